@@ -2,12 +2,15 @@ module ascii1090
 
 go 1.25.5
 
+require (
+	github.com/gdamore/tcell/v2 v2.7.0
+	github.com/jonas-p/go-shp v0.1.1
+	github.com/mattn/go-runewidth v0.0.15
+)
+
 require (
 	github.com/gdamore/encoding v1.0.0 // indirect
-	github.com/gdamore/tcell/v2 v2.7.0 // indirect
-	github.com/jonas-p/go-shp v0.1.1 // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
-	github.com/mattn/go-runewidth v0.0.15 // indirect
 	github.com/rivo/uniseg v0.4.3 // indirect
 	golang.org/x/sys v0.15.0 // indirect
 	golang.org/x/term v0.15.0 // indirect
@@ -1,24 +1,146 @@
 package adsb
 
 import (
+	"ascii1090/internal/geo"
 	"fmt"
+	"math"
 	"time"
 )
 
 // Aircraft represents an ADS-B transponder broadcast from an aircraft
 type Aircraft struct {
-	ICAO          string     // ICAO hex identifier (e.g., "A12345")
-	FlightNumber  string     // Flight number (e.g., "UAL123"), empty if not available
-	Latitude      *float64   // Decimal degrees (nil if not locked)
-	Longitude     *float64   // Decimal degrees (nil if not locked)
-	Altitude      int        // Feet above sea level
-	Speed         int        // Ground speed in knots
-	Heading       int        // Heading in degrees (0-359)
-	Track         int        // Ground track in degrees (0-359)
-	VerticalRate  int        // Vertical rate in feet per minute
-	LastSeen      time.Time  // Last update timestamp
+	ICAO         string        // ICAO hex identifier (e.g., "A12345")
+	FlightNumber string        // Flight number (e.g., "UAL123"), empty if not available
+	Latitude     *float64      // Decimal degrees (nil if not locked)
+	Longitude    *float64      // Decimal degrees (nil if not locked)
+	Altitude     int           // Feet above sea level
+	Speed        int           // Ground speed in knots
+	Heading      int           // Heading in degrees (0-359)
+	Track        int           // Ground track in degrees (0-359)
+	VerticalRate int           // Vertical rate in feet per minute
+	Trend        VerticalTrend // Climbing, level or descending, from VerticalRate
+	Category     string        // ADS-B emitter category (e.g., "A3", "A7"), empty if not available
+	Squawk       string        // Mode A transponder code (e.g., "1200"), empty if not available
+	Emergency    bool          // Emergency flag set in the transponder message
+	Alert        bool          // Squawk has changed (SBS alert flag)
+	SPI          bool          // Special position identification: the pilot pressed ident
+	OnGround     bool          // Reported on the ground; see GroundKnown
+	LastSeen     time.Time     // Last update timestamp
+	Messages     int           // Messages received from this aircraft
+	Source       Source        // Feed kind of the latest message
+	Feed         string        // Name of the feed the latest message came from
+
+	// How the position is being sent, as far as the feeds say
+	PositionSource PositionSource
+
+	// Which source last set each field, for attribution and merging
+	Sources [numFields]FieldSource
+
+	// Every callsign used this session, oldest first, including before the
+	// aircraft was last pruned; replaced rather than changed in place
+	Callsigns []CallsignUse
+
+	// Squawk codes set while tracked, oldest first, kept like Trail
+	SquawkHistory []SquawkChange
+
+	// Each feed that has heard the aircraft, in the order they first did;
+	// replaced rather than changed in place like Trail
+	Receivers []Receiver
+
+	// Each source's latest position and altitude, for MergeAverage
+	reports [numSources]sourceReport
+
+	// When the message was generated, if the feed says; used to measure
+	// feed latency
+	generated time.Time
+
+	// When a raw frame last said exactly how the position is sent; SBS
+	// positions only tell ADS-B from MLAT
+	positionTyped time.Time
+
+	// Status fields (alert, SPI, ground, vertical rate) that have been
+	// reported at all
+	reported statusFields
+
+	// Recent positions, oldest first. Replaced rather than appended in place
+	// so readers holding the old slice never see it change.
+	Trail []TrailPoint
+
+	// Every position since the aircraft was first tracked, oldest first,
+	// one per historyInterval. Appended in place, past the end of any slice
+	// a reader holds, and only replaced when thinned.
+	History []TrailPoint
+
+	// Recent altitude reports, oldest first, kept like Trail but recorded
+	// with or without a position
+	AltitudeHistory []AltitudeSample
 }
 
+// AltitudeSample is one recorded altitude report
+type AltitudeSample struct {
+	Altitude int
+	Time     time.Time
+}
+
+// altitudeSampleInterval is how often an unchanged altitude is recorded
+// again, so level flight still shows up in the history
+const altitudeSampleInterval = 10 * time.Second
+
+// statusFields marks SBS status flags present in a message, so an empty
+// field isn't mistaken for a cleared flag, nor a missing vertical rate for
+// level flight
+type statusFields uint8
+
+const (
+	fieldAlert statusFields = 1 << iota
+	fieldSPI
+	fieldGround
+	fieldVerticalRate
+)
+
+// markSources attributes every field this update carries to its source
+func (a *Aircraft) markSources() {
+	set := [numFields]bool{
+		FieldCallsign:     a.FlightNumber != "",
+		FieldPosition:     a.PositionLocked(),
+		FieldAltitude:     a.Altitude != 0,
+		FieldSpeed:        a.Speed != 0,
+		FieldTrack:        a.Track != 0 || a.Heading != 0,
+		FieldVerticalRate: a.VerticalRate != 0 || a.reported&fieldVerticalRate != 0,
+		FieldSquawk:       a.Squawk != "",
+	}
+	for field, ok := range set {
+		if ok {
+			a.Sources[field] = FieldSource{Source: a.Source, Time: a.LastSeen}
+		}
+	}
+}
+
+// GroundKnown reports whether the aircraft has said whether it is on the
+// ground, which older transponders and some message types never do
+func (a *Aircraft) GroundKnown() bool {
+	return a.reported&fieldGround != 0
+}
+
+// TrailPoint is one recorded position in an aircraft's trail
+type TrailPoint struct {
+	Lat      float64
+	Lon      float64
+	Altitude int
+	Time     time.Time
+}
+
+// TrailDuration is how far back aircraft trails are kept
+const TrailDuration = 5 * time.Minute
+
+// History is sampled every historyInterval; past maxHistoryPoints every
+// other point is dropped, so a long session keeps the whole flight at a
+// coarser spacing
+const (
+	historyInterval  = 5 * time.Second
+	maxHistoryPoints = 4000
+)
+
 // FlightLevel returns the altitude divided by 100 (Flight Level)
 func (a *Aircraft) FlightLevel() int {
 	return a.Altitude / 100
@@ -69,6 +191,89 @@ func (a *Aircraft) CardinalDirection() rune {
 	}
 }
 
+// EmergencyKind returns the type of emergency being signaled, or "" if none
+// 7500 is unlawful interference, 7600 radio failure, 7700 general emergency
+func (a *Aircraft) EmergencyKind() string {
+	switch a.Squawk {
+	case "7500":
+		return "HIJACK"
+	case "7600":
+		return "RADIO"
+	case "7700":
+		return "MAYDAY"
+	}
+	if a.Emergency {
+		return "EMERGENCY"
+	}
+	return ""
+}
+
+// recordAltitude appends the current altitude to the history if it has
+// changed or the last sample is getting old, dropping samples older than
+// TrailDuration
+func (a *Aircraft) recordAltitude(now time.Time) {
+	if a.Altitude == 0 {
+		return
+	}
+
+	if n := len(a.AltitudeHistory); n > 0 {
+		last := a.AltitudeHistory[n-1]
+		if last.Altitude == a.Altitude && now.Sub(last.Time) < altitudeSampleInterval {
+			return
+		}
+	}
+
+	start := 0
+	for start < len(a.AltitudeHistory) && now.Sub(a.AltitudeHistory[start].Time) > TrailDuration {
+		start++
+	}
+
+	history := make([]AltitudeSample, 0, len(a.AltitudeHistory)-start+1)
+	history = append(history, a.AltitudeHistory[start:]...)
+	a.AltitudeHistory = append(history, AltitudeSample{Altitude: a.Altitude, Time: now})
+}
+
+// recordPosition appends the current position to the trail if it has
+// moved, dropping points older than TrailDuration
+func (a *Aircraft) recordPosition(now time.Time) {
+	if !a.PositionLocked() {
+		return
+	}
+
+	if n := len(a.Trail); n > 0 && a.Trail[n-1].Lat == *a.Latitude && a.Trail[n-1].Lon == *a.Longitude {
+		return
+	}
+
+	start := 0
+	for start < len(a.Trail) && now.Sub(a.Trail[start].Time) > TrailDuration {
+		start++
+	}
+
+	point := TrailPoint{Lat: *a.Latitude, Lon: *a.Longitude, Altitude: a.Altitude, Time: now}
+	trail := make([]TrailPoint, 0, len(a.Trail)-start+1)
+	trail = append(trail, a.Trail[start:]...)
+	a.Trail = append(trail, point)
+	a.recordHistory(point)
+}
+
+// recordHistory adds a position to History if historyInterval has passed
+// since the last one, thinning it once it is full
+func (a *Aircraft) recordHistory(point TrailPoint) {
+	n := len(a.History)
+	if n > 0 && point.Time.Sub(a.History[n-1].Time) < historyInterval {
+		return
+	}
+
+	if n >= maxHistoryPoints {
+		thinned := make([]TrailPoint, 0, maxHistoryPoints)
+		for i := 0; i < n; i += 2 {
+			thinned = append(thinned, a.History[i])
+		}
+		a.History = thinned
+	}
+	a.History = append(a.History, point)
+}
+
 // IsStale returns true if the aircraft hasn't been seen in 60+ seconds
 func (a *Aircraft) IsStale() bool {
 	return time.Since(a.LastSeen) >= 60*time.Second
@@ -82,28 +287,24 @@ func (a *Aircraft) DisplayName() string {
 	return a.ICAO
 }
 
-// PositionString returns a formatted lat/lon string
+// MapLabel returns the compact on-map label: name and three-digit flight level
+// Format: "UAL123 350"
+func (a *Aircraft) MapLabel() string {
+	return fmt.Sprintf("%s %03d", a.DisplayName(), max(a.FlightLevel(), 0))
+}
+
+// PositionString returns a formatted lat/lon string in decimal degrees
 func (a *Aircraft) PositionString() string {
+	return a.PositionStringFormat(geo.CoordDecimal)
+}
+
+// PositionStringFormat returns a formatted lat/lon string in the given format
+func (a *Aircraft) PositionStringFormat(format geo.CoordFormat) string {
 	if !a.PositionLocked() {
 		return "Position Unknown"
 	}
 
-	lat := *a.Latitude
-	lon := *a.Longitude
-
-	latDir := "N"
-	if lat < 0 {
-		latDir = "S"
-		lat = -lat
-	}
-
-	lonDir := "E"
-	if lon < 0 {
-		lonDir = "W"
-		lon = -lon
-	}
-
-	return fmt.Sprintf("%.4f*%s, %.4f*%s", lat, latDir, lon, lonDir)
+	return geo.FormatLatLon(*a.Latitude, *a.Longitude, format)
 }
 
 // SecondsSinceLastSeen returns the number of seconds since the aircraft was last seen
@@ -112,16 +313,50 @@ func (a *Aircraft) SecondsSinceLastSeen() int {
 }
 
 // ListDisplay returns the formatted string for the aircraft list
-// Format: "(+) UAL123 FL450 500kts" or "( ) A12345 FL0 0kts"
-func (a *Aircraft) ListDisplay() string {
+// Format: "(+) UAL123 FL450↑ 500kts" or "( ) A12345 FL0      0kts", the
+// arrow showing the vertical trend; metric units show meters instead of a
+// flight level: "(+) UAL123 13716m→ 926km/h"
+func (a *Aircraft) ListDisplay(units geo.Units) string {
 	indicator := "( )"
 	if a.PositionLocked() {
 		indicator = "(+)"
 	}
 
-	return fmt.Sprintf("%s %-7s FL%-3d %3dkts",
+	altitude := fmt.Sprintf("FL%-3d", a.FlightLevel())
+	if units == geo.UnitsMetric {
+		altitude = fmt.Sprintf("%5dm", units.Altitude(a.Altitude))
+	}
+
+	return fmt.Sprintf("%s %-7s %s%c %3d%s",
 		indicator,
 		a.DisplayName(),
-		a.FlightLevel(),
-		a.Speed)
+		altitude,
+		a.TrendArrow(),
+		units.Speed(a.Speed),
+		units.SpeedUnit())
+}
+
+// RangeBearing returns the distance in nautical miles and true bearing in
+// degrees from a point to the aircraft; ok is false without a position
+func (a *Aircraft) RangeBearing(from geo.LatLon) (nm float64, bearing int, ok bool) {
+	if !a.PositionLocked() {
+		return 0, 0, false
+	}
+
+	to := geo.LatLon{Lat: *a.Latitude, Lon: *a.Longitude}
+	nm = geo.Distance(from, to) * geo.NauticalMilesPerMile
+	bearing = int(math.Round(geo.Bearing(from, to))) % 360
+	return nm, bearing, true
+}
+
+// ListDisplayFrom is ListDisplay with range and bearing from the receiver
+// appended, blank if the aircraft has no position
+// Format: "(+) UAL123 FL450 500kts  23nm 310°"
+func (a *Aircraft) ListDisplayFrom(receiver geo.LatLon, units geo.Units) string {
+	nm, bearing, ok := a.RangeBearing(receiver)
+	if !ok {
+		return a.ListDisplay(units)
+	}
+	distance := units.FromMiles(nm / geo.NauticalMilesPerMile)
+	return fmt.Sprintf("%s %3.0f%s %03d°", a.ListDisplay(units), distance, units.DistanceUnit(), bearing)
 }
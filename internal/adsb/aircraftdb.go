@@ -0,0 +1,178 @@
+package adsb
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// AircraftInfo is what the aircraft database knows about an airframe
+type AircraftInfo struct {
+	Registration string // Tail number (e.g., "N12345")
+	TypeCode     string // ICAO type designator (e.g., "B738")
+	Description  string // Make and model (e.g., "Boeing 737-800")
+	Operator     string // Airline or owner
+}
+
+// AircraftDB maps ICAO hex addresses to aircraft metadata
+type AircraftDB struct {
+	byICAO map[string]AircraftInfo
+	types  map[string]string // Type designator to description, from the file
+}
+
+// aircraftDBColumns maps header names used by common aircraft databases
+// (OpenSky's aircraftDatabase.csv, VRS and tar1090 exports) to fields
+var aircraftDBColumns = map[string]string{
+	"icao24":           "icao",
+	"icao":             "icao",
+	"hex":              "icao",
+	"modes":            "icao",
+	"registration":     "registration",
+	"reg":              "registration",
+	"typecode":         "type",
+	"icaotypecode":     "type",
+	"icaotype":         "type",
+	"type":             "type",
+	"description":      "description",
+	"typedescription":  "description",
+	"manufacturername": "manufacturer",
+	"manufacturer":     "manufacturer",
+	"model":            "model",
+	"operator":         "operator",
+	"operatorname":     "operator",
+	"owner":            "owner",
+}
+
+// LoadAircraftDB loads an aircraft database from CSV
+// Files with a header row are matched by column name, so OpenSky's
+// aircraftDatabase.csv works as downloaded; without a header, lines are
+// read as "icao,registration,typecode[,operator]". The owner stands in
+// for a missing operator.
+func LoadAircraftDB(csvPath string) (*AircraftDB, error) {
+	file, err := os.Open(csvPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open aircraft database: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.LazyQuotes = true
+
+	db := &AircraftDB{
+		byICAO: make(map[string]AircraftInfo),
+		types:  make(map[string]string),
+	}
+
+	// Positional layout until a header says otherwise
+	columns := map[string]int{"icao": 0, "registration": 1, "type": 2, "operator": 3}
+	first := true
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			continue
+		}
+
+		if first {
+			first = false
+			header := make(map[string]int)
+			for i, col := range record {
+				if field, ok := aircraftDBColumns[strings.ToLower(trimField(col))]; ok {
+					if _, dup := header[field]; !dup {
+						header[field] = i
+					}
+				}
+			}
+			if _, ok := header["icao"]; ok {
+				columns = header
+				continue
+			}
+		}
+
+		get := func(field string) string {
+			if i, ok := columns[field]; ok && i < len(record) {
+				return trimField(record[i])
+			}
+			return ""
+		}
+
+		icao := strings.ToUpper(get("icao"))
+		if icao == "" {
+			continue
+		}
+
+		info := AircraftInfo{
+			Registration: get("registration"),
+			TypeCode:     strings.ToUpper(get("type")),
+			Description:  get("description"),
+			Operator:     get("operator"),
+		}
+		if info.Description == "" {
+			info.Description = strings.TrimSpace(get("manufacturer") + " " + get("model"))
+		}
+		if info.Operator == "" {
+			info.Operator = get("owner")
+		}
+		if info == (AircraftInfo{}) {
+			continue
+		}
+
+		db.byICAO[icao] = info
+		if info.TypeCode != "" && info.Description != "" {
+			if _, exists := db.types[info.TypeCode]; !exists {
+				db.types[info.TypeCode] = info.Description
+			}
+		}
+	}
+
+	return db, nil
+}
+
+// trimField strips spaces and the single quotes some exports wrap every
+// value in
+func trimField(field string) string {
+	return strings.Trim(strings.TrimSpace(field), "'")
+}
+
+// Lookup returns what is known about an aircraft by ICAO hex address
+// The description falls back to the built-in type table when the file
+// only gives a type designator.
+func (d *AircraftDB) Lookup(icao string) (AircraftInfo, bool) {
+	if d == nil {
+		return AircraftInfo{}, false
+	}
+	info, ok := d.byICAO[strings.ToUpper(strings.TrimSpace(icao))]
+	if ok && info.Description == "" {
+		info.Description = d.TypeDescription(info.TypeCode)
+	}
+	return info, ok
+}
+
+// TypeDescription returns the make and model for an ICAO type designator,
+// or "" if it isn't known
+func (d *AircraftDB) TypeDescription(typeCode string) string {
+	typeCode = strings.ToUpper(strings.TrimSpace(typeCode))
+	if description, ok := typeDescriptions[typeCode]; ok {
+		return description
+	}
+	if d != nil {
+		return d.types[typeCode]
+	}
+	return ""
+}
+
+// Len returns the number of aircraft in the database
+func (d *AircraftDB) Len() int {
+	if d == nil {
+		return 0
+	}
+	return len(d.byICAO)
+}
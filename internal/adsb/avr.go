@@ -0,0 +1,109 @@
+package adsb
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AVRParser parses raw Mode S frames in AVR format, as dump1090 sends them
+// on port 30002: "*8D4840D6202CC371C32CE0576098;", or with "@" and a
+// 12-digit receive timestamp before the frame. Only extended squitters
+// (DF17 and DF18) are used; they say what kind of position the aircraft is
+// sending and its emitter category, which the SBS output leaves out.
+type AVRParser struct{}
+
+// NewAVRParser creates a new AVR parser
+func NewAVRParser() *AVRParser {
+	return &AVRParser{}
+}
+
+// Parse parses one AVR line, returning nil for frames that carry nothing
+// used here
+func (p *AVRParser) Parse(line string) (*Aircraft, error) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nil, nil
+	}
+
+	switch line[0] {
+	case '*':
+		line = line[1:]
+	case '@':
+		if len(line) < 13 {
+			return nil, fmt.Errorf("short timestamped frame")
+		}
+		line = line[13:]
+	default:
+		return nil, fmt.Errorf("not an AVR frame")
+	}
+	frame, err := hex.DecodeString(strings.TrimSuffix(line, ";"))
+	if err != nil {
+		return nil, fmt.Errorf("bad frame: %w", err)
+	}
+	if len(frame) != 14 {
+		return nil, nil // Short frames aren't extended squitters
+	}
+
+	df := frame[0] >> 3
+	if df != 17 && df != 18 {
+		return nil, nil
+	}
+	if modeSCRC(frame[:11]) != uint32(frame[11])<<16|uint32(frame[12])<<8|uint32(frame[13]) {
+		return nil, fmt.Errorf("CRC mismatch")
+	}
+
+	icao := strings.ToUpper(hex.EncodeToString(frame[1:4]))
+	source := PositionADSB
+	coarse := false
+	if df == 18 {
+		control := frame[0] & 7
+		coarse = control == 3 // Coarse TIS-B positions have no type code
+		switch control {
+		case 1:
+			icao = "~" + icao // ADS-B with a non-ICAO address
+		case 2, 3:
+			source = PositionTISB
+		case 5:
+			source = PositionTISB
+			icao = "~" + icao
+		case 6:
+			source = PositionADSR
+		case 4, 7:
+			return nil, nil // TIS-B management and reserved
+		}
+	}
+
+	now := time.Now()
+	aircraft := &Aircraft{
+		ICAO:     icao,
+		LastSeen: now,
+	}
+
+	typeCode := frame[4] >> 3
+	if !coarse && typeCode >= 1 && typeCode <= 4 {
+		aircraft.Category, aircraft.FlightNumber = decodeIdentification(frame[4:11])
+	}
+	if coarse || (typeCode >= 5 && typeCode <= 18) || (typeCode >= 20 && typeCode <= 22) {
+		aircraft.PositionSource = source
+		aircraft.positionTyped = now
+	}
+	return aircraft, nil
+}
+
+// modeSCRC computes the Mode S parity over the data bits of a frame
+func modeSCRC(data []byte) uint32 {
+	const generator = 0x1FFF409
+	var crc uint32
+	for _, b := range data {
+		crc ^= uint32(b) << 16
+		for i := 0; i < 8; i++ {
+			crc <<= 1
+			if crc&0x1000000 != 0 {
+				crc ^= generator
+			}
+		}
+	}
+	return crc & 0xFFFFFF
+}
@@ -0,0 +1,69 @@
+package adsb
+
+import (
+	"ascii1090/internal/debug"
+	"time"
+)
+
+// CallsignUse is one callsign an aircraft has used this session
+type CallsignUse struct {
+	Callsign string
+	Since    time.Time // When it was first received
+	InFlight bool      // Changed to while airborne rather than before departure
+}
+
+// airborne reports whether the aircraft is flying, going by the ground flag
+// when the transponder sends one and by having an altitude otherwise
+func (a *Aircraft) airborne() bool {
+	if a.GroundKnown() {
+		return !a.OnGround
+	}
+	return a.Altitude > 0
+}
+
+// CallsignChangedInFlight reports whether the aircraft changed callsign
+// while airborne, typical of repositioning flights and of two aircraft
+// mixed up under one ICAO address
+func (a *Aircraft) CallsignChangedInFlight() bool {
+	for _, use := range a.Callsigns {
+		if use.InFlight {
+			return true
+		}
+	}
+	return false
+}
+
+// noteCallsign records the callsign ac is now using in the session history,
+// which outlives pruning so an aircraft that comes back keeps its earlier
+// callsigns. Callsigns is replaced rather than changed in place, like Trail.
+func (t *Tracker) noteCallsign(ac *Aircraft, now time.Time) {
+	history := t.callsigns[ac.ICAO]
+	ac.Callsigns = history
+	if ac.FlightNumber == "" {
+		return
+	}
+
+	var previous string
+	if len(history) > 0 {
+		previous = history[len(history)-1].Callsign
+		if previous == ac.FlightNumber {
+			return
+		}
+	}
+
+	inFlight := previous != "" && ac.airborne()
+	if previous != "" {
+		log.Event(debug.LevelInfo, "callsign_changed", ac.ICAO, debug.Fields{
+			"from":      previous,
+			"to":        ac.FlightNumber,
+			"in_flight": inFlight,
+		})
+	}
+	history = append(history[:len(history):len(history)], CallsignUse{
+		Callsign: ac.FlightNumber,
+		Since:    now,
+		InFlight: inFlight,
+	})
+	t.callsigns[ac.ICAO] = history
+	ac.Callsigns = history
+}
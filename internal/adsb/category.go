@@ -0,0 +1,83 @@
+package adsb
+
+import "strings"
+
+// categoryDescriptions names the ADS-B emitter categories, set A for
+// aircraft by weight, B for other airborne things and C for surface ones
+var categoryDescriptions = map[string]string{
+	"A1": "Light",
+	"A2": "Small",
+	"A3": "Large",
+	"A4": "High-vortex large",
+	"A5": "Heavy",
+	"A6": "High performance",
+	"A7": "Rotorcraft",
+	"B1": "Glider",
+	"B2": "Lighter than air",
+	"B3": "Parachutist",
+	"B4": "Ultralight",
+	"B6": "UAV",
+	"B7": "Space vehicle",
+	"C1": "Emergency vehicle",
+	"C2": "Service vehicle",
+	"C3": "Point obstacle",
+	"C4": "Cluster obstacle",
+	"C5": "Line obstacle",
+}
+
+// categoryGroups are the names a filter can use for a set of categories
+var categoryGroups = map[string][]string{
+	"light":      {"A1"},
+	"small":      {"A2"},
+	"large":      {"A3", "A4"},
+	"heavy":      {"A5"},
+	"fast":       {"A6"},
+	"rotorcraft": {"A7"},
+	"heli":       {"A7"},
+	"glider":     {"B1"},
+	"balloon":    {"B2"},
+	"ultralight": {"B4"},
+	"uav":        {"B6"},
+	"drone":      {"B6"},
+	"surface":    {"C1", "C2"},
+	"vehicle":    {"C1", "C2"},
+	"obstacle":   {"C3", "C4", "C5"},
+}
+
+// CategoryDescription describes an emitter category code such as "A7",
+// empty if the code isn't a known category
+func CategoryDescription(code string) string {
+	return categoryDescriptions[strings.ToUpper(code)]
+}
+
+// CategoryGroup returns the category codes a name such as "heli" or
+// "heavy" stands for
+func CategoryGroup(name string) ([]string, bool) {
+	codes, ok := categoryGroups[strings.ToLower(name)]
+	return codes, ok
+}
+
+// identificationCharset decodes the 6-bit characters of an identification
+// message
+const identificationCharset = "#ABCDEFGHIJKLMNOPQRSTUVWXYZ##### ###############0123456789######"
+
+// decodeIdentification reads the emitter category and callsign from the
+// ME field of an identification message (type codes 1-4). The category is
+// empty when the aircraft doesn't report one.
+func decodeIdentification(me []byte) (category, callsign string) {
+	typeCode := me[0] >> 3
+	if set := me[0] & 7; set != 0 {
+		category = string(rune('A'+4-typeCode)) + string(rune('0'+set))
+	}
+
+	var bits uint64
+	for _, b := range me[1:7] {
+		bits = bits<<8 | uint64(b)
+	}
+	chars := make([]byte, 8)
+	for i := range chars {
+		chars[i] = identificationCharset[bits>>(42-6*i)&0x3F]
+	}
+	callsign = strings.TrimSpace(strings.ReplaceAll(string(chars), "#", ""))
+	return category, callsign
+}
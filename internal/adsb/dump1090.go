@@ -1,7 +1,9 @@
 package adsb
 
 import (
+	"ascii1090/internal/debug"
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -12,17 +14,39 @@ import (
 	"time"
 )
 
+// log is the adsb package's debug scope
+var log = debug.NewScope("adsb")
+
 // Dump1090Client connects to a dump1090 instance and reads aircraft data
+// A locally spawned dump1090 is supervised: when it exits or goes silent it
+// is restarted with backoff and the client reconnects. A remote feed that
+// drops or goes silent is reconnected the same way.
 type Dump1090Client struct {
+	mu          sync.Mutex // Guards conn, cmd, exited and stderr, replaced on restart
 	conn        io.ReadCloser
 	isLocalCLI  bool
 	cmd         *exec.Cmd
+	exited      chan struct{} // Closed when the local dump1090 exits
+	stderr      *tailBuffer   // End of the local dump1090's error output
+	opts        LocalOptions
 	networkAddr string
-	parser      *SBSParser
+	source      Source // Attributed to messages that don't name their own
+	parser      lineParser
 	msgChan     chan *Aircraft
 	errChan     chan error
 	done        chan struct{}
+	stop        chan struct{} // Closed by Close to stop reading and restarting
 	closeOnce   sync.Once
+
+	// Reconnect after this long without data, 0 for never
+	silenceTimeout time.Duration
+	health         feedHealth
+}
+
+// lineParser turns one line of a feed into an aircraft update, or nil for
+// lines that carry nothing used
+type lineParser interface {
+	Parse(line string) (*Aircraft, error)
 }
 
 // SBSParser parses SBS/BaseStation format messages
@@ -33,75 +57,289 @@ func NewSBSParser() *SBSParser {
 	return &SBSParser{}
 }
 
-// NewLocalClient spawns dump1090 CLI and connects to its SBS output
-// dump1090 is launched with --net flag to enable network output on port 30003
-func NewLocalClient() (*Dump1090Client, error) {
-	// Spawn dump1090 with network output enabled
-	cmd := exec.Command("dump1090", "--net", "--quiet")
+// DefaultSBSPort is dump1090's standard SBS/BaseStation output port
+const DefaultSBSPort = 30003
 
-	// Capture stderr to see any errors
-	stderrPipe, err := cmd.StderrPipe()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create stderr pipe: %w", err)
+// LocalOptions configures the dump1090 process NewLocalClient spawns
+type LocalOptions struct {
+	Binary string   // Executable name or path, "dump1090" if empty
+	Args   []string // Extra arguments, e.g. --gain 40 --device-index 1 --fix
+	Port   int      // SBS output port to connect to, DefaultSBSPort if 0
+}
+
+// command builds the dump1090 command line; a non-default port is passed
+// on with --net-sbs-port so dump1090 listens where we connect
+func (o LocalOptions) command() *exec.Cmd {
+	binary := o.Binary
+	if binary == "" {
+		binary = "dump1090"
+	}
+	args := []string{"--net", "--quiet"}
+	if o.Port != 0 && o.Port != DefaultSBSPort {
+		args = append(args, "--net-sbs-port", strconv.Itoa(o.Port))
+	}
+	return exec.Command(binary, append(args, o.Args...)...)
+}
+
+// sbsAddr returns the local address of the SBS output
+func (o LocalOptions) sbsAddr() string {
+	port := o.Port
+	if port == 0 {
+		port = DefaultSBSPort
+	}
+	return net.JoinHostPort("localhost", strconv.Itoa(port))
+}
+
+// Backoff for restarting a local dump1090 that exits and reconnecting a
+// remote feed that drops: the delay doubles after each failed attempt, and
+// resets once the feed has stayed up a while
+const (
+	minRestartDelay = 2 * time.Second
+	maxRestartDelay = 2 * time.Minute
+	stableRunTime   = 5 * time.Minute
+)
+
+// NewLocalClient spawns dump1090 CLI and connects to its SBS output
+// dump1090 is launched with --net flag to enable network output, on port
+// 30003 unless opts says otherwise
+func NewLocalClient(opts LocalOptions) (*Dump1090Client, error) {
+	c := &Dump1090Client{
+		isLocalCLI: true,
+		opts:       opts,
+		source:     SourceLocal,
+		parser:     NewSBSParser(),
+		msgChan:    make(chan *Aircraft, 100),
+		errChan:    make(chan error, 10),
+		done:       make(chan struct{}),
+		stop:       make(chan struct{}),
+	}
+	if err := c.spawn(); err != nil {
+		return nil, err
 	}
+	return c, nil
+}
+
+// spawn starts dump1090 and connects to its SBS output
+func (c *Dump1090Client) spawn() error {
+	// Spawn dump1090 with network output enabled, keeping the end of its
+	// error output to explain failures
+	cmd := c.opts.command()
+	stderr := &tailBuffer{}
+	cmd.Stderr = stderr
 
 	// Start the command
 	if err := cmd.Start(); err != nil {
-		return nil, fmt.Errorf("failed to start dump1090: %w", err)
+		return fmt.Errorf("failed to start dump1090: %w", err)
 	}
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
 
 	// Wait for dump1090 to initialize and open network port
 	// Try to connect with retries
 	var conn net.Conn
+	var err error
 	maxRetries := 10
 	for i := 0; i < maxRetries; i++ {
-		time.Sleep(500 * time.Millisecond)
-		conn, err = net.Dial("tcp", "localhost:30003")
+		select {
+		case <-time.After(500 * time.Millisecond):
+		case <-exited:
+			return fmt.Errorf("dump1090 exited on startup (%s)\nDump1090 stderr: %s", cmd.ProcessState, stderr)
+		case <-c.stop:
+			cmd.Process.Kill()
+			return fmt.Errorf("client closed")
+		}
+
+		conn, err = net.Dial("tcp", c.opts.sbsAddr())
 		if err == nil {
 			break
 		}
-		if i == maxRetries-1 {
-			// Read any error output from dump1090
-			buf := make([]byte, 1024)
-			n, _ := stderrPipe.Read(buf)
-			errMsg := string(buf[:n])
-			cmd.Process.Kill()
-			return nil, fmt.Errorf("failed to connect to dump1090 SBS port after %d attempts: %w\nDump1090 stderr: %s", maxRetries, err, errMsg)
+	}
+	if err != nil {
+		cmd.Process.Kill()
+		return fmt.Errorf("failed to connect to dump1090 SBS port after %d attempts: %w\nDump1090 stderr: %s", maxRetries, err, stderr)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-c.stop:
+		conn.Close()
+		cmd.Process.Kill()
+		return fmt.Errorf("client closed")
+	default:
+	}
+	c.cmd, c.conn, c.exited, c.stderr = cmd, conn, exited, stderr
+
+	// A dead dump1090's socket may not close by itself; closing it ends
+	// the read so the process is restarted
+	go func() {
+		<-exited
+		conn.Close()
+	}()
+	return nil
+}
+
+// restart stops the local dump1090 if it is still running and starts it
+// again, backing off while it keeps failing; false means Close was called.
+// cause says why it is restarted when dump1090 didn't simply exit.
+func (c *Dump1090Client) restart(upFor time.Duration, delay *time.Duration, cause error) bool {
+	c.mu.Lock()
+	cmd, exited, stderr := c.cmd, c.exited, c.stderr
+	c.mu.Unlock()
+	cmd.Process.Kill()
+	<-exited
+
+	reason := fmt.Sprintf("dump1090 exited (%s)", cmd.ProcessState)
+	if cause != nil {
+		reason = cause.Error()
+	} else if last := stderr.lastLine(); last != "" {
+		reason += ": " + last
+	}
+	return c.retry(reason, "restarting", upFor, delay, c.spawn)
+}
+
+// reconnect dials a remote feed again after its connection ended, backing
+// off while it keeps failing; false means Close was called
+func (c *Dump1090Client) reconnect(upFor time.Duration, delay *time.Duration, cause error) bool {
+	reason := fmt.Sprintf("%s closed the connection", c.networkAddr)
+	if cause != nil {
+		reason = fmt.Sprintf("lost %s: %v", c.networkAddr, cause)
+	}
+	return c.retry(reason, "reconnecting", upFor, delay, c.dial)
+}
+
+// retry calls attempt after delay until it succeeds, doubling the delay
+// after each failure up to maxRestartDelay; a feed that stayed up past
+// stableRunTime starts again from minRestartDelay. False means Close was
+// called.
+func (c *Dump1090Client) retry(reason, action string, upFor time.Duration, delay *time.Duration, attempt func() error) bool {
+	if upFor > stableRunTime {
+		*delay = minRestartDelay
+	}
+	c.health.setReconnecting(true)
+	defer c.health.setReconnecting(false)
+
+	for {
+		log.Warnf("%s, %s in %s", reason, action, *delay)
+		c.report(fmt.Errorf("%s, %s in %s", reason, action, *delay))
+		select {
+		case <-time.After(*delay):
+		case <-c.stop:
+			return false
+		}
+		*delay = min(*delay*2, maxRestartDelay)
+
+		err := attempt()
+		if err == nil {
+			c.health.connected(time.Now())
+			log.Infof("%s: %s succeeded", c.Name(), action)
+			return true
+		}
+		select {
+		case <-c.stop:
+			return false
+		default:
+		}
+		reason = err.Error()
+		if i := strings.IndexByte(reason, '\n'); i >= 0 {
+			reason = reason[:i]
 		}
 	}
+}
 
-	return &Dump1090Client{
-		conn:       conn,
-		isLocalCLI: true,
-		cmd:        cmd,
-		parser:     NewSBSParser(),
-		msgChan:    make(chan *Aircraft, 100),
-		errChan:    make(chan error, 10),
-		done:       make(chan struct{}),
-	}, nil
+// dial connects to the remote feed again
+func (c *Dump1090Client) dial() error {
+	conn, err := net.Dial("tcp", c.networkAddr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", c.networkAddr, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-c.stop:
+		conn.Close()
+		return fmt.Errorf("client closed")
+	default:
+	}
+	c.conn = conn
+	return nil
+}
+
+// report sends an error to Errors without blocking when nobody is reading,
+// and counts it against the feed's health
+func (c *Dump1090Client) report(err error) {
+	c.health.failure(err)
+	select {
+	case c.errChan <- err:
+	default:
+	}
 }
 
 // NewNetworkClient connects to a remote dump1090 instance via network
 // addr should be in format "host:port", e.g., "192.168.1.100:30003"
-func NewNetworkClient(addr string) (*Dump1090Client, error) {
+// Messages are attributed to source, SourceRemote if unknown
+func NewNetworkClient(addr string, source Source) (*Dump1090Client, error) {
 	conn, err := net.Dial("tcp", addr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
 	}
+	if source == SourceUnknown {
+		source = SourceRemote
+	}
 
 	return &Dump1090Client{
 		conn:        conn,
 		isLocalCLI:  false,
 		networkAddr: addr,
+		source:      source,
 		parser:      NewSBSParser(),
 		msgChan:     make(chan *Aircraft, 100),
 		errChan:     make(chan error, 10),
 		done:        make(chan struct{}),
+		stop:        make(chan struct{}),
 	}, nil
 }
 
+// NewRawNetworkClient connects to a remote dump1090's raw AVR output (port
+// 30002), which says how each aircraft sends its position; run it beside
+// an SBS feed from the same receiver, which supplies the positions
+func NewRawNetworkClient(addr string, source Source) (*Dump1090Client, error) {
+	c, err := NewNetworkClient(addr, source)
+	if err != nil {
+		return nil, err
+	}
+	c.parser = NewAVRParser()
+	return c, nil
+}
+
+// Name identifies the feed in messages: its address, or "local dump1090"
+func (c *Dump1090Client) Name() string {
+	if c.isLocalCLI {
+		return "local dump1090"
+	}
+	return c.networkAddr
+}
+
+// Status returns a snapshot of the feed's health
+func (c *Dump1090Client) Status() FeedStatus {
+	status := c.health.status(time.Now())
+	status.Name = c.Name()
+	status.Source = c.source
+	return status
+}
+
+// Source returns the kind of feed this client reads
+func (c *Dump1090Client) Source() Source {
+	return c.source
+}
+
 // Start begins reading messages from dump1090
 func (c *Dump1090Client) Start() {
+	c.health.connected(time.Now())
 	go c.readLoop()
 }
 
@@ -119,7 +357,9 @@ func (c *Dump1090Client) Errors() <-chan error {
 func (c *Dump1090Client) Close() error {
 	// Use sync.Once to ensure we only close once
 	c.closeOnce.Do(func() {
-		// Close the connection first to stop readLoop
+		// Stop restarts, then close the connection to stop readLoop
+		close(c.stop)
+		c.mu.Lock()
 		if c.conn != nil {
 			c.conn.Close()
 		}
@@ -128,6 +368,7 @@ func (c *Dump1090Client) Close() error {
 		if c.isLocalCLI && c.cmd != nil && c.cmd.Process != nil {
 			c.cmd.Process.Kill()
 		}
+		c.mu.Unlock()
 
 		// Wait for readLoop to finish before closing channels
 		<-c.done
@@ -139,34 +380,145 @@ func (c *Dump1090Client) Close() error {
 	return nil
 }
 
-// readLoop continuously reads and parses messages from dump1090
+// readLoop continuously reads and parses messages from dump1090,
+// restarting a local dump1090 whenever its output ends
 func (c *Dump1090Client) readLoop() {
 	defer close(c.done) // Signal that readLoop is finished
 
-	scanner := bufio.NewScanner(c.conn)
+	delay := minRestartDelay
+	for {
+		started := time.Now()
+		err := c.readConn()
+
+		select {
+		case <-c.stop:
+			return // Exit if Close() was called
+		default:
+		}
+
+		if errors.Is(err, errSilent) {
+			log.Event(debug.LevelWarn, "feed_silent", "", debug.Fields{
+				"feed":    c.Name(),
+				"timeout": c.silenceTimeout.String(),
+			})
+		}
+
+		upFor := time.Since(started)
+		if c.isLocalCLI && !c.restart(upFor, &delay, err) {
+			return
+		}
+		if !c.isLocalCLI && !c.reconnect(upFor, &delay, err) {
+			return
+		}
+	}
+}
+
+// readConn reads messages from the current connection until it ends
+func (c *Dump1090Client) readConn() error {
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+
+	var r io.Reader = conn
+	if nc, ok := conn.(net.Conn); ok && c.silenceTimeout > 0 {
+		r = &deadlineReader{conn: nc, timeout: c.silenceTimeout}
+	}
+
+	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
 		line := scanner.Text()
 		aircraft, err := c.parser.Parse(line)
 		if err != nil {
-			// Skip malformed lines silently
+			c.health.malformedLine()
+			if log.Enabled(debug.LevelTrace) {
+				log.Event(debug.LevelTrace, "sbs_skipped", "", debug.Fields{"line": line, "error": err.Error()})
+			}
 			continue
 		}
 		if aircraft != nil {
+			c.health.message(aircraft.LastSeen)
+			if aircraft.Source == SourceUnknown {
+				aircraft.Source = c.source
+			}
+			aircraft.Feed = c.Name()
+			if aircraft.PositionLocked() && aircraft.PositionSource == PositionUnknown {
+				aircraft.PositionSource = PositionADSB
+				if aircraft.Source == SourceMLAT {
+					aircraft.PositionSource = PositionMLAT
+				}
+			}
 			select {
 			case c.msgChan <- aircraft:
-			case <-c.done:
-				return // Exit if Close() was called
+			case <-c.stop:
+				return nil // Exit if Close() was called
 			}
 		}
 	}
+	err := scanner.Err()
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return fmt.Errorf("%w for %s", errSilent, c.silenceTimeout)
+	}
+	return err
+}
 
-	if err := scanner.Err(); err != nil {
-		select {
-		case c.errChan <- fmt.Errorf("error reading from dump1090: %w", err):
-		case <-c.done:
-			return // Exit if Close() was called
-		}
+// errSilent is returned by readConn when the watchdog gave up on a feed
+// that stayed connected but sent nothing
+var errSilent = errors.New("no data")
+
+// SetSilenceTimeout sets how long the feed may send nothing before it is
+// reconnected, or a local dump1090 restarted, since a wedged dump1090 often
+// keeps its port open but goes quiet; 0, the default, waits forever as a
+// receiver at a quiet site can hear nothing for a long time. Call it
+// before Start.
+func (c *Dump1090Client) SetSilenceTimeout(d time.Duration) {
+	c.silenceTimeout = d
+}
+
+// deadlineReader extends a connection's read deadline before each read,
+// so a read fails once the connection has been silent for timeout
+type deadlineReader struct {
+	conn    net.Conn
+	timeout time.Duration
+}
+
+// Read reads from the connection with a fresh deadline
+func (d *deadlineReader) Read(p []byte) (int, error) {
+	d.conn.SetReadDeadline(time.Now().Add(d.timeout))
+	return d.conn.Read(p)
+}
+
+// tailBufferSize is how much of dump1090's error output is kept
+const tailBufferSize = 2048
+
+// tailBuffer keeps the last few KB written to it
+type tailBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+// Write appends p, dropping the oldest bytes beyond tailBufferSize
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - tailBufferSize; over > 0 {
+		t.buf = t.buf[over:]
 	}
+	return len(p), nil
+}
+
+// String returns the kept output
+func (t *tailBuffer) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return string(t.buf)
+}
+
+// lastLine returns the last non-empty line of the kept output
+func (t *tailBuffer) lastLine() string {
+	lines := strings.Split(strings.TrimSpace(t.String()), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
 }
 
 // Parse parses an SBS/BaseStation format message
@@ -183,8 +535,14 @@ func (p *SBSParser) Parse(line string) (*Aircraft, error) {
 		return nil, fmt.Errorf("insufficient fields: %d", len(fields))
 	}
 
-	// Only process MSG messages
-	if fields[0] != "MSG" {
+	// Only process MSG messages, and the MLAT messages readsb and
+	// mlat-client send in the same layout
+	var source Source
+	switch fields[0] {
+	case "MSG":
+	case "MLAT":
+		source = SourceMLAT
+	default:
 		return nil, nil
 	}
 
@@ -197,6 +555,14 @@ func (p *SBSParser) Parse(line string) (*Aircraft, error) {
 	aircraft := &Aircraft{
 		ICAO:     icao,
 		LastSeen: time.Now(),
+		Source:   source,
+	}
+
+	// Date and time generated (fields 6 and 7), in the feed's local time
+	if date, clock := strings.TrimSpace(fields[6]), strings.TrimSpace(fields[7]); date != "" && clock != "" {
+		if generated, err := time.ParseInLocation("2006/01/02 15:04:05", date+" "+clock, time.Local); err == nil {
+			aircraft.generated = generated
+		}
 	}
 
 	// Callsign/Flight number (field 10)
@@ -244,8 +610,49 @@ func (p *SBSParser) Parse(line string) (*Aircraft, error) {
 	if fields[16] != "" {
 		if vr, err := strconv.Atoi(strings.TrimSpace(fields[16])); err == nil {
 			aircraft.VerticalRate = vr
+			aircraft.reported |= fieldVerticalRate
 		}
 	}
 
+	// Squawk code (field 17)
+	if squawk := strings.TrimSpace(fields[17]); squawk != "" {
+		aircraft.Squawk = squawk
+	}
+
+	// Alert flag (field 18): the squawk has changed
+	if set, ok := parseFlag(fields[18]); ok {
+		aircraft.Alert = set
+		aircraft.reported |= fieldAlert
+	}
+
+	// Emergency flag (field 19), sent as -1 or 1 when set
+	if flag := strings.TrimSpace(fields[19]); flag == "-1" || flag == "1" {
+		aircraft.Emergency = true
+	}
+
+	// SPI flag (field 20): ident is active
+	if set, ok := parseFlag(fields[20]); ok {
+		aircraft.SPI = set
+		aircraft.reported |= fieldSPI
+	}
+
+	// On-ground flag (field 21)
+	if set, ok := parseFlag(fields[21]); ok {
+		aircraft.OnGround = set
+		aircraft.reported |= fieldGround
+	}
+
 	return aircraft, nil
 }
+
+// parseFlag reads an SBS boolean field, sent as -1 or 1 when set and 0
+// when clear; ok is false when the message leaves the field empty
+func parseFlag(field string) (set, ok bool) {
+	switch strings.TrimSpace(field) {
+	case "-1", "1":
+		return true, true
+	case "0":
+		return false, true
+	}
+	return false, false
+}
@@ -0,0 +1,155 @@
+package adsb
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FeedSpec describes a network feed to connect to, as given on the command
+// line: "host:port", or "kind=host:port" to say what kind of feed it is
+type FeedSpec struct {
+	Source Source
+	Addr   string
+	Raw    bool // AVR raw frames rather than SBS
+}
+
+// ParseFeedSpecs parses a comma-separated list of feeds, e.g.
+// "192.168.1.10:30003,mlat=192.168.1.10:30106"; feeds without a kind are
+// remote, and "raw=host:port" is a remote AVR feed
+func ParseFeedSpecs(s string) ([]FeedSpec, error) {
+	var specs []FeedSpec
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		spec := FeedSpec{Source: SourceRemote, Addr: item}
+		if kind, addr, ok := strings.Cut(item, "="); ok {
+			if strings.EqualFold(strings.TrimSpace(kind), "raw") {
+				specs = append(specs, FeedSpec{Source: SourceRemote, Addr: strings.TrimSpace(addr), Raw: true})
+				continue
+			}
+			source, err := ParseSource(kind)
+			if err != nil {
+				return nil, fmt.Errorf("feed %q: %w", item, err)
+			}
+			spec = FeedSpec{Source: source, Addr: strings.TrimSpace(addr)}
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// Feeds merges the messages and errors of several dump1090 clients, so the
+// app reads one stream whatever is connected
+type Feeds struct {
+	clients   []*Dump1090Client
+	msgChan   chan *Aircraft
+	errChan   chan error
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// NewFeeds combines connected clients; Start starts them all
+func NewFeeds(clients ...*Dump1090Client) *Feeds {
+	return &Feeds{
+		clients: clients,
+		msgChan: make(chan *Aircraft, 100),
+		errChan: make(chan error, 10),
+	}
+}
+
+// Clients returns the combined clients
+func (f *Feeds) Clients() []*Dump1090Client {
+	return f.clients
+}
+
+// Status returns a snapshot of each feed's health, in the order they were
+// given to NewFeeds
+func (f *Feeds) Status() []FeedStatus {
+	statuses := make([]FeedStatus, len(f.clients))
+	for i, c := range f.clients {
+		statuses[i] = c.Status()
+	}
+	return statuses
+}
+
+// SetSilenceTimeout sets every client's silence timeout; see
+// Dump1090Client.SetSilenceTimeout
+func (f *Feeds) SetSilenceTimeout(d time.Duration) {
+	for _, c := range f.clients {
+		c.SetSilenceTimeout(d)
+	}
+}
+
+// Start starts every client and forwards their output
+func (f *Feeds) Start() {
+	for _, c := range f.clients {
+		c.Start()
+		f.wg.Add(2)
+		go f.forwardMessages(c)
+		go f.forwardErrors(c)
+	}
+}
+
+// forwardMessages copies a client's messages until it is closed
+func (f *Feeds) forwardMessages(c *Dump1090Client) {
+	defer f.wg.Done()
+	for ac := range c.ReadMessages() {
+		f.msgChan <- ac
+	}
+}
+
+// forwardErrors copies a client's errors, naming the feed when several are
+// connected, until it is closed
+func (f *Feeds) forwardErrors(c *Dump1090Client) {
+	defer f.wg.Done()
+	for err := range c.Errors() {
+		if len(f.clients) > 1 {
+			err = fmt.Errorf("%s: %w", c.Name(), err)
+		}
+		select {
+		case f.errChan <- err:
+		default:
+		}
+	}
+}
+
+// ReadMessages returns a channel of parsed aircraft updates from all feeds
+func (f *Feeds) ReadMessages() <-chan *Aircraft {
+	return f.msgChan
+}
+
+// Errors returns a channel of errors from all feeds
+func (f *Feeds) Errors() <-chan error {
+	return f.errChan
+}
+
+// Close closes every client and then the merged channels
+func (f *Feeds) Close() error {
+	f.closeOnce.Do(func() {
+		// Drain messages while the clients shut down, so a forwarder
+		// blocked on a full channel can finish
+		drained := make(chan struct{})
+		go func() {
+			for {
+				select {
+				case <-f.msgChan:
+				case <-drained:
+					return
+				}
+			}
+		}()
+		for _, c := range f.clients {
+			c.Close()
+		}
+		f.wg.Wait()
+		close(drained)
+
+		close(f.msgChan)
+		close(f.errChan)
+	})
+	return nil
+}
@@ -0,0 +1,145 @@
+package adsb
+
+import (
+	"sync"
+	"time"
+)
+
+// FeedState is a feed's connection state as shown in the feed panel
+type FeedState int
+
+const (
+	FeedConnected    FeedState = iota // Connected and sending data
+	FeedStale                         // Connected but quiet for StaleAfter
+	FeedReconnecting                  // Dropped, waiting to reconnect or restart
+)
+
+// String returns the state as shown in the feed panel
+func (s FeedState) String() string {
+	switch s {
+	case FeedStale:
+		return "Stale"
+	case FeedReconnecting:
+		return "Reconnecting"
+	default:
+		return "Connected"
+	}
+}
+
+// StaleAfter is how long a connected feed may go without a message before
+// it shows as stale; the silence timeout reconnects it later
+const StaleAfter = 30 * time.Second
+
+// FeedStatus is a snapshot of one feed's health
+type FeedStatus struct {
+	Name        string
+	Source      Source
+	State       FeedState
+	Messages    int       // Aircraft messages received since startup
+	Rate        float64   // Messages per second over the last rateWindow
+	LastMessage time.Time // Zero until the first message
+	Malformed   int       // Lines that failed to parse
+	Errors      int       // Disconnects, restarts and failed attempts
+	LastError   string
+}
+
+// rateWindow is how many seconds the message rate is averaged over
+const rateWindow = 10
+
+// feedHealth counts one client's messages and failures
+type feedHealth struct {
+	mu           sync.Mutex
+	messages     int
+	malformed    int
+	errors       int
+	lastError    string
+	lastMessage  time.Time
+	connectedAt  time.Time // When the current connection was made
+	reconnecting bool
+	buckets      [rateWindow]int // Messages per second, indexed by Unix second
+	bucketSecond [rateWindow]int64
+}
+
+// message counts an aircraft message received at now
+func (h *feedHealth) message(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.messages++
+	h.lastMessage = now
+
+	second := now.Unix()
+	i := second % rateWindow
+	if h.bucketSecond[i] != second {
+		h.bucketSecond[i] = second
+		h.buckets[i] = 0
+	}
+	h.buckets[i]++
+}
+
+// malformedLine counts a line that failed to parse
+func (h *feedHealth) malformedLine() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.malformed++
+}
+
+// failure counts a disconnect, restart or failed attempt
+func (h *feedHealth) failure(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.errors++
+	h.lastError = err.Error()
+}
+
+// connected records that a connection was made at now, so a feed isn't
+// stale before it has had time to send anything
+func (h *feedHealth) connected(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.connectedAt = now
+}
+
+// setReconnecting marks whether the feed is down and being retried
+func (h *feedHealth) setReconnecting(reconnecting bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.reconnecting = reconnecting
+}
+
+// latest returns the later of two times
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// status fills in the health fields of a FeedStatus as of now
+func (h *feedHealth) status(now time.Time) FeedStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// Count the complete seconds of the window, not the current one
+	total := 0
+	for i, second := range h.bucketSecond {
+		if age := now.Unix() - second; age >= 1 && age <= rateWindow {
+			total += h.buckets[i]
+		}
+	}
+
+	status := FeedStatus{
+		Messages:    h.messages,
+		Rate:        float64(total) / rateWindow,
+		LastMessage: h.lastMessage,
+		Malformed:   h.malformed,
+		Errors:      h.errors,
+		LastError:   h.lastError,
+	}
+	switch {
+	case h.reconnecting:
+		status.State = FeedReconnecting
+	case now.Sub(latest(h.lastMessage, h.connectedAt)) > StaleAfter:
+		status.State = FeedStale
+	}
+	return status
+}
@@ -0,0 +1,178 @@
+package adsb
+
+import (
+	"ascii1090/internal/geo"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MergePolicy decides which source's value a field keeps when feeds
+// disagree. Whatever the policy, a source may always update its own
+// values, and any source may update a field no other source has set in
+// sourceHoldTime.
+type MergePolicy int
+
+const (
+	MergePriority MergePolicy = iota // The more trusted source, see SetPrecedence
+	MergeLatest                      // Whichever message arrived last
+	MergeADSB                        // Any ADS-B source over MLAT, otherwise the latest
+	MergeLatency                     // The source with the lowest measured latency
+	MergeAverage                     // Average positions and altitudes, otherwise the latest
+)
+
+// String returns the policy name as used in flags
+func (p MergePolicy) String() string {
+	switch p {
+	case MergeLatest:
+		return "latest"
+	case MergeADSB:
+		return "adsb"
+	case MergeLatency:
+		return "latency"
+	case MergeAverage:
+		return "average"
+	default:
+		return "priority"
+	}
+}
+
+// ParseMergePolicy parses "priority", "latest", "adsb", "latency" or
+// "average"
+func ParseMergePolicy(s string) (MergePolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "priority", "":
+		return MergePriority, nil
+	case "latest", "last":
+		return MergeLatest, nil
+	case "adsb", "ads-b":
+		return MergeADSB, nil
+	case "latency":
+		return MergeLatency, nil
+	case "average", "avg":
+		return MergeAverage, nil
+	default:
+		return MergePriority, fmt.Errorf("unknown merge policy %q (use priority, latest, adsb, latency or average)", s)
+	}
+}
+
+// sourceReport is the last position and altitude one source reported for
+// an aircraft, kept for MergeAverage
+type sourceReport struct {
+	lat, lon     float64
+	positionTime time.Time
+	altitude     int
+	altitudeTime time.Time
+}
+
+// maxLatency bounds latency samples; a message generated longer ago than
+// this says more about the feed's clock than its delay
+const maxLatency = time.Minute
+
+// latencyEstimate is a smoothed per-source delay between a message being
+// generated and received
+type latencyEstimate map[Source]time.Duration
+
+// add folds one message's delay into its source's estimate
+func (l latencyEstimate) add(source Source, generated, received time.Time) {
+	if generated.IsZero() {
+		return
+	}
+	sample := received.Sub(generated)
+	if sample < 0 {
+		sample = 0
+	}
+	if sample > maxLatency {
+		return
+	}
+	if current, ok := l[source]; ok {
+		l[source] = current + (sample-current)/8
+	} else {
+		l[source] = sample
+	}
+}
+
+// of returns a source's estimate, with unmeasured sources ranked behind
+// every measured one
+func (l latencyEstimate) of(source Source) time.Duration {
+	if d, ok := l[source]; ok {
+		return d
+	}
+	return maxLatency
+}
+
+// SetMergePolicy sets how conflicting values from different feeds are
+// reconciled; the default is MergePriority
+func (t *Tracker) SetMergePolicy(policy MergePolicy) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.policy = policy
+}
+
+// Latency returns the smoothed delay measured for a source's messages, and
+// false if its messages carry no usable timestamps
+func (t *Tracker) Latency(source Source) (time.Duration, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	d, ok := t.latency[source]
+	return d, ok
+}
+
+// accepts reports whether update may overwrite field on existing under
+// the merge policy; the caller holds t.mu
+func (t *Tracker) accepts(existing *Aircraft, field Field, update *Aircraft) bool {
+	current := existing.Sources[field]
+	if current.Time.IsZero() || current.Source == update.Source ||
+		update.LastSeen.Sub(current.Time) > sourceHoldTime {
+		return true
+	}
+
+	switch t.policy {
+	case MergeLatest, MergeAverage:
+		return true
+	case MergeADSB:
+		return update.Source != SourceMLAT || current.Source == SourceMLAT
+	case MergeLatency:
+		return t.latency.of(update.Source) <= t.latency.of(current.Source)
+	default:
+		return t.precedence.rank(update.Source) <= t.precedence.rank(current.Source)
+	}
+}
+
+// averagePosition records update's position and returns the mean of the
+// positions every source reported within sourceHoldTime. Longitudes are
+// averaged as offsets from update's, so reports either side of the
+// antimeridian don't average to the other side of the globe.
+func (a *Aircraft) averagePosition(update *Aircraft) (lat, lon float64) {
+	report := &a.reports[update.Source]
+	report.lat, report.lon = *update.Latitude, *update.Longitude
+	report.positionTime = update.LastSeen
+
+	var offset float64
+	n := 0
+	for _, r := range a.reports {
+		if !r.positionTime.IsZero() && update.LastSeen.Sub(r.positionTime) <= sourceHoldTime {
+			lat += r.lat
+			offset += geo.NormalizeLon(r.lon - report.lon)
+			n++
+		}
+	}
+	return lat / float64(n), geo.NormalizeLon(report.lon + offset/float64(n))
+}
+
+// averageAltitude records update's altitude and returns the mean of the
+// altitudes every source reported within sourceHoldTime
+func (a *Aircraft) averageAltitude(update *Aircraft) int {
+	report := &a.reports[update.Source]
+	report.altitude = update.Altitude
+	report.altitudeTime = update.LastSeen
+
+	total, n := 0, 0
+	for _, r := range a.reports {
+		if !r.altitudeTime.IsZero() && update.LastSeen.Sub(r.altitudeTime) <= sourceHoldTime {
+			total += r.altitude
+			n++
+		}
+	}
+	return total / n
+}
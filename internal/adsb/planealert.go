@@ -0,0 +1,118 @@
+package adsb
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// InterestInfo is why the plane-alert database lists an airframe
+type InterestInfo struct {
+	Category string   // e.g. "USAF", "Royal Aircraft", "Dictator Alert"
+	Group    string   // "Civ", "Mil", "Pol" or "Gov"
+	Operator string   // Who flies it
+	Tags     []string // Free-form notes such as the aircraft's role
+}
+
+// String summarizes the entry, e.g. "USAF (Mil)"
+func (i InterestInfo) String() string {
+	category := i.Category
+	if category == "" {
+		category = i.Operator
+	}
+	if i.Group == "" {
+		return category
+	}
+	return fmt.Sprintf("%s (%s)", category, i.Group)
+}
+
+// PlaneAlertDB maps ICAO hex addresses to the interesting aircraft listed
+// in a plane-alert-db CSV
+type PlaneAlertDB struct {
+	byICAO map[string]InterestInfo
+}
+
+// LoadPlaneAlertDB loads a plane-alert-db CSV. Its header names columns
+// like "$ICAO", "#CMPG" and "$#Tag 2"; the $ and # markers, which say how
+// the plane-alert tool shows a column, are ignored.
+func LoadPlaneAlertDB(csvPath string) (*PlaneAlertDB, error) {
+	file, err := os.Open(csvPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open plane-alert database: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plane-alert database header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, col := range header {
+		name := strings.ToLower(strings.Trim(strings.TrimSpace(col), "$#"))
+		columns[strings.ReplaceAll(name, " ", "")] = i
+	}
+	if _, ok := columns["icao"]; !ok {
+		return nil, fmt.Errorf("no ICAO column in plane-alert database")
+	}
+
+	db := &PlaneAlertDB{byICAO: make(map[string]InterestInfo)}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			continue
+		}
+
+		get := func(col string) string {
+			if i, ok := columns[col]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		icao := strings.ToUpper(get("icao"))
+		if icao == "" {
+			continue
+		}
+
+		info := InterestInfo{
+			Category: get("category"),
+			Group:    get("cmpg"),
+			Operator: get("operator"),
+		}
+		for _, col := range []string{"tag1", "tag2", "tag3"} {
+			if tag := get(col); tag != "" {
+				info.Tags = append(info.Tags, tag)
+			}
+		}
+		db.byICAO[icao] = info
+	}
+
+	return db, nil
+}
+
+// Lookup returns why an aircraft is listed, by ICAO hex address
+func (d *PlaneAlertDB) Lookup(icao string) (InterestInfo, bool) {
+	if d == nil {
+		return InterestInfo{}, false
+	}
+	info, ok := d.byICAO[strings.ToUpper(strings.TrimSpace(icao))]
+	return info, ok
+}
+
+// Len returns the number of aircraft listed
+func (d *PlaneAlertDB) Len() int {
+	if d == nil {
+		return 0
+	}
+	return len(d.byICAO)
+}
@@ -0,0 +1,52 @@
+package adsb
+
+// PositionSource is how an aircraft's position reaches us, which bounds
+// how far it can be trusted: ADS-B positions are the aircraft's own GNSS
+// fix, MLAT positions are computed from arrival times and lag, TIS-B
+// positions are ground radar tracks rebroadcast by the FAA, and ADS-R
+// positions are UAT reports rebroadcast on 1090 (or the reverse)
+type PositionSource int
+
+const (
+	PositionUnknown PositionSource = iota
+	PositionADSB
+	PositionMLAT
+	PositionTISB
+	PositionADSR
+)
+
+// String returns the position source as shown in the detail view
+func (p PositionSource) String() string {
+	switch p {
+	case PositionADSB:
+		return "ADS-B"
+	case PositionMLAT:
+		return "MLAT"
+	case PositionTISB:
+		return "TIS-B"
+	case PositionADSR:
+		return "ADS-R"
+	default:
+		return "Unknown"
+	}
+}
+
+// updatePositionSource takes the position source from an update: always
+// from a raw frame, which says exactly; from an accepted SBS position when
+// it is MLAT, or when no raw frame has typed the position lately
+func (a *Aircraft) updatePositionSource(update *Aircraft, positioned bool) {
+	switch {
+	case !update.positionTyped.IsZero():
+		a.PositionSource = update.PositionSource
+		a.positionTyped = update.positionTyped
+	case !positioned || update.PositionSource == PositionUnknown:
+	case update.PositionSource == PositionMLAT || update.LastSeen.Sub(a.positionTyped) > sourceHoldTime:
+		a.PositionSource = update.PositionSource
+	}
+}
+
+// Indirect reports whether the position is not the aircraft's own ADS-B
+// report, so it is drawn marked on the map
+func (p PositionSource) Indirect() bool {
+	return p == PositionMLAT || p == PositionTISB || p == PositionADSR
+}
@@ -0,0 +1,55 @@
+package adsb
+
+import (
+	"math"
+	"time"
+)
+
+// Receiver is one feed's view of an aircraft: how much of it that feed
+// hears, for comparing antennas and sites
+type Receiver struct {
+	Feed     string // Feed name, as in FeedStatus
+	Source   Source
+	Messages int
+	LastSeen time.Time
+	rate     float64 // Decayed messages per second as of LastSeen
+}
+
+// receiverRateWindow is the time constant of a receiver's message rate
+const receiverRateWindow = 10 * time.Second
+
+// Rate returns the receiver's recent messages per second as of now
+func (r Receiver) Rate(now time.Time) float64 {
+	return r.rate * math.Exp(-now.Sub(r.LastSeen).Seconds()/receiverRateWindow.Seconds())
+}
+
+// Hearing reports whether the receiver has heard the aircraft within
+// StaleAfter of now
+func (r Receiver) Hearing(now time.Time) bool {
+	return now.Sub(r.LastSeen) <= StaleAfter
+}
+
+// recordReceiver counts a message from feed at now. Receivers is replaced
+// rather than changed in place, like Trail.
+func (a *Aircraft) recordReceiver(feed string, source Source, now time.Time) {
+	if feed == "" {
+		return
+	}
+
+	receivers := make([]Receiver, len(a.Receivers), len(a.Receivers)+1)
+	copy(receivers, a.Receivers)
+	i := 0
+	for i < len(receivers) && receivers[i].Feed != feed {
+		i++
+	}
+	if i == len(receivers) {
+		receivers = append(receivers, Receiver{Feed: feed, Source: source, LastSeen: now})
+	}
+
+	r := &receivers[i]
+	r.rate = r.Rate(now) + 1/receiverRateWindow.Seconds()
+	r.Messages++
+	r.LastSeen = now
+	r.Source = source
+	a.Receivers = receivers
+}
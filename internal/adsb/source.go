@@ -0,0 +1,156 @@
+package adsb
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Source is the kind of feed an update came from
+type Source int
+
+const (
+	SourceUnknown Source = iota
+	SourceLocal          // dump1090 started by ascii1090 on a local SDR
+	SourceRemote         // dump1090 or readsb on the network
+	SourceMLAT           // Multilateration positions, e.g. from mlat-client
+	SourceUAT            // 978 MHz UAT, e.g. dump978 through uat2esnt
+	numSources
+)
+
+// String returns the source name as used in flags
+func (s Source) String() string {
+	switch s {
+	case SourceLocal:
+		return "local"
+	case SourceRemote:
+		return "remote"
+	case SourceMLAT:
+		return "mlat"
+	case SourceUAT:
+		return "uat"
+	default:
+		return "unknown"
+	}
+}
+
+// Label returns the source name as shown in the detail view
+func (s Source) Label() string {
+	switch s {
+	case SourceLocal:
+		return "Local SDR"
+	case SourceRemote:
+		return "Remote"
+	case SourceMLAT:
+		return "MLAT"
+	case SourceUAT:
+		return "UAT"
+	default:
+		return "Unknown"
+	}
+}
+
+// ParseSource parses "local", "remote", "mlat" or "uat"
+func ParseSource(s string) (Source, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "local", "sdr":
+		return SourceLocal, nil
+	case "remote", "network":
+		return SourceRemote, nil
+	case "mlat":
+		return SourceMLAT, nil
+	case "uat", "978":
+		return SourceUAT, nil
+	default:
+		return SourceUnknown, fmt.Errorf("unknown source %q (use local, remote, mlat or uat)", s)
+	}
+}
+
+// ParseSources parses a comma-separated list of sources, e.g. a precedence
+// order
+func ParseSources(s string) ([]Source, error) {
+	var sources []Source
+	for _, name := range strings.Split(s, ",") {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		source, err := ParseSource(name)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// Field is an aircraft value whose source is tracked
+type Field int
+
+const (
+	FieldCallsign Field = iota
+	FieldPosition
+	FieldAltitude
+	FieldSpeed
+	FieldTrack
+	FieldVerticalRate
+	FieldSquawk
+	numFields
+)
+
+// String returns the field name as shown in the detail view
+func (f Field) String() string {
+	switch f {
+	case FieldCallsign:
+		return "callsign"
+	case FieldPosition:
+		return "position"
+	case FieldAltitude:
+		return "altitude"
+	case FieldSpeed:
+		return "speed"
+	case FieldTrack:
+		return "track"
+	case FieldVerticalRate:
+		return "vertical rate"
+	default:
+		return "squawk"
+	}
+}
+
+// FieldSource records which source last set a field, and when
+type FieldSource struct {
+	Source Source
+	Time   time.Time
+}
+
+// DefaultPrecedence ranks sources from most to least trusted: the local
+// receiver's own ADS-B, then UAT and remote ADS-B, then MLAT, whose
+// positions are computed after the fact and lag
+var DefaultPrecedence = []Source{SourceLocal, SourceUAT, SourceRemote, SourceMLAT}
+
+// sourceHoldTime is how long a field set by a higher-precedence source is
+// kept before a lower one may overwrite it, so a source that stops
+// reporting a field doesn't freeze it
+const sourceHoldTime = 10 * time.Second
+
+// precedence ranks sources for merging; lower ranks win
+type precedence map[Source]int
+
+// newPrecedence ranks sources in order, with unlisted sources after them
+func newPrecedence(order []Source) precedence {
+	ranks := make(precedence)
+	for i, source := range order {
+		if _, ok := ranks[source]; !ok {
+			ranks[source] = i
+		}
+	}
+	return ranks
+}
+
+// rank returns a source's rank, with unlisted sources ranked last
+func (p precedence) rank(s Source) int {
+	if r, ok := p[s]; ok {
+		return r
+	}
+	return len(p)
+}
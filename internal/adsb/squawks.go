@@ -0,0 +1,64 @@
+package adsb
+
+import (
+	"ascii1090/internal/debug"
+	"time"
+)
+
+// SquawkChange is one squawk code an aircraft has set
+type SquawkChange struct {
+	Squawk string
+	Since  time.Time // When it was first received
+}
+
+// maxSquawkHistory is how many squawk codes are kept per aircraft
+const maxSquawkHistory = 20
+
+// IsEmergencySquawk reports whether a squawk code is one of the emergency
+// codes EmergencyKind names: 7500, 7600 or 7700
+func IsEmergencySquawk(squawk string) bool {
+	return squawk == "7500" || squawk == "7600" || squawk == "7700"
+}
+
+// PreviousSquawk returns the squawk the aircraft set before its current
+// one; ok is false while it has only ever sent one
+func (a *Aircraft) PreviousSquawk() (squawk string, ok bool) {
+	n := len(a.SquawkHistory)
+	if n < 2 || a.SquawkHistory[n-1].Squawk != a.Squawk {
+		return "", false
+	}
+	return a.SquawkHistory[n-2].Squawk, true
+}
+
+// recordSquawk adds the current squawk to the history if it has changed,
+// dropping the oldest past maxSquawkHistory. SquawkHistory is replaced
+// rather than changed in place, like Trail.
+func (a *Aircraft) recordSquawk(now time.Time) {
+	if a.Squawk == "" {
+		return
+	}
+
+	n := len(a.SquawkHistory)
+	if n > 0 {
+		previous := a.SquawkHistory[n-1].Squawk
+		if previous == a.Squawk {
+			return
+		}
+		level := debug.LevelInfo
+		if IsEmergencySquawk(a.Squawk) {
+			level = debug.LevelWarn
+		}
+		log.Event(level, "squawk_changed", a.ICAO, debug.Fields{
+			"from": previous,
+			"to":   a.Squawk,
+		})
+	}
+
+	start := 0
+	if n >= maxSquawkHistory {
+		start = n - maxSquawkHistory + 1
+	}
+	history := make([]SquawkChange, 0, n-start+1)
+	history = append(history, a.SquawkHistory[start:]...)
+	a.SquawkHistory = append(history, SquawkChange{Squawk: a.Squawk, Since: now})
+}
@@ -1,17 +1,35 @@
 package adsb
 
 import (
+	"ascii1090/internal/debug"
+	"ascii1090/internal/geo"
 	"context"
+	"math"
 	"sort"
 	"sync"
 	"time"
 )
 
+// maxPlausibleSpeed is the ground speed (knots) above which the distance
+// between two positions is logged as a jump, usually a bad CPR decode or
+// two aircraft merged under one ICAO
+const maxPlausibleSpeed = 1000
+
 // Tracker manages a collection of aircraft with thread-safe access
 type Tracker struct {
 	aircraft map[string]*Aircraft // Keyed by ICAO hex
 	mu       sync.RWMutex
 	timeout  time.Duration
+
+	// How updates from several feeds that disagree are merged
+	policy     MergePolicy
+	precedence precedence
+	latency    latencyEstimate
+
+	// Session totals, kept when aircraft are pruned
+	seen      map[string]struct{}
+	messages  int
+	callsigns map[string][]CallsignUse
 }
 
 // NewTracker creates a new aircraft tracker
@@ -22,13 +40,27 @@ func NewTracker(timeout time.Duration) *Tracker {
 	}
 
 	return &Tracker{
-		aircraft: make(map[string]*Aircraft),
-		timeout:  timeout,
+		aircraft:   make(map[string]*Aircraft),
+		timeout:    timeout,
+		seen:       make(map[string]struct{}),
+		callsigns:  make(map[string][]CallsignUse),
+		precedence: newPrecedence(DefaultPrecedence),
+		latency:    make(latencyEstimate),
 	}
 }
 
+// SetPrecedence sets the order sources are trusted in when merging, most
+// trusted first; see DefaultPrecedence
+func (t *Tracker) SetPrecedence(order []Source) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.precedence = newPrecedence(order)
+}
+
 // Update updates or adds an aircraft to the tracker
-// If the aircraft already exists, it merges the new data (keeping non-zero values)
+// If the aircraft already exists, it merges the new data (keeping non-zero
+// values). A field last set by a more trusted source keeps that value until
+// the source has been quiet about it for sourceHoldTime.
 func (t *Tracker) Update(ac *Aircraft) {
 	if ac == nil || ac.ICAO == "" {
 		return
@@ -37,45 +69,163 @@ func (t *Tracker) Update(ac *Aircraft) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
+	t.messages++
+	t.seen[ac.ICAO] = struct{}{}
+	t.latency.add(ac.Source, ac.generated, ac.LastSeen)
+
 	existing, exists := t.aircraft[ac.ICAO]
 	if !exists {
+		ac.Messages = 1
+		if log.Enabled(debug.LevelTrace) {
+			log.Event(debug.LevelTrace, "aircraft_new", ac.ICAO, debug.Fields{
+				"callsign": ac.FlightNumber,
+				"source":   ac.Source.String(),
+			})
+		}
+		ac.markSources()
+		ac.recordReceiver(ac.Feed, ac.Source, ac.LastSeen)
+		t.noteCallsign(ac, ac.LastSeen)
+		ac.recordSquawk(ac.LastSeen)
+		ac.Trend = nextTrend(TrendLevel, ac.VerticalRate)
+		if t.policy == MergeAverage && ac.PositionLocked() {
+			ac.averagePosition(ac)
+		}
+		if t.policy == MergeAverage && ac.Altitude != 0 {
+			ac.averageAltitude(ac)
+		}
+		ac.recordPosition(ac.LastSeen)
+		ac.recordAltitude(ac.LastSeen)
 		t.aircraft[ac.ICAO] = ac
 		return
 	}
 
+	previousSeen := existing.LastSeen
 	existing.LastSeen = ac.LastSeen
-
-	if ac.FlightNumber != "" {
-		existing.FlightNumber = ac.FlightNumber
+	existing.Source = ac.Source
+	existing.Feed = ac.Feed
+	existing.Messages++
+	existing.recordReceiver(ac.Feed, ac.Source, ac.LastSeen)
+
+	// accept claims field for this update's source if the policy allows
+	accept := func(field Field) bool {
+		if !t.accepts(existing, field, ac) {
+			if log.Enabled(debug.LevelTrace) {
+				log.Event(debug.LevelTrace, "merge_rejected", ac.ICAO, debug.Fields{
+					"field":  field.String(),
+					"source": ac.Source.String(),
+					"kept":   existing.Sources[field].Source.String(),
+					"policy": t.policy.String(),
+				})
+			}
+			return false
+		}
+		existing.Sources[field] = FieldSource{Source: ac.Source, Time: ac.LastSeen}
+		return true
 	}
 
-	if ac.Latitude != nil {
-		existing.Latitude = ac.Latitude
+	if ac.FlightNumber != "" && accept(FieldCallsign) {
+		existing.FlightNumber = ac.FlightNumber
+		t.noteCallsign(existing, ac.LastSeen)
 	}
 
-	if ac.Longitude != nil {
-		existing.Longitude = ac.Longitude
+	positioned := ac.PositionLocked() && accept(FieldPosition)
+	if positioned {
+		if existing.PositionLocked() {
+			logPositionJump(existing, ac, ac.LastSeen.Sub(previousSeen))
+		}
+		lat, lon := *ac.Latitude, *ac.Longitude
+		if t.policy == MergeAverage {
+			lat, lon = existing.averagePosition(ac)
+		}
+		existing.Latitude = &lat
+		existing.Longitude = &lon
 	}
+	existing.updatePositionSource(ac, positioned)
 
-	if ac.Altitude != 0 {
+	if ac.Altitude != 0 && accept(FieldAltitude) {
 		existing.Altitude = ac.Altitude
+		if t.policy == MergeAverage {
+			existing.Altitude = existing.averageAltitude(ac)
+		}
+		existing.recordAltitude(ac.LastSeen)
 	}
 
-	if ac.Speed != 0 {
+	if ac.Speed != 0 && accept(FieldSpeed) {
 		existing.Speed = ac.Speed
 	}
 
-	if ac.Heading != 0 {
-		existing.Heading = ac.Heading
+	if (ac.Heading != 0 || ac.Track != 0) && accept(FieldTrack) {
+		if ac.Heading != 0 {
+			existing.Heading = ac.Heading
+		}
+		if ac.Track != 0 {
+			existing.Track = ac.Track
+		}
+	}
+
+	// A reported rate of 0 is level flight, not a missing field
+	if (ac.VerticalRate != 0 || ac.reported&fieldVerticalRate != 0) && accept(FieldVerticalRate) {
+		existing.VerticalRate = ac.VerticalRate
+		existing.Trend = nextTrend(existing.Trend, ac.VerticalRate)
 	}
 
-	if ac.Track != 0 {
-		existing.Track = ac.Track
+	if ac.Category != "" {
+		existing.Category = ac.Category
 	}
 
-	if ac.VerticalRate != 0 {
-		existing.VerticalRate = ac.VerticalRate
+	if ac.Squawk != "" && accept(FieldSquawk) {
+		existing.Squawk = ac.Squawk
+		existing.recordSquawk(ac.LastSeen)
+		// The emergency flag rides along with squawk messages (MSG,6), so
+		// a squawk update without it means the emergency has cleared
+		existing.Emergency = ac.Emergency
+	} else if ac.Emergency {
+		existing.Emergency = true
+	}
+
+	if ac.reported&fieldAlert != 0 {
+		existing.Alert = ac.Alert
+	}
+	if ac.reported&fieldSPI != 0 {
+		existing.SPI = ac.SPI
 	}
+	if ac.reported&fieldGround != 0 {
+		existing.OnGround = ac.OnGround
+	}
+	existing.reported |= ac.reported
+
+	if positioned {
+		existing.recordPosition(ac.LastSeen)
+	}
+}
+
+// logPositionJump logs an update whose position is further from the last
+// one than the aircraft could have flown in elapsed
+func logPositionJump(existing, update *Aircraft, elapsed time.Duration) {
+	from := geo.LatLon{Lat: *existing.Latitude, Lon: *existing.Longitude}
+	to := geo.LatLon{Lat: *update.Latitude, Lon: *update.Longitude}
+	miles := geo.Distance(from, to)
+	hours := math.Max(elapsed.Hours(), 1.0/3600) // Updates within a second count as one
+	if miles*geo.NauticalMilesPerMile/hours <= maxPlausibleSpeed {
+		return
+	}
+	log.Event(debug.LevelWarn, "position_jump", update.ICAO, debug.Fields{
+		"from_lat": from.Lat,
+		"from_lon": from.Lon,
+		"to_lat":   to.Lat,
+		"to_lon":   to.Lon,
+		"miles":    math.Round(miles*10) / 10,
+		"seconds":  elapsed.Seconds(),
+	})
+}
+
+// Totals returns how many different aircraft have been seen and how many
+// messages have been processed since the tracker was created
+func (t *Tracker) Totals() (unique, messages int) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return len(t.seen), t.messages
 }
 
 // Get retrieves an aircraft by ICAO hex
@@ -105,6 +255,25 @@ func (t *Tracker) GetAll() []*Aircraft {
 	return aircraft
 }
 
+// Snapshot returns copies of all tracked aircraft sorted by ICAO, which
+// later updates don't change
+func (t *Tracker) Snapshot() []*Aircraft {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	aircraft := make([]*Aircraft, 0, len(t.aircraft))
+	for _, ac := range t.aircraft {
+		copied := *ac
+		aircraft = append(aircraft, &copied)
+	}
+
+	sort.Slice(aircraft, func(i, j int) bool {
+		return aircraft[i].ICAO < aircraft[j].ICAO
+	})
+
+	return aircraft
+}
+
 // GetWithPosition returns all aircraft that have valid position data
 func (t *Tracker) GetWithPosition() []*Aircraft {
 	all := t.GetAll()
@@ -137,6 +306,7 @@ func (t *Tracker) PruneStale() int {
 		if ac.IsStale() {
 			delete(t.aircraft, icao)
 			removed++
+			log.Event(debug.LevelTrace, "aircraft_pruned", icao, debug.Fields{"messages": ac.Messages})
 		}
 	}
 
@@ -0,0 +1,57 @@
+package adsb
+
+// VerticalTrend is whether an aircraft is climbing, level or descending
+type VerticalTrend int
+
+const (
+	TrendLevel VerticalTrend = iota
+	TrendClimbing
+	TrendDescending
+)
+
+// Vertical rates in feet per minute that start and end a climb or descent;
+// the gap between them keeps an aircraft hovering near one threshold, or
+// bumping through turbulence, from flickering between trends
+const (
+	trendEnterRate = 500
+	trendExitRate  = 200
+)
+
+// Arrow returns the list glyph for the trend: ↑ climbing, ↓ descending,
+// → level
+func (t VerticalTrend) Arrow() rune {
+	switch t {
+	case TrendClimbing:
+		return '↑'
+	case TrendDescending:
+		return '↓'
+	default:
+		return '→'
+	}
+}
+
+// nextTrend returns the trend after a vertical rate report, staying in a
+// climb or descent until the rate falls back below trendExitRate
+func nextTrend(current VerticalTrend, rate int) VerticalTrend {
+	switch {
+	case rate >= trendEnterRate:
+		return TrendClimbing
+	case rate <= -trendEnterRate:
+		return TrendDescending
+	case current == TrendClimbing && rate > trendExitRate:
+		return TrendClimbing
+	case current == TrendDescending && rate < -trendExitRate:
+		return TrendDescending
+	default:
+		return TrendLevel
+	}
+}
+
+// TrendArrow returns the aircraft's trend arrow, or a space until it has
+// reported a vertical rate
+func (a *Aircraft) TrendArrow() rune {
+	if a.reported&fieldVerticalRate == 0 {
+		return ' '
+	}
+	return a.Trend.Arrow()
+}
@@ -0,0 +1,84 @@
+package adsb
+
+// typeDescriptions names common ICAO aircraft type designators, so the
+// detail view reads "Boeing 737-800" rather than "B738" even when the
+// aircraft database only has designators
+var typeDescriptions = map[string]string{
+	// Airbus
+	"A19N": "Airbus A319neo",
+	"A20N": "Airbus A320neo",
+	"A21N": "Airbus A321neo",
+	"A319": "Airbus A319",
+	"A320": "Airbus A320",
+	"A321": "Airbus A321",
+	"A332": "Airbus A330-200",
+	"A333": "Airbus A330-300",
+	"A339": "Airbus A330-900",
+	"A359": "Airbus A350-900",
+	"A35K": "Airbus A350-1000",
+	"A388": "Airbus A380-800",
+	"BCS1": "Airbus A220-100",
+	"BCS3": "Airbus A220-300",
+
+	// Boeing
+	"B712": "Boeing 717-200",
+	"B737": "Boeing 737-700",
+	"B738": "Boeing 737-800",
+	"B739": "Boeing 737-900",
+	"B37M": "Boeing 737 MAX 7",
+	"B38M": "Boeing 737 MAX 8",
+	"B39M": "Boeing 737 MAX 9",
+	"B744": "Boeing 747-400",
+	"B748": "Boeing 747-8",
+	"B752": "Boeing 757-200",
+	"B753": "Boeing 757-300",
+	"B763": "Boeing 767-300",
+	"B764": "Boeing 767-400",
+	"B772": "Boeing 777-200",
+	"B77L": "Boeing 777-200LR",
+	"B77W": "Boeing 777-300ER",
+	"B788": "Boeing 787-8",
+	"B789": "Boeing 787-9",
+	"B78X": "Boeing 787-10",
+
+	// Regional
+	"AT72": "ATR 72",
+	"AT76": "ATR 72-600",
+	"CRJ2": "Bombardier CRJ200",
+	"CRJ7": "Bombardier CRJ700",
+	"CRJ9": "Bombardier CRJ900",
+	"DH8D": "De Havilland Dash 8-400",
+	"E135": "Embraer ERJ-135",
+	"E145": "Embraer ERJ-145",
+	"E170": "Embraer 170",
+	"E75L": "Embraer 175",
+	"E190": "Embraer 190",
+	"E195": "Embraer 195",
+	"E290": "Embraer 190-E2",
+
+	// Business and general aviation
+	"BE20": "Beechcraft King Air 200",
+	"BE36": "Beechcraft Bonanza",
+	"C172": "Cessna 172 Skyhawk",
+	"C182": "Cessna 182 Skylane",
+	"C208": "Cessna 208 Caravan",
+	"C25A": "Cessna Citation CJ2",
+	"C560": "Cessna Citation V",
+	"C68A": "Cessna Citation Latitude",
+	"CL35": "Bombardier Challenger 350",
+	"GLF4": "Gulfstream IV",
+	"GLF5": "Gulfstream V",
+	"GLF6": "Gulfstream G650",
+	"PC12": "Pilatus PC-12",
+	"P28A": "Piper PA-28 Cherokee",
+	"SR22": "Cirrus SR22",
+
+	// Helicopters
+	"AS50": "Airbus AS350 Ecureuil",
+	"B06":  "Bell 206 JetRanger",
+	"B407": "Bell 407",
+	"EC35": "Airbus EC135",
+	"EC45": "Airbus EC145",
+	"R44":  "Robinson R44",
+	"S76":  "Sikorsky S-76",
+}
@@ -0,0 +1,307 @@
+package alert
+
+import (
+	"ascii1090/internal/adsb"
+	"ascii1090/internal/geo"
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kind identifies why an aircraft raised an alert
+type Kind int
+
+const (
+	KindEmergency   Kind = iota // Squawking 7500/7600/7700 or emergency flag set
+	KindWatch                   // Matches the watchlist
+	KindProximity               // Low and close to the receiver
+	KindInteresting             // Listed in the plane-alert database
+)
+
+// String returns a short name for the alert kind
+func (k Kind) String() string {
+	switch k {
+	case KindEmergency:
+		return "EMERGENCY"
+	case KindWatch:
+		return "WATCH"
+	case KindProximity:
+		return "PROXIMITY"
+	case KindInteresting:
+		return "INTERESTING"
+	default:
+		return "ALERT"
+	}
+}
+
+// Alert is a condition raised for one aircraft
+type Alert struct {
+	ICAO    string
+	Kind    Kind
+	Message string    // Human-readable description, e.g. "UAL123 squawking 7700 (MAYDAY)"
+	Since   time.Time // When the condition was first seen
+	Squawk  string    // Emergency squawk raised for; a change raises it again
+}
+
+// Proximity is the zone around the receiver that raises a proximity alert:
+// aircraft within Radius and at or below Ceiling, but not on the ground
+type Proximity struct {
+	Center  geo.LatLon
+	Radius  float64   // Statute miles
+	Ceiling int       // Feet
+	Units   geo.Units // Units for the alert message
+}
+
+// MaxEvents is how many alert events are kept for the event log
+const MaxEvents = 100
+
+// DefaultProximityCeiling is the proximity ceiling in feet when none is set
+const DefaultProximityCeiling = 3000
+
+// Margins an aircraft with a proximity alert must move past the zone by
+// before the alert clears, so one skirting its edge doesn't raise it again
+// on every update
+const (
+	proximityExitRadius = 1.2 // Times the radius
+	proximityExitFeet   = 300 // Above the ceiling
+)
+
+// Manager evaluates aircraft against alert rules and tracks which
+// conditions are currently active. Alerts are edge-triggered: Evaluate
+// returns an alert only when its condition first appears.
+type Manager struct {
+	mu         sync.RWMutex
+	watchlist  []string
+	aircraftDB *adsb.AircraftDB   // Registrations for the watchlist, may be nil
+	planeAlert *adsb.PlaneAlertDB // Interesting aircraft, may be nil
+	proximity  *Proximity         // nil when proximity alerts are off
+	active     map[string]Alert   // Keyed by ICAO
+	events     []Alert
+}
+
+// NewManager creates an alert manager with an optional watchlist of ICAO
+// hex codes, callsigns or registrations (case-insensitive; a trailing *
+// matches a prefix). Registrations need SetAircraftDB.
+func NewManager(watchlist []string) *Manager {
+	normalized := make([]string, 0, len(watchlist))
+	for _, entry := range watchlist {
+		if entry = normalizeRegistration(entry); entry != "" {
+			normalized = append(normalized, entry)
+		}
+	}
+
+	return &Manager{
+		watchlist: normalized,
+		active:    make(map[string]Alert),
+	}
+}
+
+// SetAircraftDB sets the database registrations on the watchlist are
+// looked up in
+func (m *Manager) SetAircraftDB(db *adsb.AircraftDB) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.aircraftDB = db
+}
+
+// SetPlaneAlertDB turns on alerts for aircraft listed in a plane-alert
+// database
+func (m *Manager) SetPlaneAlertDB(db *adsb.PlaneAlertDB) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.planeAlert = db
+}
+
+// SetProximity turns on proximity alerts for aircraft inside the zone
+func (m *Manager) SetProximity(zone Proximity) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.proximity = &zone
+}
+
+// LoadWatchlist reads a watchlist file: one ICAO hex code, callsign or
+// registration per line, blank lines and # comments ignored
+func LoadWatchlist(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open watchlist: %w", err)
+	}
+	defer file.Close()
+
+	var entries []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			entries = append(entries, line)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read watchlist: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Evaluate checks every aircraft and returns alerts that became active
+// since the last call. Conditions that have cleared are dropped.
+func (m *Manager) Evaluate(aircraft []*adsb.Aircraft) []Alert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	current := make(map[string]Alert, len(m.active))
+	var raised []Alert
+
+	for _, ac := range aircraft {
+		alert, ok := m.check(ac)
+		if !ok {
+			continue
+		}
+
+		if previous, exists := m.active[ac.ICAO]; exists && previous.Kind == alert.Kind && previous.Squawk == alert.Squawk {
+			alert.Since = previous.Since
+		} else {
+			alert.Since = now
+			raised = append(raised, alert)
+			m.events = append(m.events, alert)
+		}
+		current[ac.ICAO] = alert
+	}
+
+	if len(m.events) > MaxEvents {
+		m.events = m.events[len(m.events)-MaxEvents:]
+	}
+
+	m.active = current
+	return raised
+}
+
+// check returns the highest-priority alert condition for an aircraft. An
+// emergency squawk the aircraft switched to while tracked says so, since a
+// transition from a normal code is news in a way a steady 7700 isn't.
+func (m *Manager) check(ac *adsb.Aircraft) (Alert, bool) {
+	if kind := ac.EmergencyKind(); kind != "" {
+		message := fmt.Sprintf("%s emergency (%s)", ac.DisplayName(), kind)
+		if ac.Squawk != "" {
+			message = fmt.Sprintf("%s squawking %s (%s)", ac.DisplayName(), ac.Squawk, kind)
+		}
+		squawk := ""
+		if adsb.IsEmergencySquawk(ac.Squawk) {
+			squawk = ac.Squawk
+			if previous, ok := ac.PreviousSquawk(); ok {
+				message = fmt.Sprintf("%s changed squawk %s → %s (%s)", ac.DisplayName(), previous, ac.Squawk, kind)
+			}
+		}
+		return Alert{ICAO: ac.ICAO, Kind: KindEmergency, Message: message, Squawk: squawk}, true
+	}
+
+	previous, exists := m.active[ac.ICAO]
+	if miles, ok := m.near(ac, exists && previous.Kind == KindProximity); ok {
+		units := m.proximity.Units
+		message := fmt.Sprintf("%s nearby: %s away at %d %s", ac.DisplayName(), units.FormatDistance(miles), units.Altitude(ac.Altitude), units.AltitudeUnit())
+		return Alert{ICAO: ac.ICAO, Kind: KindProximity, Message: message}, true
+	}
+
+	if match, ok := m.watched(ac); ok {
+		message := fmt.Sprintf("%s on watchlist", ac.DisplayName())
+		if info, ok := m.aircraftDB.Lookup(ac.ICAO); ok && match == normalizeRegistration(info.Registration) && match != normalizeRegistration(ac.DisplayName()) {
+			message = fmt.Sprintf("%s (%s) on watchlist", info.Registration, ac.DisplayName())
+		}
+		return Alert{ICAO: ac.ICAO, Kind: KindWatch, Message: message}, true
+	}
+
+	if info, ok := m.planeAlert.Lookup(ac.ICAO); ok {
+		return Alert{ICAO: ac.ICAO, Kind: KindInteresting, Message: fmt.Sprintf("%s interesting: %s", ac.DisplayName(), info)}, true
+	}
+
+	return Alert{}, false
+}
+
+// near returns how far an aircraft is from the receiver if it is inside the
+// proximity zone, widened by the exit margins if it is already alerting. An
+// aircraft that hasn't reported its altitude isn't assumed to be low.
+func (m *Manager) near(ac *adsb.Aircraft, alerting bool) (float64, bool) {
+	if m.proximity == nil {
+		return 0, false
+	}
+	radius, ceiling := m.proximity.Radius, m.proximity.Ceiling
+	if alerting {
+		radius *= proximityExitRadius
+		ceiling += proximityExitFeet
+	}
+	if !ac.PositionLocked() || ac.Altitude == 0 || ac.Altitude > ceiling {
+		return 0, false
+	}
+	if ac.GroundKnown() && ac.OnGround {
+		return 0, false
+	}
+	miles := geo.Distance(m.proximity.Center, geo.LatLon{Lat: *ac.Latitude, Lon: *ac.Longitude})
+	return miles, miles <= radius
+}
+
+// watched returns the ICAO hex code, callsign or registration by which an
+// aircraft matches the watchlist
+func (m *Manager) watched(ac *adsb.Aircraft) (string, bool) {
+	candidates := []string{strings.ToUpper(ac.ICAO), strings.ToUpper(strings.TrimSpace(ac.FlightNumber))}
+	if info, ok := m.aircraftDB.Lookup(ac.ICAO); ok {
+		candidates = append(candidates, normalizeRegistration(info.Registration))
+	}
+	for _, entry := range m.watchlist {
+		prefix, isPrefix := strings.CutSuffix(entry, "*")
+		for _, candidate := range candidates {
+			if candidate == "" {
+				continue
+			}
+			if candidate == entry || (isPrefix && strings.HasPrefix(candidate, prefix)) {
+				return candidate, true
+			}
+		}
+	}
+	return "", false
+}
+
+// normalizeRegistration uppercases a registration and drops its hyphen, so
+// "G-ABCD" and "gabcd" match
+func normalizeRegistration(registration string) string {
+	return strings.ReplaceAll(strings.ToUpper(strings.TrimSpace(registration)), "-", "")
+}
+
+// Active returns the alert currently raised for an aircraft, if any
+func (m *Manager) Active(icao string) (Alert, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	alert, ok := m.active[icao]
+	return alert, ok
+}
+
+// ActiveKinds returns the kind of every active alert keyed by ICAO
+func (m *Manager) ActiveKinds() map[string]Kind {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	kinds := make(map[string]Kind, len(m.active))
+	for icao, alert := range m.active {
+		kinds[icao] = alert.Kind
+	}
+	return kinds
+}
+
+// Events returns the most recent alert events, oldest first
+func (m *Manager) Events() []Alert {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]Alert(nil), m.events...)
+}
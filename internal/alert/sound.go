@@ -0,0 +1,88 @@
+package alert
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ParseKinds parses a comma-separated list of alert kinds ("emergency",
+// "watch", "proximity", "interesting", or "all") into the set of kinds it names
+func ParseKinds(s string) (map[Kind]bool, error) {
+	kinds := make(map[Kind]bool)
+	for _, name := range strings.Split(s, ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "":
+		case "all":
+			kinds[KindEmergency] = true
+			kinds[KindWatch] = true
+			kinds[KindProximity] = true
+			kinds[KindInteresting] = true
+		case "emergency":
+			kinds[KindEmergency] = true
+		case "watch":
+			kinds[KindWatch] = true
+		case "proximity":
+			kinds[KindProximity] = true
+		case "interesting":
+			kinds[KindInteresting] = true
+		default:
+			return nil, fmt.Errorf("unknown alert kind %q (use emergency, watch, proximity, interesting or all)", name)
+		}
+	}
+	return kinds, nil
+}
+
+// Sounder makes a noise when alerts of chosen kinds are raised: the
+// terminal bell, or a command such as a sound player
+type Sounder struct {
+	kinds   map[Kind]bool
+	command string
+	bell    func() error
+}
+
+// NewSounder creates a sounder for the given kinds. With an empty command
+// it rings bell; otherwise it runs the command through the shell with
+// ALERT_KIND, ALERT_ICAO and ALERT_MESSAGE set.
+func NewSounder(kinds map[Kind]bool, command string, bell func() error) *Sounder {
+	return &Sounder{
+		kinds:   kinds,
+		command: command,
+		bell:    bell,
+	}
+}
+
+// Play sounds once for a batch of newly raised alerts, for the first one
+// of an enabled kind, so a burst of alerts doesn't ring repeatedly
+func (s *Sounder) Play(raised []Alert) error {
+	if s == nil {
+		return nil
+	}
+
+	for _, alert := range raised {
+		if !s.kinds[alert.Kind] {
+			continue
+		}
+		if s.command == "" {
+			return s.bell()
+		}
+		return s.run(alert)
+	}
+	return nil
+}
+
+// run starts the sound command for an alert without waiting for it
+func (s *Sounder) run(alert Alert) error {
+	cmd := exec.Command("sh", "-c", s.command)
+	cmd.Env = append(os.Environ(),
+		"ALERT_KIND="+alert.Kind.String(),
+		"ALERT_ICAO="+alert.ICAO,
+		"ALERT_MESSAGE="+alert.Message,
+	)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to run alert sound command: %w", err)
+	}
+	go cmd.Wait()
+	return nil
+}
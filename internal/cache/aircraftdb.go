@@ -0,0 +1,262 @@
+package cache
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AircraftDBFile is the indexed aircraft database in the cache directory,
+// the default for -aircraft-db
+const AircraftDBFile = "aircraft.csv"
+
+// AircraftDBURLs are where OpenSky publishes its aircraft metadata
+// database, compressed first
+var AircraftDBURLs = []string{
+	"https://opensky-network.org/datasets/metadata/aircraftDatabase.zip",
+	"https://opensky-network.org/datasets/metadata/aircraftDatabase.csv",
+}
+
+// aircraftDBDownload is the name the database is looked for under mirrors
+const aircraftDBDownload = "aircraftDatabase.zip"
+
+// GetAircraftDBPath returns the path to the indexed aircraft database
+func (m *Manager) GetAircraftDBPath() string {
+	return filepath.Join(m.cacheDir, AircraftDBFile)
+}
+
+// EnsureAircraftDB downloads the OpenSky aircraft database (about 80 MB)
+// if not cached, and checks for a newer one once the cached copy is past
+// the CSV refresh age. Checks are conditional requests, so an unchanged
+// database isn't downloaded again. The download is decompressed and
+// indexed into a compact CSV of just the fields the detail view shows.
+func (m *Manager) EnsureAircraftDB() error {
+	dbPath := m.GetAircraftDBPath()
+	var known version
+	exists := false
+	if _, err := os.Stat(dbPath); err == nil {
+		reason := m.needsRefresh(AircraftDBFile, dbPath, m.csvMaxAge)
+		if reason == "" {
+			m.manifest.use(AircraftDBFile)
+			return nil
+		}
+		m.progress.printf("Checking for a newer aircraft database (%s)...\n", reason)
+		exists = true
+		if m.forced() == "" {
+			known = m.manifest.version(AircraftDBFile)
+		}
+	} else {
+		m.progress.printf("Downloading aircraft database from OpenSky (about 80 MB)...\n")
+	}
+
+	tmpFile, err := os.CreateTemp("", "aircraft_*.download")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpFile.Close()
+	defer os.Remove(tmpFile.Name())
+
+	latest, changed, err := m.fetchIfChanged("aircraft database", aircraftDBDownload, AircraftDBURLs, tmpFile.Name(), known)
+	if err != nil {
+		if exists {
+			m.progress.printf("Warning: failed to refresh aircraft database, keeping the cached copy: %v\n", err)
+			m.manifest.use(AircraftDBFile)
+			return nil
+		}
+		return err
+	}
+	if !changed {
+		m.progress.printf("Aircraft database is up to date\n")
+		m.manifest.recordVersion(AircraftDBFile, known)
+		return nil
+	}
+
+	count, err := indexAircraftDB(tmpFile.Name(), dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to index aircraft database: %w", err)
+	}
+
+	m.manifest.recordVersion(AircraftDBFile, latest)
+	m.progress.printf("Indexed %d aircraft\n", count)
+	return nil
+}
+
+// fetchIfChanged downloads the first of urls that works to dest, like
+// fetch, unless the server says the file still matches known. It returns
+// the new download's version and whether anything was downloaded.
+func (m *Manager) fetchIfChanged(name, fileName string, urls []string, dest string, known version) (version, bool, error) {
+	var err error
+	for i, url := range m.mirrored(fileName, urls) {
+		if i > 0 {
+			m.progress.printf("  %s: %v, trying %s\n", name, err, url)
+		}
+
+		var resp *http.Response
+		resp, err = m.getIfChanged(url, known.ETag, known.LastModified)
+		if err != nil {
+			continue
+		}
+		if resp.StatusCode == http.StatusNotModified {
+			resp.Body.Close()
+			return known, false, nil
+		}
+
+		err = m.save(resp, name, dest)
+		if err == nil {
+			return version{
+				ETag:         resp.Header.Get("ETag"),
+				LastModified: resp.Header.Get("Last-Modified"),
+			}, true, nil
+		}
+	}
+	if err == nil {
+		err = fmt.Errorf("no download URL")
+	}
+	return known, false, err
+}
+
+// aircraftDBHeader is the header of the indexed database; adsb's loader
+// reads these names
+var aircraftDBHeader = []string{"icao24", "registration", "typecode", "description", "operator"}
+
+// indexAircraftDB reads a downloaded database, plain, zipped or gzipped,
+// and writes the compact index to dest, returning the number of aircraft
+func indexAircraftDB(src, dest string) (int, error) {
+	file, err := os.Open(src)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	r, closer, err := decompress(file)
+	if err != nil {
+		return 0, err
+	}
+	defer closer.Close()
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, col := range header {
+		columns[strings.ToLower(strings.Trim(strings.TrimSpace(col), "'"))] = i
+	}
+	if _, ok := columns["icao24"]; !ok {
+		return 0, fmt.Errorf("no icao24 column in the download")
+	}
+
+	partPath := dest + ".part"
+	out, err := os.Create(partPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(partPath)
+
+	buffered := bufio.NewWriter(out)
+	writer := csv.NewWriter(buffered)
+	writer.Write(aircraftDBHeader)
+
+	count := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			continue
+		}
+
+		get := func(col string) string {
+			if i, ok := columns[col]; ok && i < len(record) {
+				return strings.Trim(strings.TrimSpace(record[i]), "'")
+			}
+			return ""
+		}
+
+		icao := strings.ToUpper(get("icao24"))
+		registration, typeCode := get("registration"), strings.ToUpper(get("typecode"))
+		description := strings.TrimSpace(get("manufacturername") + " " + get("model"))
+		operator := get("operator")
+		if operator == "" {
+			operator = get("owner")
+		}
+		if icao == "" || registration+typeCode+description+operator == "" {
+			continue
+		}
+
+		writer.Write([]string{icao, registration, typeCode, description, operator})
+		count++
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		out.Close()
+		return 0, fmt.Errorf("failed to write index: %w", err)
+	}
+	if err := buffered.Flush(); err != nil {
+		out.Close()
+		return 0, fmt.Errorf("failed to write index: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write index: %w", err)
+	}
+	if err := os.Rename(partPath, dest); err != nil {
+		return 0, fmt.Errorf("failed to save index: %w", err)
+	}
+	return count, nil
+}
+
+// decompress returns a reader for the CSV in file, which may be a zip
+// archive (the first .csv in it), gzipped or plain, going by its first bytes
+func decompress(file *os.File) (io.Reader, io.Closer, error) {
+	magic := make([]byte, 4)
+	n, _ := io.ReadFull(file, magic)
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case bytes.Equal(magic[:n], []byte("PK\x03\x04")):
+		info, err := file.Stat()
+		if err != nil {
+			return nil, nil, err
+		}
+		archive, err := zip.NewReader(file, info.Size())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open zip: %w", err)
+		}
+		for _, f := range archive.File {
+			if strings.EqualFold(filepath.Ext(f.Name), ".csv") {
+				rc, err := f.Open()
+				if err != nil {
+					return nil, nil, err
+				}
+				return rc, rc, nil
+			}
+		}
+		return nil, nil, fmt.Errorf("no CSV file in the zip")
+
+	case n >= 2 && magic[0] == 0x1f && magic[1] == 0x8b:
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open gzip: %w", err)
+		}
+		return gz, gz, nil
+
+	default:
+		return file, io.NopCloser(nil), nil
+	}
+}
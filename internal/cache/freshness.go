@@ -0,0 +1,211 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ManifestFile records when each cached dataset was downloaded
+const ManifestFile = "downloads.json"
+
+// DefaultCSVMaxAge is how old the OurAirports CSVs may get before they are
+// downloaded again; airports open, close and change codes all the time
+const DefaultCSVMaxAge = 30 * 24 * time.Hour
+
+// manifest records when cached files were downloaded and last used,
+// saved as JSON in the cache directory
+type manifest struct {
+	mu       sync.Mutex
+	path     string
+	entries  manifestEntries
+	inUse    map[string]bool // Files used by this run, never evicted
+	progress *progressBoard  // Where warnings are printed
+}
+
+// manifestEntries is the JSON form of a manifest
+type manifestEntries struct {
+	Downloaded map[string]time.Time `json:"downloaded"`
+	Used       map[string]time.Time `json:"used"`
+	Versions   map[string]version   `json:"versions,omitempty"`
+}
+
+// version holds the HTTP validators of a download, sent back to ask for
+// the file only if it has changed
+type version struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// loadManifest reads the manifest; a missing or unreadable one starts
+// empty, and file modification times stand in for its entries
+func loadManifest(path string, progress *progressBoard) *manifest {
+	mf := &manifest{path: path, inUse: make(map[string]bool), progress: progress}
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &mf.entries); err != nil {
+			progress.printf("Warning: ignoring %s: %v\n", filepath.Base(path), err)
+			mf.entries = manifestEntries{}
+		}
+	}
+	if mf.entries.Downloaded == nil {
+		mf.entries.Downloaded = make(map[string]time.Time)
+	}
+	if mf.entries.Used == nil {
+		mf.entries.Used = make(map[string]time.Time)
+	}
+	if mf.entries.Versions == nil {
+		mf.entries.Versions = make(map[string]version)
+	}
+	return mf
+}
+
+// downloadedAt returns when name was downloaded, falling back to the
+// modification time of file
+func (mf *manifest) downloadedAt(name, file string) (time.Time, bool) {
+	mf.mu.Lock()
+	at, ok := mf.entries.Downloaded[name]
+	mf.mu.Unlock()
+	if ok {
+		return at, true
+	}
+	return modTime(file)
+}
+
+// usedAt returns when name was last used, falling back to when it was
+// downloaded
+func (mf *manifest) usedAt(name, file string) (time.Time, bool) {
+	mf.mu.Lock()
+	at, ok := mf.entries.Used[name]
+	mf.mu.Unlock()
+	if ok {
+		return at, true
+	}
+	return mf.downloadedAt(name, file)
+}
+
+// record notes that name was just downloaded and saves the manifest
+func (mf *manifest) record(name string) {
+	mf.mu.Lock()
+	defer mf.mu.Unlock()
+
+	now := time.Now().UTC()
+	mf.entries.Downloaded[name] = now
+	mf.entries.Used[name] = now
+	mf.inUse[name] = true
+	mf.save()
+}
+
+// version returns the validators recorded for name
+func (mf *manifest) version(name string) version {
+	mf.mu.Lock()
+	defer mf.mu.Unlock()
+	return mf.entries.Versions[name]
+}
+
+// recordVersion notes that name was just downloaded as v and saves the
+// manifest
+func (mf *manifest) recordVersion(name string, v version) {
+	mf.mu.Lock()
+	mf.entries.Versions[name] = v
+	mf.mu.Unlock()
+	mf.record(name)
+}
+
+// use notes that name is used by this run and saves the manifest
+func (mf *manifest) use(name string) {
+	mf.mu.Lock()
+	defer mf.mu.Unlock()
+
+	mf.entries.Used[name] = time.Now().UTC()
+	mf.inUse[name] = true
+	mf.save()
+}
+
+// isInUse reports whether name is used by this run
+func (mf *manifest) isInUse(name string) bool {
+	mf.mu.Lock()
+	defer mf.mu.Unlock()
+	return mf.inUse[name]
+}
+
+// forget drops an evicted file's entries and saves the manifest
+func (mf *manifest) forget(name string) {
+	mf.mu.Lock()
+	defer mf.mu.Unlock()
+
+	delete(mf.entries.Downloaded, name)
+	delete(mf.entries.Used, name)
+	delete(mf.entries.Versions, name)
+	mf.save()
+}
+
+// save writes the manifest; the caller holds mu
+func (mf *manifest) save() {
+	data, err := json.MarshalIndent(mf.entries, "", "  ")
+	if err == nil {
+		err = os.WriteFile(mf.path, data, 0644)
+	}
+	if err != nil {
+		mf.progress.printf("Warning: failed to save %s: %v\n", filepath.Base(mf.path), err)
+	}
+}
+
+// modTime returns a file's modification time
+func modTime(file string) (time.Time, bool) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return info.ModTime(), true
+}
+
+// SetRefreshPolicy sets how old cached files may get before they are
+// downloaded again: csvMaxAge for the OurAirports CSVs and mapMaxAge for
+// the Natural Earth and FAA shapefiles (0 never refreshes). force
+// re-downloads everything regardless of age.
+func (m *Manager) SetRefreshPolicy(csvMaxAge, mapMaxAge time.Duration, force bool) {
+	m.csvMaxAge = csvMaxAge
+	m.mapMaxAge = mapMaxAge
+	if force {
+		m.setForced("forced with -refresh")
+	}
+}
+
+// forced returns why everything is downloaded again, "" normally
+func (m *Manager) forced() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.forcedReason
+}
+
+// setForced sets the forced reason and returns the previous one
+func (m *Manager) setForced(reason string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prev := m.forcedReason
+	m.forcedReason = reason
+	return prev
+}
+
+// needsRefresh reports why an existing cached file should be downloaded
+// again, or "" if it is fresh enough
+func (m *Manager) needsRefresh(name, file string, maxAge time.Duration) string {
+	if reason := m.forced(); reason != "" {
+		return reason
+	}
+	if maxAge <= 0 {
+		return ""
+	}
+
+	at, ok := m.manifest.downloadedAt(name, file)
+	if !ok {
+		return ""
+	}
+	if age := time.Since(at); age > maxAge {
+		return fmt.Sprintf("downloaded %d days ago", int(age.Hours()/24))
+	}
+	return ""
+}
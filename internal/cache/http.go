@@ -0,0 +1,158 @@
+package cache
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// userAgent identifies ascii1090 to the data servers
+const userAgent = "Mozilla/5.0 (compatible; ascii1090/1.0)"
+
+// NewHTTPClient creates the client downloads are made with
+// Proxies come from HTTP_PROXY, HTTPS_PROXY and NO_PROXY unless proxyURL
+// is given. Certificates in caBundle (PEM) are trusted on top of the
+// system roots, for networks that intercept TLS.
+func NewHTTPClient(proxyURL, caBundle string) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+
+	if proxyURL != "" {
+		proxy, err := url.Parse(proxyURL)
+		if err != nil || proxy.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", proxyURL)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if caBundle != "" {
+		pem, err := os.ReadFile(caBundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			roots = x509.NewCertPool()
+		}
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", caBundle)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: roots}
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// SetHTTPClient sets the client downloads are made with
+func (m *Manager) SetHTTPClient(client *http.Client) {
+	m.client = client
+}
+
+// get starts a download, failing on anything but 200 OK
+func (m *Manager) get(url string) (*http.Response, error) {
+	return m.getIfChanged(url, "", "")
+}
+
+// getIfChanged starts a conditional download, sending the ETag and
+// Last-Modified values from an earlier one when given; a 304 Not Modified
+// response is returned like 200 OK for the caller to check, but only when
+// one of them was sent, so an unconditional download never gets an empty
+// body
+func (m *Manager) getIfChanged(url, etag, lastModified string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", userAgent)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download: %w", err)
+	}
+
+	conditional := etag != "" || lastModified != ""
+	if resp.StatusCode != http.StatusOK && (resp.StatusCode != http.StatusNotModified || !conditional) {
+		resp.Body.Close()
+		return nil, fmt.Errorf("download failed with status: %s (URL: %s)", resp.Status, url)
+	}
+
+	return resp, nil
+}
+
+// SetMirrors sets base URLs tried before each file's own URLs; a file is
+// looked for under its file name, e.g. <base>/ne_50m_coastline.zip or
+// <base>/airports.csv
+func (m *Manager) SetMirrors(bases []string) {
+	m.mirrors = bases
+}
+
+// fetch downloads the first of urls that works to dest, after trying
+// fileName under each configured mirror, reporting progress under name
+func (m *Manager) fetch(name, fileName string, urls []string, dest string) error {
+	urls = m.mirrored(fileName, urls)
+
+	var err error
+	for i, url := range urls {
+		if i > 0 {
+			m.progress.printf("  %s: %v, trying %s\n", name, err, url)
+		}
+		if err = m.fetchURL(name, url, dest); err == nil {
+			return nil
+		}
+	}
+	if err == nil {
+		err = fmt.Errorf("no download URL")
+	}
+	return err
+}
+
+// mirrored returns fileName under each configured mirror followed by urls
+func (m *Manager) mirrored(fileName string, urls []string) []string {
+	var all []string
+	for _, base := range m.mirrors {
+		all = append(all, strings.TrimSuffix(base, "/")+"/"+fileName)
+	}
+	return append(all, urls...)
+}
+
+// fetchURL downloads one URL to dest, replacing its contents
+func (m *Manager) fetchURL(name, url, dest string) error {
+	resp, err := m.get(url)
+	if err != nil {
+		return err
+	}
+	return m.save(resp, name, dest)
+}
+
+// save writes a response body to dest with progress under name, closing
+// the body
+func (m *Manager) save(resp *http.Response, name, dest string) error {
+	defer resp.Body.Close()
+
+	file, err := os.Create(dest)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	progress := m.progress.track(resp.Body, name, resp.ContentLength)
+	_, err = io.Copy(file, progress)
+	progress.Finish()
+	if err != nil {
+		return fmt.Errorf("failed to save download: %w", err)
+	}
+	return file.Close()
+}
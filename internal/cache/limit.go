@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// evictableFiles are the optional datasets EnforceLimit may delete; any of
+// them is downloaded again the next time it is asked for
+func evictableFiles() []DataFile {
+	return append([]DataFile{GlobalRoadsFile}, HighDetailFiles...)
+}
+
+// SetSizeLimit sets the cache size budget in bytes, 0 for no limit
+func (m *Manager) SetSizeLimit(bytes int64) {
+	m.sizeLimit = bytes
+}
+
+// EnforceLimit deletes optional datasets not used by this run, least
+// recently used first, until the cache fits its size limit. Required data
+// is never deleted, so a small limit may still be exceeded.
+func (m *Manager) EnforceLimit() error {
+	if m.sizeLimit <= 0 {
+		return nil
+	}
+
+	size, err := dirSize(m.cacheDir)
+	if err != nil {
+		return fmt.Errorf("failed to measure cache: %w", err)
+	}
+	if size <= m.sizeLimit {
+		return nil
+	}
+
+	type candidate struct {
+		file  DataFile
+		files []string
+		used  time.Time
+	}
+	var candidates []candidate
+	for _, file := range evictableFiles() {
+		if m.manifest.isInUse(file.Base) {
+			continue
+		}
+		files, _ := filepath.Glob(filepath.Join(m.cacheDir, file.Base+".*"))
+		if len(files) == 0 {
+			continue
+		}
+		used, _ := m.manifest.usedAt(file.Base, filepath.Join(m.cacheDir, file.Base+".shp"))
+		candidates = append(candidates, candidate{file, files, used})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].used.Before(candidates[j].used)
+	})
+
+	for _, c := range candidates {
+		if size <= m.sizeLimit {
+			break
+		}
+		freed := int64(0)
+		for _, path := range c.files {
+			if info, err := os.Stat(path); err == nil && os.Remove(path) == nil {
+				freed += info.Size()
+			}
+		}
+		m.manifest.forget(c.file.Base)
+		size -= freed
+		m.progress.printf("Evicted %s from the cache (%s, last used %s)\n",
+			c.file.Name, formatBytes(freed), c.used.Format("2006-01-02"))
+	}
+
+	if size > m.sizeLimit {
+		m.progress.printf("Warning: cache is %s, over its %s limit, with nothing left to evict\n",
+			formatBytes(size), formatBytes(m.sizeLimit))
+	}
+	return nil
+}
+
+// dirSize totals the sizes of the files directly in dir
+func dirSize(dir string) (int64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var size int64
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		if info, err := entry.Info(); err == nil {
+			size += info.Size()
+		}
+	}
+	return size, nil
+}
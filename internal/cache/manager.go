@@ -8,142 +8,289 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 )
 
 // Manager handles downloading and caching Natural Earth data
 type Manager struct {
 	cacheDir string
+	progress *progressBoard // Where download progress and messages are reported
+	client   *http.Client
+	mirrors  []string // Base URLs tried before each file's own URLs
+
+	manifest     *manifest     // When each cached file was downloaded
+	csvMaxAge    time.Duration // Age after which OurAirports CSVs are refreshed
+	mapMaxAge    time.Duration // Age after which shapefiles are refreshed, 0 for never
+	forcedReason string        // Why everything is downloaded again, "" normally
+	mu           sync.Mutex    // Guards forcedReason, which Redownload sets from the TUI
+	sizeLimit    int64         // Cache size budget in bytes, 0 for none
 }
 
 // DataFile represents a Natural Earth dataset to download
 type DataFile struct {
-	Name     string // Friendly name
-	URL      string // Download URL
-	Base     string // Base filename (without extension)
-	Optional bool   // If true, failure to download won't stop the app
+	Name     string   // Friendly name
+	URLs     []string // Download URLs, tried in order
+	Base     string   // Base filename (without extension)
+	Optional bool     // If true, failure to download won't stop the app
 }
 
 // Natural Earth datasets - using 1:50m (medium detail) for most features
 var NaturalEarthFiles = []DataFile{
 	{
-		Name:     "States/Provinces",
-		URL:      "https://naciscdn.org/naturalearth/50m/cultural/ne_50m_admin_1_states_provinces.zip",
+		Name: "States/Provinces",
+		URLs: []string{
+			"https://naciscdn.org/naturalearth/50m/cultural/ne_50m_admin_1_states_provinces.zip",
+			"https://naturalearth.s3.amazonaws.com/50m_cultural/ne_50m_admin_1_states_provinces.zip",
+		},
 		Base:     "ne_50m_admin_1_states_provinces",
 		Optional: true, // Optional - app can work without it
 	},
 	{
-		Name:     "Rivers",
-		URL:      "https://naciscdn.org/naturalearth/50m/physical/ne_50m_rivers_lake_centerlines.zip",
+		Name: "Rivers",
+		URLs: []string{
+			"https://naciscdn.org/naturalearth/50m/physical/ne_50m_rivers_lake_centerlines.zip",
+			"https://naturalearth.s3.amazonaws.com/50m_physical/ne_50m_rivers_lake_centerlines.zip",
+		},
 		Base:     "ne_50m_rivers_lake_centerlines",
 		Optional: true,
 	},
 	{
-		Name:     "Coastlines",
-		URL:      "https://naciscdn.org/naturalearth/50m/physical/ne_50m_coastline.zip",
+		Name: "Coastlines",
+		URLs: []string{
+			"https://naciscdn.org/naturalearth/50m/physical/ne_50m_coastline.zip",
+			"https://naturalearth.s3.amazonaws.com/50m_physical/ne_50m_coastline.zip",
+		},
 		Base:     "ne_50m_coastline",
 		Optional: true,
 	},
 	{
-		Name:     "Populated Places",
-		URL:      "https://naciscdn.org/naturalearth/50m/cultural/ne_50m_populated_places.zip",
+		Name: "Populated Places",
+		URLs: []string{
+			"https://naciscdn.org/naturalearth/50m/cultural/ne_50m_populated_places.zip",
+			"https://naturalearth.s3.amazonaws.com/50m_cultural/ne_50m_populated_places.zip",
+		},
 		Base:     "ne_50m_populated_places",
 		Optional: true,
 	},
 	{
-		Name:     "Roads (North America)",
-		URL:      "https://naciscdn.org/naturalearth/10m/cultural/ne_10m_roads_north_america.zip",
+		Name: "Roads (North America)",
+		URLs: []string{
+			"https://naciscdn.org/naturalearth/10m/cultural/ne_10m_roads_north_america.zip",
+			"https://naturalearth.s3.amazonaws.com/10m_cultural/ne_10m_roads_north_america.zip",
+		},
 		Base:     "ne_10m_roads_north_america",
 		Optional: true,
 	},
+	{
+		Name: "Time Zones",
+		URLs: []string{
+			"https://naciscdn.org/naturalearth/10m/cultural/ne_10m_time_zones.zip",
+			"https://naturalearth.s3.amazonaws.com/10m_cultural/ne_10m_time_zones.zip",
+		},
+		Base:     "ne_10m_time_zones",
+		Optional: true,
+	},
+}
+
+// GlobalRoadsFile is the worldwide Natural Earth roads dataset, downloaded
+// only on request since it is large and North American users don't need it
+var GlobalRoadsFile = DataFile{
+	Name: "Roads (Global)",
+	URLs: []string{
+		"https://naciscdn.org/naturalearth/10m/cultural/ne_10m_roads.zip",
+		"https://naturalearth.s3.amazonaws.com/10m_cultural/ne_10m_roads.zip",
+	},
+	Base:     "ne_10m_roads",
+	Optional: true,
+}
+
+// HighDetailFiles are the 1:10m coastline, river and state border datasets,
+// downloaded only with -detail high since the 50m ones look blocky when
+// zoomed in below about 50 miles
+var HighDetailFiles = []DataFile{
+	{
+		Name: "States/Provinces (10m)",
+		URLs: []string{
+			"https://naciscdn.org/naturalearth/10m/cultural/ne_10m_admin_1_states_provinces.zip",
+			"https://naturalearth.s3.amazonaws.com/10m_cultural/ne_10m_admin_1_states_provinces.zip",
+		},
+		Base:     "ne_10m_admin_1_states_provinces",
+		Optional: true,
+	},
+	{
+		Name: "Rivers (10m)",
+		URLs: []string{
+			"https://naciscdn.org/naturalearth/10m/physical/ne_10m_rivers_lake_centerlines.zip",
+			"https://naturalearth.s3.amazonaws.com/10m_physical/ne_10m_rivers_lake_centerlines.zip",
+		},
+		Base:     "ne_10m_rivers_lake_centerlines",
+		Optional: true,
+	},
+	{
+		Name: "Coastlines (10m)",
+		URLs: []string{
+			"https://naciscdn.org/naturalearth/10m/physical/ne_10m_coastline.zip",
+			"https://naturalearth.s3.amazonaws.com/10m_physical/ne_10m_coastline.zip",
+		},
+		Base:     "ne_10m_coastline",
+		Optional: true,
+	},
+}
+
+// AirspaceFile is the FAA class airspace shapefile (US coverage only)
+var AirspaceFile = DataFile{
+	Name:     "Class Airspace (FAA)",
+	URLs:     []string{"https://opendata.arcgis.com/api/v3/datasets/c6a62360338e408cb1512366ad61559e_0/downloads/data?format=shp&spatialRefId=4326"},
+	Base:     "Class_Airspace",
+	Optional: true,
 }
 
 // NewManager creates a new cache manager
 // If cacheDir is empty, uses ~/.ascii1090/data
 func NewManager(cacheDir string) (*Manager, error) {
 	if cacheDir == "" {
-		home, err := os.UserHomeDir()
+		baseDir, err := BaseDir()
 		if err != nil {
-			return nil, fmt.Errorf("failed to get home directory: %w", err)
+			return nil, err
 		}
-		cacheDir = filepath.Join(home, ".ascii1090", "data")
+		cacheDir = filepath.Join(baseDir, "data")
 	}
 
 	if err := os.MkdirAll(cacheDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create cache directory: %w", err)
 	}
 
+	progress := newProgressBoard(os.Stdout)
 	return &Manager{
-		cacheDir: cacheDir,
+		cacheDir:  cacheDir,
+		progress:  progress,
+		client:    http.DefaultClient,
+		manifest:  loadManifest(filepath.Join(cacheDir, ManifestFile), progress),
+		csvMaxAge: DefaultCSVMaxAge,
 	}, nil
 }
 
 // EnsureData ensures all required Natural Earth data is available
 // Downloads missing files automatically
 // Optional files that fail to download will be skipped with a warning
+// Files are fetched in parallel, at most MaxConcurrentDownloads at a time
 func (m *Manager) EnsureData() error {
+	var jobs []downloadJob
 	for _, file := range NaturalEarthFiles {
-		if err := m.ensureFile(file); err != nil {
-			if file.Optional {
-				fmt.Printf("Warning: Skipping %s (optional): %v\n", file.Name, err)
-				continue
-			}
-			return fmt.Errorf("failed to ensure %s: %w", file.Name, err)
-		}
+		jobs = append(jobs, downloadJob{file.Name, file.Optional, func() error { return m.ensureFile(file) }})
+	}
+	jobs = append(jobs,
+		downloadJob{AirspaceFile.Name, true, func() error { return m.ensureFile(AirspaceFile) }},
+		downloadJob{"airports", true, m.EnsureAirportData},
+		downloadJob{"runways", true, m.EnsureRunwayData},
+		downloadJob{"navaids", true, m.EnsureNavaidData},
+	)
+
+	errs := make([]error, len(jobs))
+	slots := make(chan struct{}, MaxConcurrentDownloads)
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			errs[i] = job.run()
+		}()
 	}
+	wg.Wait()
 
-	// Download airport database (optional)
-	if err := m.EnsureAirportData(); err != nil {
-		fmt.Printf("Warning: Failed to download airports (optional): %v\n", err)
+	for i, job := range jobs {
+		if errs[i] == nil {
+			continue
+		}
+		if !job.optional {
+			return fmt.Errorf("failed to ensure %s: %w", job.name, errs[i])
+		}
+		m.progress.printf("Warning: Skipping %s (optional): %v\n", job.name, errs[i])
 	}
 
 	return nil
 }
 
-// ensureFile checks if a data file exists, downloads if needed
-func (m *Manager) ensureFile(file DataFile) error {
-	shpPath := filepath.Join(m.cacheDir, file.Base+".shp")
-	if _, err := os.Stat(shpPath); err == nil {
-		return nil
-	}
+// MaxConcurrentDownloads bounds how many files EnsureData fetches at once
+const MaxConcurrentDownloads = 4
 
-	fmt.Printf("Downloading %s...\n", file.Name)
+// downloadJob is one dataset for EnsureData to fetch
+type downloadJob struct {
+	name     string
+	optional bool
+	run      func() error
+}
 
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", file.URL, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
+// EnsureGlobalRoads downloads the worldwide roads dataset if not cached
+func (m *Manager) EnsureGlobalRoads() error {
+	return m.ensureFile(GlobalRoadsFile)
+}
 
-	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; ascii1090/1.0)")
+// EnsureHighDetail downloads the 1:10m coastline, river and state border
+// datasets if not cached; like EnsureData it fetches them in parallel, and
+// since they are optional a failure only prints a warning
+func (m *Manager) EnsureHighDetail() {
+	var wg sync.WaitGroup
+	errs := make([]error, len(HighDetailFiles))
+	for i, file := range HighDetailFiles {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = m.ensureFile(file)
+		}()
+	}
+	wg.Wait()
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to download: %w", err)
+	for i, file := range HighDetailFiles {
+		if errs[i] != nil {
+			m.progress.printf("Warning: Skipping %s (optional), using 50m data: %v\n", file.Name, errs[i])
+		}
 	}
-	defer resp.Body.Close()
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download failed with status: %s (URL: %s)", resp.Status, file.URL)
+// ensureFile checks if a data file exists, downloads if needed
+// A cached file past the refresh age is downloaded again, keeping the old
+// copy if that fails
+func (m *Manager) ensureFile(file DataFile) error {
+	shpPath := filepath.Join(m.cacheDir, file.Base+".shp")
+	refreshing := false
+	if _, err := os.Stat(shpPath); err == nil {
+		reason := m.needsRefresh(file.Base, shpPath, m.mapMaxAge)
+		if reason == "" {
+			m.manifest.use(file.Base)
+			return nil
+		}
+		m.progress.printf("Refreshing %s (%s)...\n", file.Name, reason)
+		refreshing = true
+	} else {
+		m.progress.printf("Downloading %s...\n", file.Name)
 	}
 
 	tmpFile, err := os.CreateTemp("", "ne_*.zip")
 	if err != nil {
 		return fmt.Errorf("failed to create temp file: %w", err)
 	}
+	tmpFile.Close()
 	defer os.Remove(tmpFile.Name())
-	defer tmpFile.Close()
 
-	if _, err := io.Copy(tmpFile, resp.Body); err != nil {
-		return fmt.Errorf("failed to save download: %w", err)
+	if err := m.fetch(file.Name, file.Base+".zip", file.URLs, tmpFile.Name()); err != nil {
+		if refreshing {
+			m.progress.printf("Warning: failed to refresh %s, keeping the cached copy: %v\n", file.Name, err)
+			m.manifest.use(file.Base)
+			return nil
+		}
+		return err
 	}
 
-	tmpFile.Close()
-
 	if err := m.extractZip(tmpFile.Name(), m.cacheDir); err != nil {
 		return fmt.Errorf("failed to extract: %w", err)
 	}
 
-	fmt.Printf("Downloaded and extracted %s\n", file.Name)
+	m.manifest.record(file.Base)
+	m.progress.printf("Downloaded and extracted %s\n", file.Name)
 	return nil
 }
 
@@ -194,49 +341,87 @@ func (m *Manager) GetCacheDir() string {
 
 // EnsureAirportData downloads the OurAirports CSV if not already cached
 func (m *Manager) EnsureAirportData() error {
-	csvPath := filepath.Join(m.cacheDir, "airports.csv")
-
-	if _, err := os.Stat(csvPath); err == nil {
-		return nil
-	}
+	return m.ensureCSV("airport database", "OurAirports", ourAirportsURLs("airports.csv"), m.GetAirportCSVPath())
+}
 
-	fmt.Println("Downloading airport database from OurAirports...")
+// EnsureNavaidData downloads the OurAirports navaids CSV if not already cached
+func (m *Manager) EnsureNavaidData() error {
+	return m.ensureCSV("navaid database", "OurAirports", ourAirportsURLs("navaids.csv"), m.GetNavaidCSVPath())
+}
 
-	url := "https://davidmegginson.github.io/ourairports-data/airports.csv"
+// EnsureRunwayData downloads the OurAirports runways CSV if not already cached
+func (m *Manager) EnsureRunwayData() error {
+	return m.ensureCSV("runway database", "OurAirports", ourAirportsURLs("runways.csv"), m.GetRunwayCSVPath())
+}
 
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+// ensureCSV downloads a plain CSV file from source to csvPath unless it
+// already exists and is younger than the CSV refresh age; a failed refresh
+// keeps the old copy
+func (m *Manager) ensureCSV(name, source string, urls []string, csvPath string) error {
+	fileName := filepath.Base(csvPath)
+	refreshing := false
+	if _, err := os.Stat(csvPath); err == nil {
+		reason := m.needsRefresh(fileName, csvPath, m.csvMaxAge)
+		if reason == "" {
+			m.manifest.use(fileName)
+			return nil
+		}
+		m.progress.printf("Refreshing %s (%s)...\n", name, reason)
+		refreshing = true
+	} else {
+		m.progress.printf("Downloading %s from %s...\n", name, source)
 	}
 
-	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; ascii1090/1.0)")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to download airports: %w", err)
+	// Download beside the old file so it survives a failed refresh
+	partPath := csvPath + ".part"
+	if err := m.fetch(name, fileName, urls, partPath); err != nil {
+		os.Remove(partPath)
+		if refreshing {
+			m.progress.printf("Warning: failed to refresh %s, keeping the cached copy: %v\n", name, err)
+			m.manifest.use(fileName)
+			return nil
+		}
+		return err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download failed with status: %s", resp.Status)
+	if err := os.Rename(partPath, csvPath); err != nil {
+		return fmt.Errorf("failed to save %s: %w", fileName, err)
 	}
 
-	outFile, err := os.Create(csvPath)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
-	}
-	defer outFile.Close()
+	m.manifest.record(fileName)
+	m.progress.printf("Downloaded %s successfully\n", name)
+	return nil
+}
 
-	if _, err := io.Copy(outFile, resp.Body); err != nil {
-		return fmt.Errorf("failed to save airports CSV: %w", err)
+// ourAirportsURLs returns the OurAirports download URLs for a CSV file:
+// the GitHub mirror, then the main site
+func ourAirportsURLs(name string) []string {
+	return []string{
+		"https://davidmegginson.github.io/ourairports-data/" + name,
+		"https://ourairports.com/data/" + name,
 	}
-
-	fmt.Println("Downloaded airport database successfully")
-	return nil
 }
 
 // GetAirportCSVPath returns the path to the airports CSV file
 func (m *Manager) GetAirportCSVPath() string {
 	return filepath.Join(m.cacheDir, "airports.csv")
 }
+
+// GetRunwayCSVPath returns the path to the runways CSV file
+func (m *Manager) GetRunwayCSVPath() string {
+	return filepath.Join(m.cacheDir, "runways.csv")
+}
+
+// GetNavaidCSVPath returns the path to the navaids CSV file
+func (m *Manager) GetNavaidCSVPath() string {
+	return filepath.Join(m.cacheDir, "navaids.csv")
+}
+
+// BaseDir returns the ascii1090 home directory (~/.ascii1090) used for
+// cached data and user files such as overlays
+func BaseDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".ascii1090"), nil
+}
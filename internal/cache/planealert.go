@@ -0,0 +1,25 @@
+package cache
+
+import "path/filepath"
+
+// PlaneAlertFile is the plane-alert database in the cache directory, the
+// default for -plane-alert
+const PlaneAlertFile = "plane-alert-db.csv"
+
+// PlaneAlertURLs are where the community plane-alert database of
+// interesting aircraft (government, military, notable owners) is published
+var PlaneAlertURLs = []string{
+	"https://raw.githubusercontent.com/sdr-enthusiasts/plane-alert-db/main/plane-alert-db.csv",
+}
+
+// GetPlaneAlertPath returns the path to the plane-alert database
+func (m *Manager) GetPlaneAlertPath() string {
+	return filepath.Join(m.cacheDir, PlaneAlertFile)
+}
+
+// EnsurePlaneAlert downloads the plane-alert database if not cached, and
+// again once it is past the CSV refresh age, since new airframes are added
+// to it every week
+func (m *Manager) EnsurePlaneAlert() error {
+	return m.ensureCSV("plane-alert database", "plane-alert-db", PlaneAlertURLs, m.GetPlaneAlertPath())
+}
@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// progressInterval is how often download progress is redrawn
+const progressInterval = 250 * time.Millisecond
+
+// progressBoard reports the downloads in flight on one carriage-return
+// redrawn line: the names of the files downloading, then bytes, percentage
+// and speed for the whole batch. Messages printed through it go above the
+// line.
+type progressBoard struct {
+	mu        sync.Mutex
+	out       io.Writer
+	downloads []*progressReader
+	started   time.Time // When the current batch of downloads began
+	doneRead  int64     // Bytes of the batch's finished downloads
+	doneTotal int64     // Their sizes, -1 if one was unknown
+	drawn     time.Time
+	width     int // Length of the line on screen, 0 if none
+}
+
+// newProgressBoard creates a board writing to out
+func newProgressBoard(out io.Writer) *progressBoard {
+	return &progressBoard{out: out}
+}
+
+// progressReader counts the bytes of one download as they are read
+type progressReader struct {
+	r     io.Reader
+	board *progressBoard
+	name  string
+	total int64 // From Content-Length, -1 if unknown
+	read  int64
+}
+
+// track wraps r so its progress shows on the board under name
+func (b *progressBoard) track(r io.Reader, name string, total int64) *progressReader {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.downloads) == 0 {
+		b.started = time.Now()
+		b.drawn = b.started
+		b.doneRead, b.doneTotal = 0, 0
+	}
+	p := &progressReader{r: r, board: b, name: name, total: total}
+	b.downloads = append(b.downloads, p)
+	return p
+}
+
+// Read counts bytes as they pass and redraws the board now and then
+func (p *progressReader) Read(buf []byte) (int, error) {
+	n, err := p.r.Read(buf)
+
+	b := p.board
+	b.mu.Lock()
+	p.read += int64(n)
+	if now := time.Now(); now.Sub(b.drawn) >= progressInterval {
+		b.drawn = now
+		b.draw(now)
+	}
+	b.mu.Unlock()
+
+	return n, err
+}
+
+// Finish takes the download off the board
+func (p *progressReader) Finish() {
+	b := p.board
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i, download := range b.downloads {
+		if download == p {
+			b.downloads = append(b.downloads[:i], b.downloads[i+1:]...)
+			// Keep counting it so the batch's percentage doesn't jump back
+			b.doneRead += p.read
+			b.doneTotal = addTotal(b.doneTotal, p.total)
+			break
+		}
+	}
+	b.clear()
+	if len(b.downloads) > 0 {
+		b.draw(time.Now())
+	}
+}
+
+// printf prints a message line, keeping the progress line below it
+func (b *progressBoard) printf(format string, args ...any) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.clear()
+	fmt.Fprintf(b.out, format, args...)
+	if len(b.downloads) > 0 {
+		b.draw(time.Now())
+	}
+}
+
+// draw writes the progress line over the previous one; b.mu must be held
+func (b *progressBoard) draw(now time.Time) {
+	read, total := b.doneRead, b.doneTotal
+	names := make([]string, len(b.downloads))
+	for i, download := range b.downloads {
+		read += download.read
+		total = addTotal(total, download.total)
+		names[i] = download.name
+	}
+
+	line := fmt.Sprintf("  %s: %s", strings.Join(names, ", "), formatBytes(read))
+	if total > 0 {
+		line += fmt.Sprintf(" / %s (%d%%)", formatBytes(total), read*100/total)
+	}
+	if elapsed := now.Sub(b.started).Seconds(); elapsed > 0 {
+		line += fmt.Sprintf("  %s/s", formatBytes(int64(float64(read)/elapsed)))
+	}
+
+	pad := max(b.width-len(line), 0)
+	b.width = len(line)
+	fmt.Fprintf(b.out, "\r%s%s", line, strings.Repeat(" ", pad))
+}
+
+// addTotal adds a download's size to a batch total, which becomes -1 once
+// one size is unknown since the percentage would be meaningless
+func addTotal(total, size int64) int64 {
+	if total < 0 || size <= 0 {
+		return -1
+	}
+	return total + size
+}
+
+// clear blanks the progress line; b.mu must be held
+func (b *progressBoard) clear() {
+	if b.width > 0 {
+		fmt.Fprintf(b.out, "\r%s\r", strings.Repeat(" ", b.width))
+		b.width = 0
+	}
+}
+
+// formatBytes formats a byte count as B, KB or MB
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.0f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
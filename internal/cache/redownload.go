@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Datasets are the names Redownload accepts
+var Datasets = []string{
+	"states", "rivers", "coastlines", "places", "roads", "timezones", "airspace",
+	"global-roads", "high-detail", "airports", "runways", "navaids", "aircraft-db", "plane-alert",
+}
+
+// naturalEarthDatasets maps dataset names to the base name of their
+// Natural Earth file
+var naturalEarthDatasets = map[string]string{
+	"states":     "ne_50m_admin_1_states_provinces",
+	"rivers":     "ne_50m_rivers_lake_centerlines",
+	"coastlines": "ne_50m_coastline",
+	"places":     "ne_50m_populated_places",
+	"roads":      "ne_10m_roads_north_america",
+	"timezones":  "ne_10m_time_zones",
+}
+
+// SetOutput sets where download progress and messages are written, stdout
+// by default; the TUI points it at a popup while it owns the terminal
+func (m *Manager) SetOutput(out io.Writer) {
+	m.progress.mu.Lock()
+	defer m.progress.mu.Unlock()
+	m.progress.out = out
+	m.progress.width = 0
+}
+
+// Redownload fetches one of Datasets again even if the cached copy is
+// fresh, to replace one that got corrupted; like a refresh, a failed
+// download keeps the cached copy. It must not run alongside other
+// downloads by the same manager.
+func (m *Manager) Redownload(name string) error {
+	ensure, ok := m.dataset(name)
+	if !ok {
+		return fmt.Errorf("unknown dataset %q (use %s)", name, strings.Join(Datasets, ", "))
+	}
+
+	prev := m.setForced("re-download requested")
+	defer m.setForced(prev)
+	return ensure()
+}
+
+// dataset returns the function that downloads a named dataset
+func (m *Manager) dataset(name string) (func() error, bool) {
+	if base, ok := naturalEarthDatasets[name]; ok {
+		for _, file := range NaturalEarthFiles {
+			if file.Base == base {
+				return func() error { return m.ensureFile(file) }, true
+			}
+		}
+	}
+
+	switch name {
+	case "airspace":
+		return func() error { return m.ensureFile(AirspaceFile) }, true
+	case "global-roads":
+		return m.EnsureGlobalRoads, true
+	case "high-detail":
+		return func() error {
+			var errs []error
+			for _, file := range HighDetailFiles {
+				errs = append(errs, m.ensureFile(file))
+			}
+			return errors.Join(errs...)
+		}, true
+	case "airports":
+		return m.EnsureAirportData, true
+	case "runways":
+		return m.EnsureRunwayData, true
+	case "navaids":
+		return m.EnsureNavaidData, true
+	case "aircraft-db":
+		return m.EnsureAircraftDB, true
+	case "plane-alert":
+		return m.EnsurePlaneAlert, true
+	}
+	return nil, false
+}
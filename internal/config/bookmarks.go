@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// BookmarksTable prefixes the tables holding bookmarked map views, e.g.
+// [bookmarks.home]
+const BookmarksTable = "bookmarks"
+
+// Bookmark is a named map center with an optional zoom
+type Bookmark struct {
+	Name   string
+	Lat    float64
+	Lon    float64
+	Radius float64 // Map radius in miles, 0 to keep the current one
+}
+
+// Bookmarks returns the bookmarks in the order they appear in the file
+// Entries without both lat and lon are skipped.
+func (c *Config) Bookmarks() []Bookmark {
+	var bookmarks []Bookmark
+	for _, table := range c.doc.Tables() {
+		key, ok := strings.CutPrefix(table, BookmarksTable+".")
+		if !ok {
+			continue
+		}
+		if _, ok := c.doc.Get(table, "lat"); !ok {
+			continue
+		}
+		if _, ok := c.doc.Get(table, "lon"); !ok {
+			continue
+		}
+
+		bookmarks = append(bookmarks, Bookmark{
+			Name:   c.doc.String(table, "name", unquoteKey(key)),
+			Lat:    c.doc.Float(table, "lat", 0),
+			Lon:    c.doc.Float(table, "lon", 0),
+			Radius: c.doc.Float(table, "radius", 0),
+		})
+	}
+	return bookmarks
+}
+
+// isBookmarkTable reports whether a table holds a bookmark
+func isBookmarkTable(table string) bool {
+	return strings.HasPrefix(table, BookmarksTable+".")
+}
+
+// AppendBookmark adds a bookmark to the end of a config file, creating the
+// file if needed. The rest of the file, comments included, is untouched.
+func AppendBookmark(path string, bookmark Bookmark) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	section := fmt.Sprintf("\n[%s.%s]\nname = %s\nlat = %s\nlon = %s\nradius = %s\n",
+		BookmarksTable, strconv.Quote(bookmark.Name), strconv.Quote(bookmark.Name),
+		strconv.FormatFloat(bookmark.Lat, 'f', 5, 64),
+		strconv.FormatFloat(bookmark.Lon, 'f', 5, 64),
+		strconv.FormatFloat(bookmark.Radius, 'f', -1, 64))
+	if _, err := file.WriteString(section); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,241 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FileName is the config file looked for in the ascii1090 home directory
+const FileName = "config.toml"
+
+// Config is a loaded config.toml. Settings stand in for command line
+// flags; flags given on the command line win.
+type Config struct {
+	Path string
+	doc  *Document
+}
+
+// settings maps config file keys, as "table.key" with top-level keys
+// bare, to the command line flags they stand in for
+var settings = map[string]string{
+	"network":          "network",
+	"local":            "local",
+	"source_priority":  "source-priority",
+	"merge":            "merge",
+	"feed_timeout":     "feed-timeout",
+	"cache":            "cache",
+	"proxy":            "proxy",
+	"ca_bundle":        "ca-bundle",
+	"mirrors":          "mirror",
+	"airports_max_age": "airports-max-age",
+	"map_max_age":      "map-max-age",
+	"cache_limit":      "cache-limit",
+	"feature_memory":   "feature-memory",
+	"debug_log":        "d",
+	"debug_scope":      "dscope",
+	"log_level":        "log-level",
+	"log_json":         "log-json",
+	"log_max_size":     "log-max-size",
+	"log_keep":         "log-keep",
+	"pprof":            "pprof",
+	"radius":           "r",
+	"zoom_step":        "zoom-step",
+	"aspect":           "a",
+	"highway_detail":   "H",
+	"overlay":          "overlay",
+	"global_roads":     "global-roads",
+	"detail":           "detail",
+	"mode":             "mode",
+	"colors":           "colors",
+	"ascii":            "ascii",
+	"mono":             "mono",
+	"symbols":          "symbols",
+	"watchlist":        "watchlist",
+	"theme":            "theme",
+	"units":            "units",
+	"coords":           "coords",
+	"labels":           "labels",
+	"metar":            "metar",
+	"routes":           "routes",
+	"aircraft_db":      "aircraft-db",
+	"plane_alert":      "plane-alert",
+	"waypoints":        "waypoints",
+	"local_time":       "local-time",
+	"confirm_quit":     "confirm-quit",
+	"summary_json":     "summary-json",
+	"stats":            "stats",
+	"coverage":         "coverage",
+
+	"download_aircraft_db": "download-aircraft-db",
+	"download_plane_alert": "download-plane-alert",
+
+	"dump1090.binary":   "dump1090",
+	"dump1090.args":     "dump1090-args",
+	"dump1090.sbs_port": "sbs-port",
+
+	"alerts.beep":      "beep",
+	"alerts.sound_cmd": "sound-cmd",
+
+	"alerts.proximity":          "proximity",
+	"alerts.proximity_altitude": "proximity-alt",
+
+	"receiver.lat": "lat",
+	"receiver.lon": "lon",
+
+	"layers.show": "show",
+	"layers.hide": "hide",
+
+	"filters.positions_only": "positions-only",
+}
+
+// KeysTable is the table remapping keys to actions
+const KeysTable = "keys"
+
+// DefaultPath returns ~/.ascii1090/config.toml
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".ascii1090", FileName), nil
+}
+
+// Load reads a config file
+func Load(path string) (*Config, error) {
+	doc, err := ParseFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Config{Path: path, doc: doc}, nil
+}
+
+// ApplyFlags sets every flag the config has a value for, unless it was
+// already given on the command line. It returns warnings for keys it
+// doesn't recognize and an error for values the flag rejects.
+func (c *Config) ApplyFlags(fs *flag.FlagSet) (warnings []string, err error) {
+	given := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+
+	for _, table := range c.doc.Tables() {
+		if table == KeysTable || isBookmarkTable(table) {
+			continue
+		}
+
+		values := c.doc.Table(table)
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			name := key
+			if table != "" {
+				name = table + "." + key
+			}
+
+			flagName, ok := settings[name]
+			if !ok {
+				warnings = append(warnings, fmt.Sprintf("%s: unknown setting %q", c.Path, name))
+				continue
+			}
+			if given[flagName] {
+				continue
+			}
+
+			if err := fs.Set(flagName, formatValue(values[key])); err != nil {
+				return warnings, fmt.Errorf("%s: %s: %w", c.Path, name, err)
+			}
+		}
+	}
+
+	return warnings, nil
+}
+
+// KeyBindings returns the [keys] table as action name to key
+func (c *Config) KeyBindings() map[string]string {
+	bindings := make(map[string]string)
+	for action, value := range c.doc.Table(KeysTable) {
+		bindings[action] = formatValue(value)
+	}
+	return bindings
+}
+
+// SaveSetting sets a top-level key in a config file to value, written as
+// TOML, creating the file if needed. An existing line for the key is
+// replaced in place; otherwise the key goes before the first table. The
+// rest of the file, comments included, is untouched.
+func SaveSetting(path, key, value string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	setting := key + " = " + value
+	var lines []string
+	if len(data) > 0 {
+		lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+	insert := len(lines)
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(stripComment(line))
+		if strings.HasPrefix(trimmed, "[") {
+			insert = i
+			break
+		}
+		if name, _, ok := strings.Cut(trimmed, "="); ok && unquoteKey(strings.TrimSpace(name)) == key {
+			lines[i] = setting
+			insert = -1
+			break
+		}
+	}
+	if insert >= 0 {
+		// Keep the blank line that separates the top-level keys from the
+		// first table
+		for insert > 0 && strings.TrimSpace(lines[insert-1]) == "" {
+			insert--
+		}
+		added := []string{setting}
+		if insert < len(lines) && strings.TrimSpace(lines[insert]) != "" {
+			added = append(added, "")
+		}
+		lines = append(lines[:insert], append(added, lines[insert:]...)...)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// formatValue renders a TOML value the way the matching flag expects it;
+// arrays become comma-separated lists
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = formatValue(item)
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(value)
+}
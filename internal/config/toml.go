@@ -0,0 +1,267 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Document is a parsed TOML file. Only the subset ascii1090 needs is
+// supported: [table] headers, key = value pairs, strings, integers, floats,
+// booleans, single-line arrays and # comments. Top-level keys live in the
+// table named "".
+type Document struct {
+	tables map[string]map[string]any
+	order  []string
+}
+
+// NewDocument creates an empty document
+func NewDocument() *Document {
+	return &Document{tables: map[string]map[string]any{"": {}}, order: []string{""}}
+}
+
+// ParseFile reads and parses a TOML file
+func ParseFile(path string) (*Document, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	doc, err := Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return doc, nil
+}
+
+// Parse parses a TOML document
+func Parse(r io.Reader) (*Document, error) {
+	doc := NewDocument()
+	table := ""
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(stripComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+				return nil, fmt.Errorf("line %d: invalid table header %q", lineNum, line)
+			}
+			table = strings.TrimSpace(line[1 : len(line)-1])
+			if table == "" {
+				return nil, fmt.Errorf("line %d: empty table name", lineNum)
+			}
+			doc.ensureTable(table)
+			continue
+		}
+
+		eq := strings.Index(line, "=")
+		if eq < 0 {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNum)
+		}
+
+		key := unquoteKey(strings.TrimSpace(line[:eq]))
+		if key == "" {
+			return nil, fmt.Errorf("line %d: missing key", lineNum)
+		}
+
+		value, err := parseValue(strings.TrimSpace(line[eq+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+
+		doc.Set(table, key, value)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// Tables returns table names in the order they first appeared
+func (d *Document) Tables() []string {
+	return append([]string(nil), d.order...)
+}
+
+// Table returns the key/value pairs of a table, or nil if it doesn't exist
+func (d *Document) Table(name string) map[string]any {
+	return d.tables[name]
+}
+
+// Get returns a raw value
+func (d *Document) Get(table, key string) (any, bool) {
+	value, ok := d.tables[table][key]
+	return value, ok
+}
+
+// Set stores a value, creating the table if needed
+func (d *Document) Set(table, key string, value any) {
+	d.ensureTable(table)[key] = value
+}
+
+// String returns a string value, or def if missing or not a string
+func (d *Document) String(table, key, def string) string {
+	if value, ok := d.tables[table][key].(string); ok {
+		return value
+	}
+	return def
+}
+
+// Int returns an integer value, or def if missing or not a number
+func (d *Document) Int(table, key string, def int) int {
+	switch value := d.tables[table][key].(type) {
+	case int64:
+		return int(value)
+	case float64:
+		return int(value)
+	}
+	return def
+}
+
+// Float returns a numeric value, or def if missing or not a number
+func (d *Document) Float(table, key string, def float64) float64 {
+	switch value := d.tables[table][key].(type) {
+	case int64:
+		return float64(value)
+	case float64:
+		return value
+	}
+	return def
+}
+
+// Bool returns a boolean value, or def if missing or not a boolean
+func (d *Document) Bool(table, key string, def bool) bool {
+	if value, ok := d.tables[table][key].(bool); ok {
+		return value
+	}
+	return def
+}
+
+// ensureTable returns a table, creating it if needed
+func (d *Document) ensureTable(name string) map[string]any {
+	table, ok := d.tables[name]
+	if !ok {
+		table = make(map[string]any)
+		d.tables[name] = table
+		d.order = append(d.order, name)
+	}
+	return table
+}
+
+// stripComment removes a trailing # comment that isn't inside a string
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// unquoteKey strips quotes from a quoted key
+func unquoteKey(key string) string {
+	if len(key) >= 2 && (key[0] == '"' || key[0] == '\'') && key[len(key)-1] == key[0] {
+		return key[1 : len(key)-1]
+	}
+	return key
+}
+
+// parseValue parses a single TOML value
+func parseValue(raw string) (any, error) {
+	switch {
+	case raw == "":
+		return nil, fmt.Errorf("missing value")
+
+	case raw == "true":
+		return true, nil
+
+	case raw == "false":
+		return false, nil
+
+	case raw[0] == '"':
+		value, err := strconv.Unquote(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid string %s", raw)
+		}
+		return value, nil
+
+	case raw[0] == '\'':
+		if len(raw) < 2 || raw[len(raw)-1] != '\'' {
+			return nil, fmt.Errorf("invalid string %s", raw)
+		}
+		return raw[1 : len(raw)-1], nil
+
+	case raw[0] == '[':
+		if raw[len(raw)-1] != ']' {
+			return nil, fmt.Errorf("arrays must be on one line")
+		}
+		var values []any
+		for _, item := range splitArray(raw[1 : len(raw)-1]) {
+			if item == "" {
+				continue
+			}
+			value, err := parseValue(item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	}
+
+	number := strings.ReplaceAll(raw, "_", "")
+	if i, err := strconv.ParseInt(number, 10, 64); err == nil {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(number, 64); err == nil {
+		return f, nil
+	}
+
+	return nil, fmt.Errorf("invalid value %q", raw)
+}
+
+// splitArray splits array contents on commas outside of strings
+func splitArray(s string) []string {
+	var items []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			items = append(items, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(items, strings.TrimSpace(s[start:]))
+}
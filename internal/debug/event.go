@@ -0,0 +1,93 @@
+package debug
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Fields are the named values attached to an event
+type Fields map[string]interface{}
+
+// record is one line of the JSON log; printf-style messages fill Message,
+// events fill Event, ICAO and Fields
+type record struct {
+	Time      string `json:"time"`
+	Level     string `json:"level"`
+	Component string `json:"component"`
+	Event     string `json:"event,omitempty"`
+	ICAO      string `json:"icao,omitempty"`
+	Message   string `json:"msg,omitempty"`
+	Fields    Fields `json:"fields,omitempty"`
+}
+
+var jsonOutput bool // Write JSON lines instead of text
+
+// SetJSON switches the log between text lines and one JSON object per line
+// for querying with jq
+func SetJSON(enabled bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	jsonOutput = enabled
+}
+
+// Event logs a named event, e.g. "callsign_changed", about the aircraft
+// icao, or about no aircraft when icao is empty. In text logs the fields
+// follow the event name as key=value pairs.
+func (s *Scope) Event(l Level, event, icao string, fields Fields) {
+	mu.Lock()
+	defer mu.Unlock()
+	if !enabledFor(s.name, l) {
+		return
+	}
+	write(record{
+		Level:     l.String(),
+		Component: s.name,
+		Event:     event,
+		ICAO:      icao,
+		Fields:    fields,
+	})
+}
+
+// enabledFor reports whether a message at l for scope is written; the
+// caller holds mu
+func enabledFor(scope string, l Level) bool {
+	return writer != io.Discard && l >= level && scopeEnabled(scope)
+}
+
+// write timestamps and writes one log line; the caller holds mu
+func write(r record) {
+	r.Time = time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
+	if jsonOutput {
+		line, err := json.Marshal(r)
+		if err != nil {
+			// Fields that can't be encoded are written as text instead
+			r.Fields = Fields{"error": err.Error(), "fields": fmt.Sprint(r.Fields)}
+			line, _ = json.Marshal(r)
+		}
+		writer.Write(append(line, '\n'))
+		return
+	}
+
+	text := r.Message
+	if r.Event != "" {
+		var b strings.Builder
+		b.WriteString(r.Event)
+		if r.ICAO != "" {
+			fmt.Fprintf(&b, " icao=%s", r.ICAO)
+		}
+		keys := make([]string, 0, len(r.Fields))
+		for key := range r.Fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(&b, " %s=%v", key, r.Fields[key])
+		}
+		text = b.String()
+	}
+	fmt.Fprintf(writer, "%s %-5s [%s] %s\n", r.Time, r.Level, r.Component, text)
+}
@@ -3,21 +3,85 @@ package debug
 import (
 	"fmt"
 	"io"
+	"strings"
+	"sync"
 )
 
-var writer io.Writer = io.Discard
+// Level is the severity of a log message; messages below the configured
+// level are dropped
+type Level int
+
+const (
+	LevelTrace Level = iota // Per-frame and per-message detail
+	LevelInfo               // State changes: zoom, layers, follow, fetches
+	LevelWarn               // Recoverable problems: failed fetches, restarts
+	LevelError              // Failures the user should know about
+)
+
+// String returns the level name as written in the log
+func (l Level) String() string {
+	switch l {
+	case LevelTrace:
+		return "TRACE"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	default:
+		return "ERROR"
+	}
+}
+
+// ParseLevel parses "error", "warn", "info" or "trace"
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "error":
+		return LevelError, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "info", "":
+		return LevelInfo, nil
+	case "trace", "debug":
+		return LevelTrace, nil
+	default:
+		return LevelInfo, fmt.Errorf("unknown log level %q (use error, warn, info or trace)", s)
+	}
+}
+
+var (
+	mu     sync.Mutex
+	writer io.Writer = io.Discard
+	level            = LevelInfo
+)
 
 // SetOutput sets the debug output destination
 func SetOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
 	writer = w
 }
 
-// Log writes a debug message
-func Log(format string, args ...interface{}) {
-	fmt.Fprintf(writer, format+"\n", args...)
+// SetLevel sets the lowest level that is written
+func SetLevel(l Level) {
+	mu.Lock()
+	defer mu.Unlock()
+	level = l
+}
+
+// logf writes one message for scope if l is at or above the level and the
+// scope is enabled
+func logf(scope string, l Level, format string, args ...interface{}) {
+	mu.Lock()
+	defer mu.Unlock()
+	if !enabledFor(scope, l) {
+		return
+	}
+	write(record{Level: l.String(), Component: scope, Message: fmt.Sprintf(format, args...)})
 }
 
 // Enabled returns true if debug logging is enabled
 func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
 	return writer != io.Discard
 }
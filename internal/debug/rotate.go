@@ -0,0 +1,82 @@
+package debug
+
+import (
+	"fmt"
+	"os"
+)
+
+// RotatingFile is a log file that is renamed to path.1 (path.1 to path.2,
+// and so on) once it grows past a size, keeping a fixed number of old files
+// so a long-running session can't fill the disk
+type RotatingFile struct {
+	path    string
+	maxSize int64 // Bytes before rotating, 0 never rotates
+	keep    int   // Old files kept beside the current one
+	file    *os.File
+	size    int64
+}
+
+// OpenRotatingFile starts a fresh log at path, rotating the previous run's
+// log out of the way first
+func OpenRotatingFile(path string, maxSize int64, keep int) (*RotatingFile, error) {
+	r := &RotatingFile{path: path, maxSize: maxSize, keep: keep}
+	if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+		r.shift()
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Write appends p, rotating first if it would take the file past maxSize
+// Callers serialize writes; the package logger holds its lock.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close closes the current file
+func (r *RotatingFile) Close() error {
+	return r.file.Close()
+}
+
+// rotate closes the current file, shifts the old ones and starts a new one
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	r.shift()
+	return r.open()
+}
+
+// shift renames path.N-1 to path.N down to path to path.1, dropping the
+// oldest; with keep 0 the current file is simply removed
+func (r *RotatingFile) shift() {
+	if r.keep <= 0 {
+		os.Remove(r.path)
+		return
+	}
+	os.Remove(fmt.Sprintf("%s.%d", r.path, r.keep))
+	for i := r.keep - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+	}
+	os.Rename(r.path, r.path+".1")
+}
+
+// open creates a new, empty current file
+func (r *RotatingFile) open() error {
+	file, err := os.Create(r.path)
+	if err != nil {
+		return fmt.Errorf("failed to create log file: %w", err)
+	}
+	r.file = file
+	r.size = 0
+	return nil
+}
@@ -0,0 +1,98 @@
+package debug
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Scope logs for one subsystem; each package keeps one, and -dscope picks
+// which of them are written
+type Scope struct {
+	name string
+}
+
+var (
+	scopes  = make(map[string]*Scope) // Every scope created, by name
+	enabled map[string]bool           // Scopes written, nil for all
+)
+
+// NewScope returns the logger for a subsystem, e.g. "adsb" or "render"
+func NewScope(name string) *Scope {
+	mu.Lock()
+	defer mu.Unlock()
+	if scope, ok := scopes[name]; ok {
+		return scope
+	}
+	scope := &Scope{name: name}
+	scopes[name] = scope
+	return scope
+}
+
+// Scopes returns the names of all subsystems that log, sorted
+func Scopes() []string {
+	mu.Lock()
+	defer mu.Unlock()
+	names := make([]string, 0, len(scopes))
+	for name := range scopes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetScopes limits logging to the named subsystems; an empty list logs
+// them all
+func SetScopes(names []string) error {
+	known := Scopes()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(names) == 0 {
+		enabled = nil
+		return nil
+	}
+	enabled = make(map[string]bool)
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := scopes[name]; !ok {
+			enabled = nil
+			return fmt.Errorf("unknown debug scope %q (use %s)", name, strings.Join(known, ", "))
+		}
+		enabled[name] = true
+	}
+	return nil
+}
+
+// scopeEnabled reports whether a scope is written; the caller holds mu
+func scopeEnabled(name string) bool {
+	return enabled == nil || enabled[name]
+}
+
+// Enabled reports whether messages at l for this scope are written, so hot
+// paths can skip building Fields that would be thrown away
+func (s *Scope) Enabled(l Level) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return enabledFor(s.name, l)
+}
+
+// Errorf logs a failure
+func (s *Scope) Errorf(format string, args ...interface{}) {
+	logf(s.name, LevelError, format, args...)
+}
+
+// Warnf logs a recoverable problem
+func (s *Scope) Warnf(format string, args ...interface{}) {
+	logf(s.name, LevelWarn, format, args...)
+}
+
+// Infof logs a state change
+func (s *Scope) Infof(format string, args ...interface{}) {
+	logf(s.name, LevelInfo, format, args...)
+}
+
+// Tracef logs fine-grained detail
+func (s *Scope) Tracef(format string, args ...interface{}) {
+	logf(s.name, LevelTrace, format, args...)
+}
@@ -72,7 +72,7 @@ func (a *AirportLoader) LoadAirports() ([]*Feature, error) {
 
 		lat, err := strconv.ParseFloat(latStr, 64)
 		if err != nil {
-			continue 
+			continue
 		}
 
 		lon, err := strconv.ParseFloat(lonStr, 64)
@@ -96,6 +96,16 @@ func (a *AirportLoader) LoadAirports() ([]*Feature, error) {
 		airport := NewPointFeature(FeatureAirport, LatLon{Lat: lat, Lon: lon}, label)
 		airport.Properties["full_name"] = name
 		airport.Properties["type"] = airportType
+		airport.Properties["ident"] = ident
+		airport.Properties["iata"] = iataCode
+		if i, ok := colIndices["municipality"]; ok && record[i] != "" {
+			airport.Properties["municipality"] = record[i]
+		}
+		if i, ok := colIndices["elevation_ft"]; ok {
+			if elevation, err := strconv.ParseFloat(record[i], 64); err == nil {
+				airport.Properties["elevation_ft"] = elevation
+			}
+		}
 
 		airports = append(airports, airport)
 	}
@@ -0,0 +1,130 @@
+package geo
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// CoordFormat selects how coordinates are displayed
+type CoordFormat int
+
+const (
+	CoordDecimal CoordFormat = iota // 32.8975°N
+	CoordDDM                        // 32°53.85'N
+	CoordDMS                        // 32°53'51"N
+)
+
+// String returns the flag/config spelling of the format
+func (c CoordFormat) String() string {
+	switch c {
+	case CoordDDM:
+		return "ddm"
+	case CoordDMS:
+		return "dms"
+	default:
+		return "decimal"
+	}
+}
+
+// ParseCoordFormat parses "decimal", "ddm" or "dms"
+func ParseCoordFormat(s string) (CoordFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "decimal", "dd", "":
+		return CoordDecimal, nil
+	case "ddm", "dm":
+		return CoordDDM, nil
+	case "dms":
+		return CoordDMS, nil
+	default:
+		return CoordDecimal, fmt.Errorf("unknown coordinate format %q (use decimal, ddm or dms)", s)
+	}
+}
+
+// FormatLatLon formats a coordinate pair, e.g. "32.8975*N, 97.0403*W"
+func FormatLatLon(lat, lon float64, format CoordFormat) string {
+	return FormatCoord(lat, "N", "S", format) + ", " + FormatCoord(lon, "E", "W", format)
+}
+
+// FormatCoord formats a single latitude or longitude with a hemisphere letter
+// Degrees are marked with * like the rest of the UI for terminals without
+// a degree glyph
+func FormatCoord(value float64, positive, negative string, format CoordFormat) string {
+	hemisphere := positive
+	if value < 0 {
+		hemisphere = negative
+		value = -value
+	}
+
+	switch format {
+	case CoordDDM:
+		// Round first so 59.995 minutes carries into the degrees
+		totalMinutes := math.Round(value*60*100) / 100
+		degrees := math.Floor(totalMinutes / 60)
+		minutes := totalMinutes - degrees*60
+		return fmt.Sprintf("%.0f*%05.2f'%s", degrees, minutes, hemisphere)
+
+	case CoordDMS:
+		totalSeconds := math.Round(value * 3600)
+		degrees := math.Floor(totalSeconds / 3600)
+		minutes := math.Floor((totalSeconds - degrees*3600) / 60)
+		seconds := totalSeconds - degrees*3600 - minutes*60
+		return fmt.Sprintf("%.0f*%02.0f'%02.0f\"%s", degrees, minutes, seconds, hemisphere)
+
+	default:
+		return fmt.Sprintf("%.4f*%s", value, hemisphere)
+	}
+}
+
+// ParseLatLon parses a coordinate pair typed by the user, such as
+// "32.9, -97.0", "32.9 -97.0" or "32.9N 97.0W"
+// Degree marks (° or *) are ignored; a hemisphere letter flips S and W
+// values negative.
+func ParseLatLon(s string) (LatLon, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	if len(fields) != 2 {
+		return LatLon{}, fmt.Errorf("expected latitude and longitude, got %q", s)
+	}
+
+	lat, err := parseDegrees(fields[0], "N", "S")
+	if err != nil {
+		return LatLon{}, err
+	}
+	lon, err := parseDegrees(fields[1], "E", "W")
+	if err != nil {
+		return LatLon{}, err
+	}
+
+	if lat < -90 || lat > 90 {
+		return LatLon{}, fmt.Errorf("latitude %g out of range", lat)
+	}
+	if lon < -180 || lon > 180 {
+		return LatLon{}, fmt.Errorf("longitude %g out of range", lon)
+	}
+	return LatLon{Lat: lat, Lon: lon}, nil
+}
+
+// parseDegrees parses decimal degrees with an optional hemisphere letter
+func parseDegrees(s, positive, negative string) (float64, error) {
+	s = strings.ToUpper(strings.NewReplacer("°", "", "*", "").Replace(s))
+
+	sign := 1.0
+	for _, letter := range []string{positive, negative} {
+		if strings.HasSuffix(s, letter) || strings.HasPrefix(s, letter) {
+			s = strings.TrimSuffix(strings.TrimPrefix(s, letter), letter)
+			if letter == negative {
+				sign = -1
+			}
+			break
+		}
+	}
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid coordinate %q", s)
+	}
+	return sign * value, nil
+}
@@ -0,0 +1,157 @@
+package geo
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// CoverageCellDegrees is the size of a coverage grid cell, about 3 nm of
+// latitude
+const CoverageCellDegrees = 0.05
+
+// coverageKey indexes a coverage cell by its south-west corner in cell
+// units
+type coverageKey struct {
+	lat, lon int
+}
+
+// CoverageCell is the positions received in one cell of the coverage grid
+type CoverageCell struct {
+	Lat, Lon    float64 // Center of the cell
+	Positions   int     // Positions received in the cell
+	MinAltitude int     // Lowest altitude received in feet, 0 if none was reported
+}
+
+// CoverageGrid counts received aircraft positions on a fixed latitude and
+// longitude grid, mapping where the receiver actually hears aircraft
+type CoverageGrid struct {
+	cells map[coverageKey]*CoverageCell
+	max   int
+}
+
+// NewCoverageGrid creates an empty coverage grid
+func NewCoverageGrid() *CoverageGrid {
+	return &CoverageGrid{cells: make(map[coverageKey]*CoverageCell)}
+}
+
+// coverageKeyOf returns the grid cell a position falls in
+func coverageKeyOf(lat, lon float64) coverageKey {
+	return coverageKey{int(math.Floor(lat / CoverageCellDegrees)), int(math.Floor(lon / CoverageCellDegrees))}
+}
+
+// Add counts a position received at altitude feet, 0 if unknown. Low
+// minimum altitudes show where the antenna sees down toward the horizon.
+func (g *CoverageGrid) Add(lat, lon float64, altitude int) {
+	key := coverageKeyOf(lat, lon)
+	cell, ok := g.cells[key]
+	if !ok {
+		cell = &CoverageCell{
+			Lat: (float64(key.lat) + 0.5) * CoverageCellDegrees,
+			Lon: (float64(key.lon) + 0.5) * CoverageCellDegrees,
+		}
+		g.cells[key] = cell
+	}
+	cell.Positions++
+	if altitude > 0 && (cell.MinAltitude == 0 || altitude < cell.MinAltitude) {
+		cell.MinAltitude = altitude
+	}
+	g.max = max(g.max, cell.Positions)
+}
+
+// Positions returns how many positions were received in the cell holding
+// a point
+func (g *CoverageGrid) Positions(lat, lon float64) int {
+	if cell, ok := g.cells[coverageKeyOf(lat, lon)]; ok {
+		return cell.Positions
+	}
+	return 0
+}
+
+// Max returns the most positions received in any one cell
+func (g *CoverageGrid) Max() int {
+	return g.max
+}
+
+// Len returns the number of cells positions were received in
+func (g *CoverageGrid) Len() int {
+	return len(g.cells)
+}
+
+// Cells returns every cell with positions, south to north then west to east
+func (g *CoverageGrid) Cells() []CoverageCell {
+	cells := make([]CoverageCell, 0, len(g.cells))
+	for _, cell := range g.cells {
+		cells = append(cells, *cell)
+	}
+	sort.Slice(cells, func(i, j int) bool {
+		if cells[i].Lat != cells[j].Lat {
+			return cells[i].Lat < cells[j].Lat
+		}
+		return cells[i].Lon < cells[j].Lon
+	})
+	return cells
+}
+
+// WriteCSV writes one lat,lon,positions,min_altitude_ft line per cell,
+// giving each cell's center
+func (g *CoverageGrid) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"lat", "lon", "positions", "min_altitude_ft"})
+	for _, cell := range g.Cells() {
+		writer.Write([]string{
+			strconv.FormatFloat(cell.Lat, 'f', 3, 64),
+			strconv.FormatFloat(cell.Lon, 'f', 3, 64),
+			strconv.Itoa(cell.Positions),
+			strconv.Itoa(cell.MinAltitude),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteGeoJSON writes the grid as a FeatureCollection of square cell
+// polygons with positions and min_altitude_ft properties, for styling by
+// density in QGIS or geojson.io
+func (g *CoverageGrid) WriteGeoJSON(w io.Writer) error {
+	type geometry struct {
+		Type        string         `json:"type"`
+		Coordinates [][][2]float64 `json:"coordinates"`
+	}
+	type feature struct {
+		Type       string         `json:"type"`
+		Geometry   geometry       `json:"geometry"`
+		Properties map[string]int `json:"properties"`
+	}
+
+	half := CoverageCellDegrees / 2
+	round := func(v float64) float64 {
+		return math.Round(v*1e4) / 1e4
+	}
+	features := make([]feature, 0, len(g.cells))
+	for _, cell := range g.Cells() {
+		south, north := round(cell.Lat-half), round(cell.Lat+half)
+		west, east := round(cell.Lon-half), round(cell.Lon+half)
+		features = append(features, feature{
+			Type: "Feature",
+			Geometry: geometry{
+				Type:        "Polygon",
+				Coordinates: [][][2]float64{{{west, south}, {east, south}, {east, north}, {west, north}, {west, south}}},
+			},
+			Properties: map[string]int{"positions": cell.Positions, "min_altitude_ft": cell.MinAltitude},
+		})
+	}
+
+	collection := struct {
+		Type     string    `json:"type"`
+		Features []feature `json:"features"`
+	}{"FeatureCollection", features}
+	if err := json.NewEncoder(w).Encode(collection); err != nil {
+		return fmt.Errorf("failed to encode coverage: %w", err)
+	}
+	return nil
+}
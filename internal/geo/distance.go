@@ -0,0 +1,99 @@
+package geo
+
+import "math"
+
+// EarthRadiusMiles is the mean radius of the Earth in statute miles
+const EarthRadiusMiles = 3958.8
+
+// Destination returns the point reached by travelling distanceMiles from
+// (lat, lon) along the given true bearing, on a spherical Earth
+func Destination(lat, lon, bearingDeg, distanceMiles float64) LatLon {
+	phi1 := lat * math.Pi / 180
+	lambda1 := lon * math.Pi / 180
+	theta := bearingDeg * math.Pi / 180
+	delta := distanceMiles / EarthRadiusMiles
+
+	phi2 := math.Asin(math.Sin(phi1)*math.Cos(delta) + math.Cos(phi1)*math.Sin(delta)*math.Cos(theta))
+	lambda2 := lambda1 + math.Atan2(math.Sin(theta)*math.Sin(delta)*math.Cos(phi1),
+		math.Cos(delta)-math.Sin(phi1)*math.Sin(phi2))
+
+	return LatLon{
+		Lat: phi2 * 180 / math.Pi,
+		Lon: NormalizeLon(lambda2 * 180 / math.Pi),
+	}
+}
+
+// GreatCircle returns points along the great-circle path from a to b,
+// including both endpoints. segments controls how many straight pieces the
+// path is split into.
+func GreatCircle(a, b LatLon, segments int) []LatLon {
+	if segments < 1 {
+		segments = 1
+	}
+
+	toVec := func(p LatLon) [3]float64 {
+		phi := p.Lat * math.Pi / 180
+		lambda := p.Lon * math.Pi / 180
+		return [3]float64{math.Cos(phi) * math.Cos(lambda), math.Cos(phi) * math.Sin(lambda), math.Sin(phi)}
+	}
+
+	va, vb := toVec(a), toVec(b)
+	dot := va[0]*vb[0] + va[1]*vb[1] + va[2]*vb[2]
+	omega := math.Acos(math.Max(-1, math.Min(1, dot)))
+	if omega < 1e-9 {
+		return []LatLon{a, b}
+	}
+
+	points := make([]LatLon, 0, segments+1)
+	for i := 0; i <= segments; i++ {
+		t := float64(i) / float64(segments)
+		// Spherical linear interpolation between the two unit vectors
+		wa := math.Sin((1-t)*omega) / math.Sin(omega)
+		wb := math.Sin(t*omega) / math.Sin(omega)
+		x := wa*va[0] + wb*vb[0]
+		y := wa*va[1] + wb*vb[1]
+		z := wa*va[2] + wb*vb[2]
+
+		points = append(points, LatLon{
+			Lat: math.Atan2(z, math.Hypot(x, y)) * 180 / math.Pi,
+			Lon: math.Atan2(y, x) * 180 / math.Pi,
+		})
+	}
+
+	return points
+}
+
+// Distance returns the great-circle distance between two points in
+// statute miles
+func Distance(a, b LatLon) float64 {
+	phi1 := a.Lat * math.Pi / 180
+	phi2 := b.Lat * math.Pi / 180
+	dPhi := phi2 - phi1
+	dLambda := (b.Lon - a.Lon) * math.Pi / 180
+
+	h := math.Sin(dPhi/2)*math.Sin(dPhi/2) + math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * EarthRadiusMiles * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// NauticalMilesPerMile converts statute miles to nautical miles
+const NauticalMilesPerMile = 0.868976
+
+// Bearing returns the initial true bearing from a to b in degrees (0-359)
+func Bearing(a, b LatLon) float64 {
+	phi1 := a.Lat * math.Pi / 180
+	phi2 := b.Lat * math.Pi / 180
+	dLambda := (b.Lon - a.Lon) * math.Pi / 180
+
+	y := math.Sin(dLambda) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(dLambda)
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
+
+// compassPoints are the 8 compass directions, N first
+var compassPoints = [8]string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}
+
+// CompassPoint names a true bearing as one of the 8 compass directions,
+// e.g. 50 → "NE"
+func CompassPoint(bearing float64) string {
+	return compassPoints[int(math.Mod(bearing+22.5+360, 360)/45)%8]
+}
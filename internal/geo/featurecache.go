@@ -0,0 +1,155 @@
+package geo
+
+import (
+	"ascii1090/internal/debug"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// log is the geo package's debug scope
+var log = debug.NewScope("geo")
+
+// FeatureCacheFile is the pre-parsed feature cache in the data directory
+const FeatureCacheFile = "features.gob"
+
+// featureCacheVersion is bumped whenever the loaders change what they
+// produce, so caches written by older builds are rebuilt
+const featureCacheVersion = 5
+
+// featureCache is what FeatureCacheFile holds: the features LoadAll
+// returned other than the tiled line layers, an index of the tiles in
+// FeatureTilesDir holding those, how many of each type there were, and
+// the key of the inputs they were parsed from
+type featureCache struct {
+	Key      string
+	Features map[FeatureType][]*Feature
+	Tiles    []featureTile
+	Counts   map[FeatureType]int
+}
+
+// LoadCached returns the same features as LoadAll, read from the feature
+// cache when it was written from the same source files and settings.
+// Otherwise the shapefiles and CSVs are parsed and the cache is rewritten,
+// so later startups skip parsing. The big line layers are left in tiles
+// on disk for the map to load around where it is looking; when the cache
+// can't be written every feature is returned instead, with nil tiles.
+func (s *ShapefileLoader) LoadCached() (map[FeatureType][]*Feature, *FeatureTiles, error) {
+	key := s.cacheKey()
+	path := filepath.Join(s.dataDir, FeatureCacheFile)
+	tilesDir := filepath.Join(s.dataDir, FeatureTilesDir, key[:16])
+
+	if cache, err := readFeatureCache(path, key); err == nil {
+		log.Infof("Feature cache hit, key %s", key[:12])
+		fmt.Fprintf(s.out, "Loaded features from cache, %d map tiles\n", len(cache.Tiles))
+		s.printFeatureCounts(cache.Counts)
+		now := time.Now()
+		os.Chtimes(tilesDir, now, now) // Newest tiles are kept when pruning
+		return cache.Features, &FeatureTiles{dir: tilesDir, tiles: cache.Tiles}, nil
+	} else if !os.IsNotExist(err) {
+		fmt.Fprintf(s.out, "Rebuilding feature cache: %v\n", err)
+	}
+	log.Infof("Feature cache miss, parsing sources for key %s", key[:12])
+
+	features, err := s.LoadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	tiled, rest := splitTiled(features)
+	tiles, err := writeFeatureTiles(tilesDir, tiled)
+	if err == nil {
+		err = writeFeatureCache(path, featureCache{Key: key, Features: rest, Tiles: tiles, Counts: featureCounts(features)})
+	}
+	if err != nil {
+		fmt.Fprintf(s.out, "Warning: failed to write feature cache: %v\n", err)
+		return features, nil, nil
+	}
+	return rest, &FeatureTiles{dir: tilesDir, tiles: tiles}, nil
+}
+
+// cacheKey identifies the inputs LoadAll would read: the cache format, the
+// detail setting, and the name, size and modification time of every
+// source file, so a re-download or a changed flag invalidates the cache
+func (s *ShapefileLoader) cacheKey() string {
+	sources := []string{
+		s.naturalEarthPath("admin_1_states_provinces"),
+		s.naturalEarthPath("rivers_lake_centerlines"),
+		s.naturalEarthPath("coastline"),
+		s.dataDir + "/ne_10m_roads_north_america.shp",
+		s.dataDir + "/ne_10m_roads.shp",
+		s.dataDir + "/Class_Airspace.shp",
+		s.dataDir + "/ne_10m_time_zones.shp",
+		s.dataDir + "/ne_50m_populated_places.shp",
+		s.dataDir + "/airports.csv",
+		s.dataDir + "/runways.csv",
+		s.dataDir + "/navaids.csv",
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "v%d detail=%d\n", featureCacheVersion, s.detail)
+	for _, source := range sources {
+		info, err := os.Stat(source)
+		if err != nil {
+			fmt.Fprintf(hash, "%s missing\n", filepath.Base(source))
+			continue
+		}
+		fmt.Fprintf(hash, "%s %d %d\n", filepath.Base(source), info.Size(), info.ModTime().UnixNano())
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// readFeatureCache decodes the cache, failing if it was written for a
+// different key
+func readFeatureCache(path, key string) (*featureCache, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var cache featureCache
+	if err := gob.NewDecoder(file).Decode(&cache); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", FeatureCacheFile, err)
+	}
+	if cache.Key != key {
+		return nil, fmt.Errorf("source data or settings changed")
+	}
+
+	fillProperties(cache.Features)
+	return &cache, nil
+}
+
+// fillProperties restores the empty Properties maps gob drops, since the
+// renderer expects every feature to have them
+func fillProperties(features map[FeatureType][]*Feature) {
+	for _, list := range features {
+		for _, feature := range list {
+			if feature.Properties == nil {
+				feature.Properties = make(map[string]interface{})
+			}
+		}
+	}
+}
+
+// writeFeatureCache encodes the features to a temp file and renames it
+// into place, so an interrupted write never leaves a truncated cache
+func writeFeatureCache(path string, cache featureCache) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "features-*.gob")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := gob.NewEncoder(tmp).Encode(cache); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -10,6 +10,12 @@ const (
 	FeatureCoastline
 	FeatureCity
 	FeatureAirport
+	FeatureOverlay
+	FeatureWaypoint
+	FeatureAirspace
+	FeatureNavaid
+	FeatureRunway
+	FeatureTimeZone
 )
 
 // String returns a string representation of the feature type
@@ -27,6 +33,18 @@ func (f FeatureType) String() string {
 		return "City"
 	case FeatureAirport:
 		return "Airport"
+	case FeatureOverlay:
+		return "Overlay"
+	case FeatureWaypoint:
+		return "Waypoint"
+	case FeatureAirspace:
+		return "Airspace"
+	case FeatureNavaid:
+		return "Navaid"
+	case FeatureRunway:
+		return "Runway"
+	case FeatureTimeZone:
+		return "TimeZone"
 	default:
 		return "Unknown"
 	}
@@ -40,10 +58,10 @@ type LatLon struct {
 
 // Feature represents a geographic feature (line, polygon, or point)
 type Feature struct {
-	Type       FeatureType        // Type of feature
-	Points     []LatLon           // Polyline/polygon points (empty for point features)
-	Point      *LatLon            // Single point (for cities, airports)
-	Name       string             // Label for cities, airports, etc.
+	Type       FeatureType            // Type of feature
+	Points     []LatLon               // Polyline/polygon points (empty for point features)
+	Point      *LatLon                // Single point (for cities, airports)
+	Name       string                 // Label for cities, airports, etc.
 	Properties map[string]interface{} // Additional properties from shapefile
 }
 
@@ -0,0 +1,222 @@
+package geo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GeoJSONLoader loads user overlay features from GeoJSON files
+// Styling follows the simplestyle-spec properties (stroke, marker-color,
+// marker-symbol, title) so files exported from geojson.io work as-is
+type GeoJSONLoader struct {
+	path string
+}
+
+// NewGeoJSONLoader creates a new GeoJSON loader for a single file
+func NewGeoJSONLoader(path string) *GeoJSONLoader {
+	return &GeoJSONLoader{
+		path: path,
+	}
+}
+
+type geoJSONObject struct {
+	Type        string                 `json:"type"`
+	Features    []geoJSONObject        `json:"features"`
+	Geometry    *geoJSONObject         `json:"geometry"`
+	Geometries  []geoJSONObject        `json:"geometries"`
+	Coordinates json.RawMessage        `json:"coordinates"`
+	Properties  map[string]interface{} `json:"properties"`
+}
+
+// Load parses the file and returns overlay features
+// Points become point features, lines and polygon rings become line features
+func (g *GeoJSONLoader) Load() ([]*Feature, error) {
+	data, err := os.ReadFile(g.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overlay: %w", err)
+	}
+
+	var root geoJSONObject
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse GeoJSON: %w", err)
+	}
+
+	var features []*Feature
+	if err := g.collect(&root, nil, &features); err != nil {
+		return nil, err
+	}
+
+	source := filepath.Base(g.path)
+	for _, feature := range features {
+		feature.Properties["overlay"] = source
+	}
+
+	return features, nil
+}
+
+// collect walks a GeoJSON object and appends the features it contains
+func (g *GeoJSONLoader) collect(obj *geoJSONObject, props map[string]interface{}, out *[]*Feature) error {
+	switch obj.Type {
+	case "FeatureCollection":
+		for i := range obj.Features {
+			if err := g.collect(&obj.Features[i], nil, out); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case "Feature":
+		if obj.Geometry == nil {
+			return nil
+		}
+		return g.collect(obj.Geometry, obj.Properties, out)
+
+	case "GeometryCollection":
+		for i := range obj.Geometries {
+			if err := g.collect(&obj.Geometries[i], props, out); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	name := ""
+	for _, key := range []string{"title", "name", "Name", "NAME"} {
+		if v, ok := props[key].(string); ok && v != "" {
+			name = v
+			break
+		}
+	}
+
+	newProps := func() map[string]interface{} {
+		p := make(map[string]interface{}, len(props))
+		for k, v := range props {
+			p[k] = v
+		}
+		return p
+	}
+
+	addLine := func(coords [][]float64) {
+		points := toLatLons(coords)
+		if len(points) > 1 {
+			feature := NewLineFeature(FeatureOverlay, points)
+			feature.Name = name
+			feature.Properties = newProps()
+			*out = append(*out, feature)
+		}
+	}
+
+	addPoint := func(coord []float64) {
+		if len(coord) < 2 {
+			return
+		}
+		feature := NewPointFeature(FeatureOverlay, LatLon{Lat: coord[1], Lon: coord[0]}, name)
+		feature.Properties = newProps()
+		*out = append(*out, feature)
+	}
+
+	var err error
+	switch obj.Type {
+	case "Point":
+		var coord []float64
+		if err = json.Unmarshal(obj.Coordinates, &coord); err == nil {
+			addPoint(coord)
+		}
+
+	case "MultiPoint":
+		var coords [][]float64
+		if err = json.Unmarshal(obj.Coordinates, &coords); err == nil {
+			for _, coord := range coords {
+				addPoint(coord)
+			}
+		}
+
+	case "LineString":
+		var coords [][]float64
+		if err = json.Unmarshal(obj.Coordinates, &coords); err == nil {
+			addLine(coords)
+		}
+
+	case "MultiLineString", "Polygon":
+		var lines [][][]float64
+		if err = json.Unmarshal(obj.Coordinates, &lines); err == nil {
+			for _, coords := range lines {
+				addLine(coords)
+			}
+		}
+
+	case "MultiPolygon":
+		var polygons [][][][]float64
+		if err = json.Unmarshal(obj.Coordinates, &polygons); err == nil {
+			for _, rings := range polygons {
+				for _, coords := range rings {
+					addLine(coords)
+				}
+			}
+		}
+
+	default:
+		return fmt.Errorf("unsupported GeoJSON type: %q", obj.Type)
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid %s coordinates: %w", obj.Type, err)
+	}
+	return nil
+}
+
+// toLatLons converts GeoJSON [lon, lat] pairs to LatLon points
+func toLatLons(coords [][]float64) []LatLon {
+	points := make([]LatLon, 0, len(coords))
+	for _, c := range coords {
+		if len(c) >= 2 {
+			points = append(points, LatLon{Lat: c[1], Lon: c[0]})
+		}
+	}
+	return points
+}
+
+// LoadOverlays loads GeoJSON overlays from a list of files or directories
+// Directories are scanned (non-recursively) for .geojson and .json files.
+// Files that fail to parse are skipped with a warning.
+func LoadOverlays(paths []string) []*Feature {
+	var features []*Feature
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			fmt.Printf("Warning: overlay %s not found\n", path)
+			continue
+		}
+
+		files := []string{path}
+		if info.IsDir() {
+			files = nil
+			entries, err := os.ReadDir(path)
+			if err != nil {
+				fmt.Printf("Warning: failed to read overlay directory %s: %v\n", path, err)
+				continue
+			}
+			for _, entry := range entries {
+				ext := strings.ToLower(filepath.Ext(entry.Name()))
+				if !entry.IsDir() && (ext == ".geojson" || ext == ".json") {
+					files = append(files, filepath.Join(path, entry.Name()))
+				}
+			}
+		}
+
+		for _, file := range files {
+			loaded, err := NewGeoJSONLoader(file).Load()
+			if err != nil {
+				fmt.Printf("Warning: failed to load overlay %s: %v\n", file, err)
+				continue
+			}
+			features = append(features, loaded...)
+		}
+	}
+
+	return features
+}
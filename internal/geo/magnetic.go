@@ -0,0 +1,260 @@
+package geo
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MagneticModel is a spherical harmonic model of the Earth's main magnetic
+// field in the World Magnetic Model (WMM) coefficient format
+type MagneticModel struct {
+	Name   string
+	Epoch  float64 // Decimal year the coefficients refer to
+	maxN   int
+	g, h   [][]float64 // Main field coefficients, nT
+	dg, dh [][]float64 // Secular variation, nT/year
+}
+
+// wmmTruncated holds the WMM2020 coefficients up to degree and order 6.
+// Truncating the 12th-order model keeps declination within roughly a degree
+// across populated areas, which is plenty for displaying magnetic headings.
+// Drop a full WMM.COF into the cache directory for the complete model.
+const wmmTruncated = `    2020.0            WMM-2020 (degree 6)
+  1  0  -29404.5       0.0        6.7        0.0
+  1  1   -1450.7    4652.9        7.7      -25.1
+  2  0   -2500.0       0.0      -11.5        0.0
+  2  1    2982.0   -2991.6       -7.1      -30.2
+  2  2    1676.8    -734.8       -2.2      -23.9
+  3  0    1363.9       0.0        2.8        0.0
+  3  1   -2381.0     -82.2       -6.2        5.7
+  3  2    1236.2     241.8        3.4       -1.0
+  3  3     525.7    -542.9      -12.2        1.1
+  4  0     903.1       0.0       -1.1        0.0
+  4  1     809.4     282.0       -1.6        0.2
+  4  2      86.2    -158.4       -6.0        6.9
+  4  3    -309.4     199.8        5.4        3.7
+  4  4      47.9    -350.1       -5.5       -5.6
+  5  0    -234.4       0.0       -0.3        0.0
+  5  1     363.1      47.7        0.6        0.1
+  5  2     187.8     208.4       -0.7        2.5
+  5  3    -140.7    -121.3        0.1       -0.9
+  5  4    -151.2      32.2        1.2        3.0
+  5  5      13.7      99.1        1.0        0.5
+  6  0      65.9       0.0       -0.6        0.0
+  6  1      65.6     -19.1       -0.4        0.1
+  6  2      73.0      25.0        0.5       -1.8
+  6  3    -121.5      52.7        1.4       -1.4
+  6  4     -36.2     -64.4       -1.4        0.9
+  6  5      13.5       9.0       -0.0        0.1
+  6  6     -64.7      68.1        0.8        1.0
+999999999999999999999999999999999999999999999999
+`
+
+// DefaultMagneticModel returns the built-in truncated WMM
+func DefaultMagneticModel() *MagneticModel {
+	model, err := parseMagneticModel(bufio.NewScanner(strings.NewReader(wmmTruncated)))
+	if err != nil {
+		panic(fmt.Sprintf("built-in magnetic model is invalid: %v", err))
+	}
+	return model
+}
+
+// LoadMagneticModel loads a WMM.COF coefficient file as published by NOAA
+func LoadMagneticModel(path string) (*MagneticModel, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open magnetic model: %w", err)
+	}
+	defer file.Close()
+
+	return parseMagneticModel(bufio.NewScanner(file))
+}
+
+// parseMagneticModel parses the COF format: a header line with the epoch and
+// model name, then "n m g h dg dh" lines, terminated by a line of 9s
+func parseMagneticModel(scanner *bufio.Scanner) (*MagneticModel, error) {
+	if !scanner.Scan() {
+		return nil, fmt.Errorf("empty coefficient file")
+	}
+
+	header := strings.Fields(scanner.Text())
+	if len(header) < 2 {
+		return nil, fmt.Errorf("invalid header line")
+	}
+	epoch, err := strconv.ParseFloat(header[0], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid epoch %q", header[0])
+	}
+
+	type coeff struct {
+		n, m         int
+		g, h, dg, dh float64
+	}
+	var coeffs []coeff
+	maxN := 0
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if strings.HasPrefix(fields[0], "9999") {
+			break
+		}
+		if len(fields) < 6 {
+			return nil, fmt.Errorf("invalid coefficient line %q", scanner.Text())
+		}
+
+		var c coeff
+		values := make([]float64, 4)
+		if c.n, err = strconv.Atoi(fields[0]); err != nil {
+			return nil, fmt.Errorf("invalid degree %q", fields[0])
+		}
+		if c.m, err = strconv.Atoi(fields[1]); err != nil {
+			return nil, fmt.Errorf("invalid order %q", fields[1])
+		}
+		for i := range values {
+			if values[i], err = strconv.ParseFloat(fields[i+2], 64); err != nil {
+				return nil, fmt.Errorf("invalid coefficient %q", fields[i+2])
+			}
+		}
+		if c.m > c.n || c.n < 1 {
+			return nil, fmt.Errorf("invalid degree/order %d/%d", c.n, c.m)
+		}
+		c.g, c.h, c.dg, c.dh = values[0], values[1], values[2], values[3]
+
+		coeffs = append(coeffs, c)
+		maxN = max(maxN, c.n)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if maxN == 0 {
+		return nil, fmt.Errorf("no coefficients found")
+	}
+
+	table := func() [][]float64 {
+		t := make([][]float64, maxN+1)
+		for n := range t {
+			t[n] = make([]float64, n+1)
+		}
+		return t
+	}
+
+	model := &MagneticModel{
+		Name:  strings.Join(header[1:], " "),
+		Epoch: epoch,
+		maxN:  maxN,
+		g:     table(),
+		h:     table(),
+		dg:    table(),
+		dh:    table(),
+	}
+	for _, c := range coeffs {
+		model.g[c.n][c.m] = c.g
+		model.h[c.n][c.m] = c.h
+		model.dg[c.n][c.m] = c.dg
+		model.dh[c.n][c.m] = c.dh
+	}
+
+	return model, nil
+}
+
+// Declination returns the magnetic declination in degrees (east positive)
+// at a geodetic position and altitude above the ellipsoid, at time t.
+// Magnetic bearing = true bearing - declination.
+func (mm *MagneticModel) Declination(lat, lon, altitudeFeet float64, t time.Time) float64 {
+	const (
+		wgs84A    = 6378.137 // km
+		wgs84F    = 1 / 298.257223563
+		refRadius = 6371.2 // km, geomagnetic reference radius
+	)
+
+	yearStart := time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	yearEnd := yearStart.AddDate(1, 0, 0)
+	decimalYear := float64(t.Year()) + t.Sub(yearStart).Hours()/yearEnd.Sub(yearStart).Hours()
+	dt := decimalYear - mm.Epoch
+
+	// Geodetic to geocentric spherical coordinates
+	phi := lat * math.Pi / 180
+	lambda := lon * math.Pi / 180
+	altKm := altitudeFeet * 0.0003048
+	e2 := wgs84F * (2 - wgs84F)
+	rc := wgs84A / math.Sqrt(1-e2*math.Sin(phi)*math.Sin(phi))
+	p := (rc + altKm) * math.Cos(phi)
+	z := (rc*(1-e2) + altKm) * math.Sin(phi)
+	r := math.Hypot(p, z)
+	phiC := math.Asin(z / r)
+
+	// Schmidt semi-normalized associated Legendre functions of the
+	// colatitude theta, and their derivatives with respect to theta
+	cosT := math.Sin(phiC)
+	sinT := math.Cos(phiC)
+	P := make([][]float64, mm.maxN+1)
+	dP := make([][]float64, mm.maxN+1)
+	for n := range P {
+		P[n] = make([]float64, n+1)
+		dP[n] = make([]float64, n+1)
+	}
+	P[0][0] = 1
+	for n := 1; n <= mm.maxN; n++ {
+		for m := 0; m <= n; m++ {
+			switch {
+			case n == m && n == 1:
+				P[1][1] = sinT
+				dP[1][1] = cosT
+			case n == m:
+				k := math.Sqrt(float64(2*n-1) / float64(2*n))
+				P[n][n] = k * sinT * P[n-1][n-1]
+				dP[n][n] = k * (cosT*P[n-1][n-1] + sinT*dP[n-1][n-1])
+			default:
+				k1 := float64(2*n - 1)
+				k2 := 0.0
+				var p2, dp2 float64
+				if n-2 >= m {
+					k2 = math.Sqrt(float64((n-1)*(n-1) - m*m))
+					p2, dp2 = P[n-2][m], dP[n-2][m]
+				}
+				denom := math.Sqrt(float64(n*n - m*m))
+				P[n][m] = (k1*cosT*P[n-1][m] - k2*p2) / denom
+				dP[n][m] = (k1*(cosT*dP[n-1][m]-sinT*P[n-1][m]) - k2*dp2) / denom
+			}
+		}
+	}
+
+	// Field components in the geocentric frame
+	var bx, by, bz float64
+	for n := 1; n <= mm.maxN; n++ {
+		ratio := math.Pow(refRadius/r, float64(n+2))
+		for m := 0; m <= n; m++ {
+			g := mm.g[n][m] + dt*mm.dg[n][m]
+			h := mm.h[n][m] + dt*mm.dh[n][m]
+			cosM := math.Cos(float64(m) * lambda)
+			sinM := math.Sin(float64(m) * lambda)
+
+			bx += ratio * (g*cosM + h*sinM) * dP[n][m]
+			by += ratio * float64(m) * (g*sinM - h*cosM) * P[n][m]
+			bz -= ratio * float64(n+1) * (g*cosM + h*sinM) * P[n][m]
+		}
+	}
+	if sinT > 1e-10 {
+		by /= sinT
+	}
+
+	// Rotate north component back to the geodetic frame
+	psi := phiC - phi
+	north := bx*math.Cos(psi) - bz*math.Sin(psi)
+
+	return math.Atan2(by, north) * 180 / math.Pi
+}
+
+// MagneticBearing converts a true bearing to magnetic using a declination
+func MagneticBearing(trueBearing int, declination float64) int {
+	mag := int(math.Round(float64(trueBearing) - declination))
+	return ((mag % 360) + 360) % 360
+}
@@ -0,0 +1,101 @@
+package geo
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// NavaidLoader loads radio navigation aids from the OurAirports navaids CSV
+type NavaidLoader struct {
+	csvPath string
+}
+
+// NewNavaidLoader creates a new navaid loader
+func NewNavaidLoader(csvPath string) *NavaidLoader {
+	return &NavaidLoader{
+		csvPath: csvPath,
+	}
+}
+
+// LoadNavaids loads VOR, NDB and DME stations from the CSV file
+// Each feature is labeled with its identifier; the navaid type (VOR,
+// VORTAC, VOR-DME, NDB, DME, TACAN, ...) is kept in Properties["type"]
+func (n *NavaidLoader) LoadNavaids() ([]*Feature, error) {
+	file, err := os.Open(n.csvPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open navaids CSV: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	colIndices := make(map[string]int)
+	for i, col := range header {
+		colIndices[col] = i
+	}
+
+	required := []string{"ident", "name", "type", "latitude_deg", "longitude_deg"}
+	for _, col := range required {
+		if _, ok := colIndices[col]; !ok {
+			return nil, fmt.Errorf("missing required column: %s", col)
+		}
+	}
+
+	var navaids []*Feature
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			continue
+		}
+
+		navaidType := record[colIndices["type"]]
+		if NavaidKind(navaidType) == "" {
+			continue
+		}
+
+		lat, err := strconv.ParseFloat(record[colIndices["latitude_deg"]], 64)
+		if err != nil {
+			continue
+		}
+
+		lon, err := strconv.ParseFloat(record[colIndices["longitude_deg"]], 64)
+		if err != nil {
+			continue
+		}
+
+		navaid := NewPointFeature(FeatureNavaid, LatLon{Lat: lat, Lon: lon}, record[colIndices["ident"]])
+		navaid.Properties["full_name"] = record[colIndices["name"]]
+		navaid.Properties["type"] = navaidType
+
+		navaids = append(navaids, navaid)
+	}
+
+	return navaids, nil
+}
+
+// NavaidKind groups OurAirports navaid types into VOR, NDB or DME
+// Returns an empty string for types that aren't rendered
+func NavaidKind(navaidType string) string {
+	switch navaidType {
+	case "VOR", "VORTAC", "VOR-DME":
+		return "VOR"
+	case "NDB", "NDB-DME":
+		return "NDB"
+	case "DME", "TACAN":
+		return "DME"
+	default:
+		return ""
+	}
+}
@@ -17,7 +17,7 @@ type Projection struct {
 	radiusMiles  float64
 	screenWidth  int
 	screenHeight int
-	aspectRatio  float64 
+	aspectRatio  float64
 	scaleX       float64
 	scaleY       float64
 }
@@ -28,7 +28,7 @@ type Projection struct {
 func NewProjection(centerLat, centerLon, radiusMiles float64, screenWidth, screenHeight int, aspectRatio float64) *Projection {
 	p := &Projection{
 		centerLat:    centerLat,
-		centerLon:    centerLon,
+		centerLon:    NormalizeLon(centerLon),
 		radiusMiles:  radiusMiles,
 		screenWidth:  screenWidth,
 		screenHeight: screenHeight,
@@ -45,7 +45,7 @@ func (p *Projection) calculateScale() {
 	// 1 degree longitude ≈ 69 * cos(latitude) miles
 
 	milesPerDegreeLat := 69.0
-	milesPerDegreeLon := 69.0 * math.Cos(p.centerLat*math.Pi/180.0)
+	milesPerDegreeLon := milesPerDegreeLonAt(p.centerLat)
 
 	degreesLat := p.radiusMiles / milesPerDegreeLat
 	degreesLon := p.radiusMiles / milesPerDegreeLon
@@ -59,7 +59,7 @@ func (p *Projection) calculateScale() {
 
 	if scaleX < scaleY {
 		p.scaleX = scaleX
-		p.scaleY = scaleX / p.aspectRatio 
+		p.scaleY = scaleX / p.aspectRatio
 	} else {
 		p.scaleX = scaleY * p.aspectRatio
 		p.scaleY = scaleY
@@ -70,7 +70,9 @@ func (p *Projection) calculateScale() {
 // Returns screen coordinates with (0, 0) at top-left
 func (p *Projection) Project(lat, lon float64) Point {
 	deltaLat := lat - p.centerLat
-	deltaLon := lon - p.centerLon
+	// Take the short way around so points just across the antimeridian
+	// land next to the center instead of on the far side of the world
+	deltaLon := NormalizeLon(lon - p.centerLon)
 
 	// Convert to pixels
 	// Note: Y is inverted (positive lat goes up, but positive screen Y goes down)
@@ -95,7 +97,7 @@ func (p *Projection) Unproject(x, y int) (lat, lon float64) {
 	deltaLat := -float64(y) / p.scaleY // Negative because screen Y is inverted
 
 	lat = p.centerLat + deltaLat
-	lon = p.centerLon + deltaLon
+	lon = NormalizeLon(p.centerLon + deltaLon)
 
 	return lat, lon
 }
@@ -110,7 +112,7 @@ func (p *Projection) IsInBounds(lat, lon float64) bool {
 // UpdateCenter recalculates the projection with a new center point
 func (p *Projection) UpdateCenter(lat, lon float64) {
 	p.centerLat = lat
-	p.centerLon = lon
+	p.centerLon = NormalizeLon(lon)
 	p.calculateScale()
 }
 
@@ -126,20 +128,70 @@ func (p *Projection) GetCenter() (lat, lon float64) {
 	return p.centerLat, p.centerLon
 }
 
+// GetRadius returns the radius in miles the projection was built for
+func (p *Projection) GetRadius() float64 {
+	return p.radiusMiles
+}
+
 // GetBounds returns the geographic bounds visible on screen
+// Longitudes are not normalized, so a view straddling the antimeridian
+// yields MinLon < -180 or MaxLon > 180; Bounds.Contains handles the wrap.
 func (p *Projection) GetBounds() *Bounds {
-	topLeftLat, topLeftLon := p.Unproject(0, 0)
-	bottomRightLat, bottomRightLon := p.Unproject(p.screenWidth-1, p.screenHeight-1)
+	halfLon := float64(p.screenWidth) / 2 / p.scaleX
+	halfLat := float64(p.screenHeight) / 2 / p.scaleY
 
-	minLat := math.Min(topLeftLat, bottomRightLat)
-	maxLat := math.Max(topLeftLat, bottomRightLat)
-	minLon := math.Min(topLeftLon, bottomRightLon)
-	maxLon := math.Max(topLeftLon, bottomRightLon)
+	if halfLon > 180 {
+		halfLon = 180
+	}
 
 	return &Bounds{
-		MinLat: minLat,
-		MaxLat: maxLat,
-		MinLon: minLon,
-		MaxLon: maxLon,
+		MinLat: math.Max(p.centerLat-halfLat, -90),
+		MaxLat: math.Min(p.centerLat+halfLat, 90),
+		MinLon: p.centerLon - halfLon,
+		MaxLon: p.centerLon + halfLon,
 	}
 }
+
+// DegreesPerCell returns the smallest number of degrees covered by a single
+// character cell in either axis, useful for picking a simplification tolerance
+func (p *Projection) DegreesPerCell() float64 {
+	return math.Min(1.0/p.scaleX, 1.0/p.scaleY)
+}
+
+// ProjectionState captures every input that affects projected coordinates.
+// Two projections with equal states produce identical screen positions.
+type ProjectionState struct {
+	CenterLat    float64
+	CenterLon    float64
+	RadiusMiles  float64
+	ScreenWidth  int
+	ScreenHeight int
+	AspectRatio  float64
+}
+
+// State returns the current projection state for change detection
+func (p *Projection) State() ProjectionState {
+	return ProjectionState{
+		CenterLat:    p.centerLat,
+		CenterLon:    p.centerLon,
+		RadiusMiles:  p.radiusMiles,
+		ScreenWidth:  p.screenWidth,
+		ScreenHeight: p.screenHeight,
+		AspectRatio:  p.aspectRatio,
+	}
+}
+
+// NormalizeLon wraps a longitude into the range [-180, 180)
+func NormalizeLon(lon float64) float64 {
+	lon = math.Mod(lon+180, 360)
+	if lon < 0 {
+		lon += 360
+	}
+	return lon - 180
+}
+
+// milesPerDegreeLonAt returns the length of one degree of longitude at the
+// given latitude, clamped so the scale stays finite near the poles
+func milesPerDegreeLonAt(lat float64) float64 {
+	return 69.0 * math.Max(math.Cos(lat*math.Pi/180.0), 0.01)
+}
@@ -0,0 +1,201 @@
+package geo
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+)
+
+// Route is the scheduled origin and destination for a flight callsign
+type Route struct {
+	Origin      string // Airport code (ICAO or IATA)
+	Destination string // Airport code (ICAO or IATA)
+}
+
+// RouteTable maps flight callsigns to routes
+type RouteTable struct {
+	routes map[string]Route
+}
+
+// LoadRoutes loads a route table from CSV
+// Two layouts are accepted: a simple "callsign,origin,destination" file, or
+// the VRS standing-data routes format with Callsign and AirportCodes
+// ("KDFW-KLAX") columns. For multi-leg routes the first and last airports
+// are used.
+func LoadRoutes(csvPath string) (*RouteTable, error) {
+	file, err := os.Open(csvPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open routes file: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	table := &RouteTable{routes: make(map[string]Route)}
+
+	callsignCol, airportsCol := -1, -1
+	first := true
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			continue
+		}
+
+		if first {
+			first = false
+			for i, col := range record {
+				switch strings.ToLower(strings.TrimSpace(col)) {
+				case "callsign":
+					callsignCol = i
+				case "airportcodes":
+					airportsCol = i
+				}
+			}
+			if callsignCol >= 0 {
+				continue // Header row
+			}
+		}
+
+		var callsign string
+		var airports []string
+		if airportsCol >= 0 {
+			if len(record) <= airportsCol || len(record) <= callsignCol {
+				continue
+			}
+			callsign = record[callsignCol]
+			airports = strings.Split(record[airportsCol], "-")
+		} else {
+			if len(record) < 3 {
+				continue
+			}
+			callsign = record[0]
+			airports = record[1:3]
+		}
+
+		if len(airports) < 2 {
+			continue
+		}
+
+		callsign = strings.ToUpper(strings.TrimSpace(callsign))
+		table.routes[callsign] = Route{
+			Origin:      strings.ToUpper(strings.TrimSpace(airports[0])),
+			Destination: strings.ToUpper(strings.TrimSpace(airports[len(airports)-1])),
+		}
+	}
+
+	return table, nil
+}
+
+// Lookup returns the route for a callsign, if known
+func (r *RouteTable) Lookup(callsign string) (Route, bool) {
+	if r == nil {
+		return Route{}, false
+	}
+	route, ok := r.routes[strings.ToUpper(strings.TrimSpace(callsign))]
+	return route, ok
+}
+
+// Len returns the number of routes in the table
+func (r *RouteTable) Len() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.routes)
+}
+
+// AirportIndex resolves airport codes (ICAO ident or IATA) to airport
+// features, and finds the airport nearest a point
+type AirportIndex struct {
+	byCode map[string]*Feature
+	grid   map[gridCell][]*Feature // Airports by whole degree of lat/lon
+}
+
+// gridCell is a one-degree square of latitude and longitude
+type gridCell struct {
+	lat, lon int
+}
+
+// cellOf returns the grid cell a point falls in
+func cellOf(p LatLon) gridCell {
+	return gridCell{int(math.Floor(p.Lat)), int(math.Floor(p.Lon))}
+}
+
+// NewAirportIndex builds an index over airport features loaded by AirportLoader
+func NewAirportIndex(airports []*Feature) *AirportIndex {
+	index := &AirportIndex{
+		byCode: make(map[string]*Feature, len(airports)*2),
+		grid:   make(map[gridCell][]*Feature),
+	}
+
+	for _, airport := range airports {
+		if ident, ok := airport.Properties["ident"].(string); ok && ident != "" {
+			index.byCode[strings.ToUpper(ident)] = airport
+		}
+		if airport.Point != nil {
+			cell := cellOf(*airport.Point)
+			index.grid[cell] = append(index.grid[cell], airport)
+		}
+	}
+	// IATA codes go second so an ICAO ident always wins a collision
+	for _, airport := range airports {
+		if iata, ok := airport.Properties["iata"].(string); ok && iata != "" {
+			if _, exists := index.byCode[strings.ToUpper(iata)]; !exists {
+				index.byCode[strings.ToUpper(iata)] = airport
+			}
+		}
+	}
+
+	return index
+}
+
+// Lookup returns the airport with the given ICAO ident or IATA code
+func (a *AirportIndex) Lookup(code string) (*Feature, bool) {
+	if a == nil {
+		return nil, false
+	}
+	airport, ok := a.byCode[strings.ToUpper(strings.TrimSpace(code))]
+	return airport, ok
+}
+
+// Nearest returns the airport closest to p within maxMiles and its distance
+// in miles, searching only the grid cells that range can reach
+func (a *AirportIndex) Nearest(p LatLon, maxMiles float64) (*Feature, float64, bool) {
+	if a == nil {
+		return nil, 0, false
+	}
+
+	const milesPerDegree = 69.0
+	latCells := int(math.Ceil(maxMiles / milesPerDegree))
+	lonCells := 180
+	if cos := math.Cos(p.Lat * math.Pi / 180); cos > 0.01 {
+		lonCells = min(int(math.Ceil(maxMiles/(milesPerDegree*cos))), 180)
+	}
+
+	center := cellOf(p)
+	var nearest *Feature
+	best := maxMiles
+	for dLat := -latCells; dLat <= latCells; dLat++ {
+		for dLon := -lonCells; dLon <= lonCells; dLon++ {
+			lon := (center.lon+dLon+180)%360 - 180
+			if lon < -180 {
+				lon += 360
+			}
+			for _, airport := range a.grid[gridCell{center.lat + dLat, lon}] {
+				if d := Distance(p, *airport.Point); d <= best {
+					nearest, best = airport, d
+				}
+			}
+		}
+	}
+	return nearest, best, nearest != nil
+}
@@ -0,0 +1,108 @@
+package geo
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// RunwayLoader loads runway centerlines from the OurAirports runways CSV
+type RunwayLoader struct {
+	csvPath string
+}
+
+// NewRunwayLoader creates a new runway loader
+func NewRunwayLoader(csvPath string) *RunwayLoader {
+	return &RunwayLoader{
+		csvPath: csvPath,
+	}
+}
+
+// LoadRunways loads runway centerlines for the given airports
+// airports maps an airport ident (e.g. "KDFW") to its reference point; runways
+// at other airports are skipped. When a runway lacks threshold coordinates,
+// its centerline is reconstructed from the airport position, heading and length.
+func (r *RunwayLoader) LoadRunways(airports map[string]LatLon) ([]*Feature, error) {
+	file, err := os.Open(r.csvPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open runways CSV: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	colIndices := make(map[string]int)
+	for i, col := range header {
+		colIndices[col] = i
+	}
+
+	required := []string{"airport_ident", "length_ft", "closed", "le_ident", "he_ident",
+		"le_latitude_deg", "le_longitude_deg", "he_latitude_deg", "he_longitude_deg", "le_heading_degT"}
+	for _, col := range required {
+		if _, ok := colIndices[col]; !ok {
+			return nil, fmt.Errorf("missing required column: %s", col)
+		}
+	}
+
+	parse := func(record []string, col string) (float64, bool) {
+		v, err := strconv.ParseFloat(record[colIndices[col]], 64)
+		return v, err == nil
+	}
+
+	var runways []*Feature
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			continue
+		}
+
+		ident := record[colIndices["airport_ident"]]
+		center, ok := airports[ident]
+		if !ok || record[colIndices["closed"]] == "1" {
+			continue
+		}
+
+		var points []LatLon
+
+		leLat, ok1 := parse(record, "le_latitude_deg")
+		leLon, ok2 := parse(record, "le_longitude_deg")
+		heLat, ok3 := parse(record, "he_latitude_deg")
+		heLon, ok4 := parse(record, "he_longitude_deg")
+		if ok1 && ok2 && ok3 && ok4 {
+			points = []LatLon{{Lat: leLat, Lon: leLon}, {Lat: heLat, Lon: heLon}}
+		} else {
+			heading, okHeading := parse(record, "le_heading_degT")
+			length, okLength := parse(record, "length_ft")
+			if !okHeading || !okLength || length <= 0 {
+				continue
+			}
+			halfMiles := length / 2 / 5280
+			points = []LatLon{
+				Destination(center.Lat, center.Lon, heading+180, halfMiles),
+				Destination(center.Lat, center.Lon, heading, halfMiles),
+			}
+		}
+
+		runway := NewLineFeature(FeatureRunway, points)
+		runway.Name = record[colIndices["le_ident"]] + "/" + record[colIndices["he_ident"]]
+		runway.Properties["airport"] = ident
+		if length, ok := parse(record, "length_ft"); ok {
+			runway.Properties["length_ft"] = length
+		}
+
+		runways = append(runways, runway)
+	}
+
+	return runways, nil
+}
@@ -2,71 +2,170 @@ package geo
 
 import (
 	"fmt"
+	"io"
 	"math"
+	"os"
 	"strings"
 
 	"github.com/jonas-p/go-shp"
 )
 
+// Road dataset identifiers stored in highway Properties["dataset"]
+const (
+	RoadsNorthAmerica = "north_america"
+	RoadsGlobal       = "global"
+)
+
+// Highway detail levels are the highest Natural Earth road scalerank
+// drawn; lower shows fewer, more major roads
+const (
+	MinHighwayDetail = 1
+	MaxHighwayDetail = 10
+)
+
+// NorthAmericaBounds approximates the coverage of the North American roads
+// dataset; outside it the global roads dataset is preferred
+var NorthAmericaBounds = &Bounds{MinLat: 7, MaxLat: 84, MinLon: -170, MaxLon: -52}
+
+// DetailLevel selects the Natural Earth scale for coastlines, rivers and
+// state borders
+type DetailLevel int
+
+const (
+	DetailMedium DetailLevel = iota // 1:50m, the default
+	DetailHigh                      // 1:10m, for maps mostly zoomed in below 50 miles
+)
+
+// ParseDetailLevel parses "medium" or "high"
+func ParseDetailLevel(s string) (DetailLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "medium", "":
+		return DetailMedium, nil
+	case "high":
+		return DetailHigh, nil
+	default:
+		return DetailMedium, fmt.Errorf("unknown detail level %q (use medium or high)", s)
+	}
+}
+
 // ShapefileLoader loads and parses ESRI shapefiles
 type ShapefileLoader struct {
 	dataDir string
+	detail  DetailLevel
+	out     io.Writer // Where progress and warnings are printed
 }
 
 // NewShapefileLoader creates a new shapefile loader
 func NewShapefileLoader(dataDir string) *ShapefileLoader {
 	return &ShapefileLoader{
 		dataDir: dataDir,
+		out:     os.Stdout,
 	}
 }
 
+// SetOutput sets where progress and warnings are printed, stdout by default
+func (s *ShapefileLoader) SetOutput(out io.Writer) {
+	s.out = out
+}
+
+// SetDetail sets which Natural Earth scale LoadAll prefers
+func (s *ShapefileLoader) SetDetail(detail DetailLevel) {
+	s.detail = detail
+}
+
+// naturalEarthPath returns the shapefile for a Natural Earth dataset such
+// as "coastline", preferring the 1:10m file at high detail when it has been
+// downloaded and falling back to 1:50m otherwise
+func (s *ShapefileLoader) naturalEarthPath(name string) string {
+	if s.detail == DetailHigh {
+		path := s.dataDir + "/ne_10m_" + name + ".shp"
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return s.dataDir + "/ne_50m_" + name + ".shp"
+}
+
 // LoadAll loads all required shapefiles and returns them organized by feature type
 // Missing files will be skipped with a warning - app can function with just aircraft
-// highwayDetail is the scalerank threshold for highways (lower = fewer roads)
-func (s *ShapefileLoader) LoadAll(highwayDetail int) (map[FeatureType][]*Feature, error) {
+// Roads up to MaxHighwayDetail are loaded so the renderer can change the
+// highway detail without reloading
+func (s *ShapefileLoader) LoadAll() (map[FeatureType][]*Feature, error) {
 	features := make(map[FeatureType][]*Feature)
 
-	// Load state borders (50m resolution)
-	states, err := s.LoadShapefile(s.dataDir+"/ne_50m_admin_1_states_provinces.shp", FeatureStateBorder)
+	// Load state borders (50m resolution, 10m at high detail)
+	states, err := s.LoadShapefile(s.naturalEarthPath("admin_1_states_provinces"), FeatureStateBorder)
 	if err != nil {
-		fmt.Printf("Warning: failed to load states: %v\n", err)
+		fmt.Fprintf(s.out, "Warning: failed to load states: %v\n", err)
 		features[FeatureStateBorder] = []*Feature{}
 	} else {
 		features[FeatureStateBorder] = states
 	}
 
-	// Load rivers (50m resolution)
-	rivers, err := s.LoadShapefile(s.dataDir+"/ne_50m_rivers_lake_centerlines.shp", FeatureRiver)
+	// Load rivers (50m resolution, 10m at high detail)
+	rivers, err := s.LoadShapefile(s.naturalEarthPath("rivers_lake_centerlines"), FeatureRiver)
 	if err != nil {
-		fmt.Printf("Warning: failed to load rivers: %v\n", err)
+		fmt.Fprintf(s.out, "Warning: failed to load rivers: %v\n", err)
 		features[FeatureRiver] = []*Feature{}
 	} else {
 		features[FeatureRiver] = rivers
 	}
 
-	// Load coastlines (50m resolution)
-	coasts, err := s.LoadShapefile(s.dataDir+"/ne_50m_coastline.shp", FeatureCoastline)
+	// Load coastlines (50m resolution, 10m at high detail)
+	coasts, err := s.LoadShapefile(s.naturalEarthPath("coastline"), FeatureCoastline)
 	if err != nil {
-		fmt.Printf("Warning: failed to load coastlines: %v\n", err)
+		fmt.Fprintf(s.out, "Warning: failed to load coastlines: %v\n", err)
 		features[FeatureCoastline] = []*Feature{}
 	} else {
 		features[FeatureCoastline] = coasts
 	}
 
 	// Load highways/roads (10m resolution - North America)
-	// Filter by scalerank threshold (lower = fewer roads)
-	highways, err := s.LoadHighways(s.dataDir+"/ne_10m_roads_north_america.shp", highwayDetail)
+	highways, err := s.LoadHighways(s.dataDir+"/ne_10m_roads_north_america.shp", MaxHighwayDetail)
+	if err != nil {
+		fmt.Fprintf(s.out, "Warning: failed to load highways: %v\n", err)
+		highways = []*Feature{}
+	}
+	for _, highway := range highways {
+		highway.Properties["dataset"] = RoadsNorthAmerica
+	}
+
+	// Load worldwide roads if they were downloaded (optional)
+	globalRoadsPath := s.dataDir + "/ne_10m_roads.shp"
+	if _, err := os.Stat(globalRoadsPath); err == nil {
+		globalRoads, err := s.LoadHighways(globalRoadsPath, MaxHighwayDetail)
+		if err != nil {
+			fmt.Fprintf(s.out, "Warning: failed to load global roads: %v\n", err)
+		}
+		for _, highway := range globalRoads {
+			highway.Properties["dataset"] = RoadsGlobal
+		}
+		highways = append(highways, globalRoads...)
+	}
+	features[FeatureHighway] = highways
+
+	// Load FAA class airspace (US only, optional download)
+	airspace, err := s.LoadAirspace(s.dataDir + "/Class_Airspace.shp")
+	if err != nil {
+		fmt.Fprintf(s.out, "Warning: failed to load airspace: %v\n", err)
+		features[FeatureAirspace] = []*Feature{}
+	} else {
+		features[FeatureAirspace] = airspace
+	}
+
+	// Load time zone boundaries (10m resolution, optional)
+	timeZones, err := s.LoadTimeZones(s.dataDir + "/ne_10m_time_zones.shp")
 	if err != nil {
-		fmt.Printf("Warning: failed to load highways: %v\n", err)
-		features[FeatureHighway] = []*Feature{}
+		fmt.Fprintf(s.out, "Warning: failed to load time zones: %v\n", err)
+		features[FeatureTimeZone] = []*Feature{}
 	} else {
-		features[FeatureHighway] = highways
+		features[FeatureTimeZone] = timeZones
 	}
 
 	// Load cities (50m resolution)
 	cities, err := s.LoadCities(s.dataDir + "/ne_50m_populated_places.shp")
 	if err != nil {
-		fmt.Printf("Warning: failed to load cities: %v\n", err)
+		fmt.Fprintf(s.out, "Warning: failed to load cities: %v\n", err)
 		features[FeatureCity] = []*Feature{}
 	} else {
 		features[FeatureCity] = cities
@@ -76,23 +175,65 @@ func (s *ShapefileLoader) LoadAll(highwayDetail int) (map[FeatureType][]*Feature
 	airportLoader := NewAirportLoader(s.dataDir + "/airports.csv")
 	airports, err := airportLoader.LoadAirports()
 	if err != nil {
-		fmt.Printf("Warning: failed to load airports: %v\n", err)
+		fmt.Fprintf(s.out, "Warning: failed to load airports: %v\n", err)
 		features[FeatureAirport] = []*Feature{}
 	} else {
 		features[FeatureAirport] = airports
 	}
 
-	// Show feature counts
-	fmt.Printf("Loaded features: %d states, %d rivers, %d coastlines, %d highways, %d cities, %d airports\n",
-		len(features[FeatureStateBorder]),
-		len(features[FeatureRiver]),
-		len(features[FeatureCoastline]),
-		len(features[FeatureHighway]),
-		len(features[FeatureCity]),
-		len(features[FeatureAirport]))
+	// Load runways for the loaded airports
+	airportPositions := make(map[string]LatLon, len(features[FeatureAirport]))
+	for _, airport := range features[FeatureAirport] {
+		if ident, ok := airport.Properties["ident"].(string); ok {
+			airportPositions[ident] = *airport.Point
+		}
+	}
+	runwayLoader := NewRunwayLoader(s.dataDir + "/runways.csv")
+	runways, err := runwayLoader.LoadRunways(airportPositions)
+	if err != nil {
+		fmt.Fprintf(s.out, "Warning: failed to load runways: %v\n", err)
+		features[FeatureRunway] = []*Feature{}
+	} else {
+		features[FeatureRunway] = runways
+	}
+
+	// Load navaids from CSV
+	navaidLoader := NewNavaidLoader(s.dataDir + "/navaids.csv")
+	navaids, err := navaidLoader.LoadNavaids()
+	if err != nil {
+		fmt.Fprintf(s.out, "Warning: failed to load navaids: %v\n", err)
+		features[FeatureNavaid] = []*Feature{}
+	} else {
+		features[FeatureNavaid] = navaids
+	}
+
+	s.printFeatureCounts(featureCounts(features))
 	return features, nil
 }
 
+// featureCounts returns how many features there are of each type
+func featureCounts(features map[FeatureType][]*Feature) map[FeatureType]int {
+	counts := make(map[FeatureType]int, len(features))
+	for ftype, list := range features {
+		counts[ftype] = len(list)
+	}
+	return counts
+}
+
+// printFeatureCounts shows how many features of each type were loaded
+func (s *ShapefileLoader) printFeatureCounts(counts map[FeatureType]int) {
+	fmt.Fprintf(s.out, "Loaded features: %d states, %d rivers, %d coastlines, %d highways, %d airspace, %d cities, %d airports, %d runways, %d navaids\n",
+		counts[FeatureStateBorder],
+		counts[FeatureRiver],
+		counts[FeatureCoastline],
+		counts[FeatureHighway],
+		counts[FeatureAirspace],
+		counts[FeatureCity],
+		counts[FeatureAirport],
+		counts[FeatureRunway],
+		counts[FeatureNavaid])
+}
+
 // LoadShapefile loads a shapefile and converts it to Feature objects
 func (s *ShapefileLoader) LoadShapefile(path string, ftype FeatureType) ([]*Feature, error) {
 	shape, err := shp.Open(path)
@@ -103,9 +244,17 @@ func (s *ShapefileLoader) LoadShapefile(path string, ftype FeatureType) ([]*Feat
 
 	features := make([]*Feature, 0)
 
+	// Keep the name attribute (rivers, lakes, states) for labeling
+	nameIdx := findField(shape.Fields(), "name", "name_en")
+
 	// Read all features
 	for shape.Next() {
-		_, p := shape.Shape()
+		n, p := shape.Shape()
+
+		name := ""
+		if nameIdx >= 0 {
+			name = strings.TrimSpace(shape.ReadAttribute(n, nameIdx))
+		}
 
 		switch geom := p.(type) {
 		case *shp.PolyLine:
@@ -119,7 +268,9 @@ func (s *ShapefileLoader) LoadShapefile(path string, ftype FeatureType) ([]*Feat
 				}
 			}
 			if len(points) > 1 {
-				features = append(features, NewLineFeature(ftype, points))
+				feature := NewLineFeature(ftype, points)
+				feature.Name = name
+				features = append(features, feature)
 			}
 
 		case *shp.Polygon:
@@ -132,12 +283,14 @@ func (s *ShapefileLoader) LoadShapefile(path string, ftype FeatureType) ([]*Feat
 				}
 			}
 			if len(points) > 1 {
-				features = append(features, NewLineFeature(ftype, points))
+				feature := NewLineFeature(ftype, points)
+				feature.Name = name
+				features = append(features, feature)
 			}
 
 		case *shp.Point:
 			// Point feature
-			feature := NewPointFeature(ftype, LatLon{Lat: geom.Y, Lon: geom.X}, "")
+			feature := NewPointFeature(ftype, LatLon{Lat: geom.Y, Lon: geom.X}, name)
 			features = append(features, feature)
 		}
 	}
@@ -193,6 +346,7 @@ func (s *ShapefileLoader) LoadCities(path string) ([]*Feature, error) {
 
 // LoadHighways loads highway/road features with filtering for major roads only
 // maxScalerank is the threshold - only roads with scalerank <= maxScalerank are loaded
+// Each road's scalerank is kept in Properties["scalerank"] when known
 func (s *ShapefileLoader) LoadHighways(path string, maxScalerank int) ([]*Feature, error) {
 	shape, err := shp.Open(path)
 	if err != nil {
@@ -213,21 +367,23 @@ func (s *ShapefileLoader) LoadHighways(path string, maxScalerank int) ([]*Featur
 		}
 	}
 
-	fmt.Printf("Loading highways with scalerank filtering (scalerank <= %d)...\n", maxScalerank)
+	fmt.Fprintf(s.out, "Loading highways with scalerank filtering (scalerank <= %d)...\n", maxScalerank)
 
 	// Read all features
 	for shape.Next() {
 		n, p := shape.Shape()
 
 		// Filter by scalerank if available
+		scalerank := -1
 		if scalerankIdx >= 0 {
 			scalerankStr := shape.ReadAttribute(n, scalerankIdx)
 			if scalerankStr != "" {
-				var scalerank int
 				if _, err := fmt.Sscanf(scalerankStr, "%d", &scalerank); err == nil {
 					if scalerank > maxScalerank {
 						continue // Skip roads above threshold
 					}
+				} else {
+					scalerank = -1
 				}
 			}
 		}
@@ -243,7 +399,11 @@ func (s *ShapefileLoader) LoadHighways(path string, maxScalerank int) ([]*Featur
 				}
 			}
 			if len(points) > 1 {
-				features = append(features, NewLineFeature(FeatureHighway, points))
+				feature := NewLineFeature(FeatureHighway, points)
+				if scalerank >= 0 {
+					feature.Properties["scalerank"] = scalerank
+				}
+				features = append(features, feature)
 			}
 		}
 	}
@@ -251,6 +411,120 @@ func (s *ShapefileLoader) LoadHighways(path string, maxScalerank int) ([]*Featur
 	return features, nil
 }
 
+// LoadAirspace loads FAA class airspace boundaries
+// Only Class B, C and D shelves are kept; each outline carries its class
+// letter in Properties["class"] for styling
+func (s *ShapefileLoader) LoadAirspace(path string) ([]*Feature, error) {
+	shape, err := shp.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer shape.Close()
+
+	classIdx := findField(shape.Fields(), "CLASS")
+	if classIdx < 0 {
+		return nil, fmt.Errorf("missing CLASS attribute")
+	}
+
+	features := make([]*Feature, 0)
+
+	for shape.Next() {
+		n, p := shape.Shape()
+
+		class := strings.TrimSpace(shape.ReadAttribute(n, classIdx))
+		if class != "B" && class != "C" && class != "D" {
+			continue
+		}
+
+		polygon, ok := p.(*shp.Polygon)
+		if !ok {
+			continue
+		}
+
+		for _, ring := range polygonRings(polygon) {
+			feature := NewLineFeature(FeatureAirspace, ring)
+			feature.Properties["class"] = class
+			features = append(features, feature)
+		}
+	}
+
+	return features, nil
+}
+
+// LoadTimeZones loads time zone boundary outlines
+// Each polygon ring becomes its own line feature so separate parts aren't
+// joined by stray connecting lines
+func (s *ShapefileLoader) LoadTimeZones(path string) ([]*Feature, error) {
+	shape, err := shp.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer shape.Close()
+
+	nameIdx := findField(shape.Fields(), "time_zone")
+	features := make([]*Feature, 0)
+
+	for shape.Next() {
+		n, p := shape.Shape()
+
+		polygon, ok := p.(*shp.Polygon)
+		if !ok {
+			continue
+		}
+
+		name := ""
+		if nameIdx >= 0 {
+			name = strings.TrimSpace(shape.ReadAttribute(n, nameIdx))
+		}
+
+		for _, ring := range polygonRings(polygon) {
+			feature := NewLineFeature(FeatureTimeZone, ring)
+			feature.Name = name
+			features = append(features, feature)
+		}
+	}
+
+	return features, nil
+}
+
+// polygonRings splits a shapefile polygon into its individual rings
+// (outer boundaries and holes), dropping degenerate ones
+func polygonRings(polygon *shp.Polygon) [][]LatLon {
+	rings := make([][]LatLon, 0, len(polygon.Parts))
+
+	for i := 0; i < len(polygon.Parts); i++ {
+		start := int(polygon.Parts[i])
+		end := len(polygon.Points)
+		if i+1 < len(polygon.Parts) {
+			end = int(polygon.Parts[i+1])
+		}
+
+		points := make([]LatLon, 0, end-start)
+		for _, point := range polygon.Points[start:end] {
+			points = append(points, LatLon{Lat: point.Y, Lon: point.X})
+		}
+		if len(points) > 1 {
+			rings = append(rings, points)
+		}
+	}
+
+	return rings
+}
+
+// findField returns the index of the first attribute field matching one of
+// the given names (case-insensitive), or -1 if none exist
+func findField(fields []shp.Field, names ...string) int {
+	for i, field := range fields {
+		fieldName := strings.TrimRight(string(field.Name[:]), "\x00 ")
+		for _, name := range names {
+			if strings.EqualFold(fieldName, name) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
 // FilterByBounds filters features to only those within or intersecting the given bounds
 func FilterByBounds(features []*Feature, bounds *Bounds) []*Feature {
 	filtered := make([]*Feature, 0)
@@ -294,7 +568,7 @@ func NewBounds(centerLat, centerLon, radiusMiles float64) *Bounds {
 	// 1 degree latitude ≈ 69 miles
 	// 1 degree longitude ≈ 69 * cos(latitude) miles
 	latDegrees := radiusMiles / 69.0
-	lonDegrees := radiusMiles / (69.0 * math.Cos(centerLat*math.Pi/180.0))
+	lonDegrees := math.Min(radiusMiles/milesPerDegreeLonAt(centerLat), 180)
 
 	return &Bounds{
 		MinLat: centerLat - latDegrees,
@@ -305,7 +579,17 @@ func NewBounds(centerLat, centerLon, radiusMiles float64) *Bounds {
 }
 
 // Contains checks if a point is within the bounds
+// Bounds that extend past ±180° longitude wrap around the antimeridian
 func (b *Bounds) Contains(lat, lon float64) bool {
-	return lat >= b.MinLat && lat <= b.MaxLat &&
-		lon >= b.MinLon && lon <= b.MaxLon
+	if lat < b.MinLat || lat > b.MaxLat {
+		return false
+	}
+
+	lon = NormalizeLon(lon)
+	for _, l := range []float64{lon, lon - 360, lon + 360} {
+		if l >= b.MinLon && l <= b.MaxLon {
+			return true
+		}
+	}
+	return false
 }
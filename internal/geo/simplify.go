@@ -0,0 +1,94 @@
+package geo
+
+import "math"
+
+// Simplify reduces the number of points in a polyline using the
+// Douglas-Peucker algorithm. tolerance is the maximum allowed deviation
+// in degrees; points closer than this to the simplified line are dropped.
+// The first and last points are always kept.
+func Simplify(points []LatLon, tolerance float64) []LatLon {
+	if len(points) < 3 || tolerance <= 0 {
+		return points
+	}
+
+	keep := make([]bool, len(points))
+	keep[0] = true
+	keep[len(points)-1] = true
+
+	// Iterative stack of [start, end] ranges to avoid deep recursion
+	// on long coastlines
+	stack := [][2]int{{0, len(points) - 1}}
+	for len(stack) > 0 {
+		span := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		start, end := span[0], span[1]
+		maxDist := 0.0
+		maxIdx := -1
+		for i := start + 1; i < end; i++ {
+			d := perpendicularDistance(points[i], points[start], points[end])
+			if d > maxDist {
+				maxDist = d
+				maxIdx = i
+			}
+		}
+
+		if maxIdx >= 0 && maxDist > tolerance {
+			keep[maxIdx] = true
+			stack = append(stack, [2]int{start, maxIdx}, [2]int{maxIdx, end})
+		}
+	}
+
+	simplified := make([]LatLon, 0, len(points)/2+2)
+	for i, p := range points {
+		if keep[i] {
+			simplified = append(simplified, p)
+		}
+	}
+
+	return simplified
+}
+
+// SimplifyFeatures returns copies of the given line features simplified to
+// tolerance. Point features are returned unchanged.
+func SimplifyFeatures(features []*Feature, tolerance float64) []*Feature {
+	simplified := make([]*Feature, 0, len(features))
+
+	for _, feature := range features {
+		if !feature.IsLine() {
+			simplified = append(simplified, feature)
+			continue
+		}
+
+		points := Simplify(feature.Points, tolerance)
+		simplified = append(simplified, &Feature{
+			Type:       feature.Type,
+			Points:     points,
+			Point:      feature.Point,
+			Name:       feature.Name,
+			Properties: feature.Properties,
+		})
+	}
+
+	return simplified
+}
+
+// perpendicularDistance returns the distance in degrees from p to the
+// segment a-b
+func perpendicularDistance(p, a, b LatLon) float64 {
+	dx := b.Lon - a.Lon
+	dy := b.Lat - a.Lat
+
+	if dx == 0 && dy == 0 {
+		return math.Hypot(p.Lon-a.Lon, p.Lat-a.Lat)
+	}
+
+	t := ((p.Lon-a.Lon)*dx + (p.Lat-a.Lat)*dy) / (dx*dx + dy*dy)
+	if t < 0 {
+		t = 0
+	} else if t > 1 {
+		t = 1
+	}
+
+	return math.Hypot(p.Lon-(a.Lon+t*dx), p.Lat-(a.Lat+t*dy))
+}
@@ -0,0 +1,392 @@
+package geo
+
+import (
+	"encoding/gob"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FeatureTilesDir holds the tiled line features next to the feature cache,
+// one directory per cache key
+const FeatureTilesDir = "feature-tiles"
+
+// keptTileSets is how many cache keys' tiles are kept, so a second
+// instance run with other settings doesn't delete the tiles this one reads
+const keptTileSets = 2
+
+// featureTileDegrees is the size of the grid features are tiled on
+const featureTileDegrees = 10
+
+// featureOverhead estimates the bytes a feature takes besides its points:
+// the struct, its Properties map and slice headers
+const featureOverhead = 200
+
+// tiledTypes are the line layers kept in tiles on disk rather than in
+// memory: the big shapefiles. Points, runways and user data stay loaded.
+var tiledTypes = []FeatureType{
+	FeatureStateBorder,
+	FeatureHighway,
+	FeatureRiver,
+	FeatureCoastline,
+	FeatureAirspace,
+	FeatureTimeZone,
+}
+
+// featureTile is one tile file, the extent of the features in it, which
+// may reach past its grid cell, and their estimated size in memory
+type featureTile struct {
+	Name   string
+	Extent Bounds
+	Size   int64
+}
+
+// loadedTile is a tile's features in memory
+type loadedTile struct {
+	features map[FeatureType][]*Feature
+	used     uint64 // Load the tile was last around the view in
+}
+
+// FeatureTiles loads the tiled line features around the map on demand, so
+// continental datasets don't all live in memory for a small radius
+type FeatureTiles struct {
+	dir   string
+	tiles []featureTile
+	limit int64 // Bytes of tiles kept loaded, 0 for no limit
+
+	mu      sync.Mutex
+	loaded  map[string]*loadedTile // By tile name
+	region  *Bounds                // Region the loaded tiles cover, nil before the first load
+	current []string               // Tiles in region, as last returned by Load
+	loads   uint64
+}
+
+// SetMemoryLimit sets roughly how many bytes of tiles may stay loaded, 0
+// for no limit. Without a limit only the tiles around the view are kept;
+// with one, tiles that scroll off stay loaded for panning back until the
+// limit is reached, then the least recently seen are unloaded.
+func (t *FeatureTiles) SetMemoryLimit(bytes int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.limit = bytes
+}
+
+// Covers reports whether the loaded tiles hold every feature in view
+func (t *FeatureTiles) Covers(view *Bounds) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.region != nil &&
+		view.MinLat >= t.region.MinLat && view.MaxLat <= t.region.MaxLat &&
+		view.MinLon >= t.region.MinLon && view.MaxLon <= t.region.MaxLon
+}
+
+// Load reads the tiles with features within three times the view each
+// way, or a smaller margin when those won't fit the memory limit, and
+// unloads the rest as the limit requires. It returns the line features
+// around the view by type, or nil when the tiles around it are the same
+// as last time.
+func (t *FeatureTiles) Load(view *Bounds) (map[FeatureType][]*Feature, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.loaded == nil {
+		t.loaded = make(map[string]*loadedTile)
+	}
+	var region *Bounds
+	var wanted []featureTile
+	for _, margin := range []float64{1, 0.5, 0} {
+		region = view.Expand(margin)
+		wanted = t.tilesIn(region)
+		if t.limit <= 0 || tilesSize(wanted) <= t.limit {
+			break
+		}
+	}
+	if t.limit > 0 && tilesSize(wanted) > t.limit {
+		log.Warnf("Feature tiles in view take %d MB, over the %d MB limit", tilesSize(wanted)>>20, t.limit>>20)
+	}
+
+	t.loads++
+	names := make([]string, 0, len(wanted))
+	for _, tile := range wanted {
+		loaded, ok := t.loaded[tile.Name]
+		if !ok {
+			features, err := readFeatureTile(filepath.Join(t.dir, tile.Name))
+			if err != nil {
+				return nil, err
+			}
+			loaded = &loadedTile{features: features}
+			t.loaded[tile.Name] = loaded
+		}
+		loaded.used = t.loads
+		names = append(names, tile.Name)
+	}
+	t.region = region
+	t.evict()
+	log.Infof("Feature tiles: %d of %d loaded, %d MB, for %.1f,%.1f to %.1f,%.1f",
+		len(t.loaded), len(t.tiles), t.size()>>20, region.MinLat, region.MinLon, region.MaxLat, region.MaxLon)
+
+	if slices.Equal(names, t.current) {
+		return nil, nil
+	}
+	t.current = names
+	merged := make(map[FeatureType][]*Feature, len(tiledTypes))
+	for _, ftype := range tiledTypes {
+		merged[ftype] = []*Feature{}
+	}
+	for _, name := range names {
+		for ftype, list := range t.loaded[name].features {
+			merged[ftype] = append(merged[ftype], list...)
+		}
+	}
+	return merged, nil
+}
+
+// tilesIn returns the tiles with features in region
+func (t *FeatureTiles) tilesIn(region *Bounds) []featureTile {
+	var tiles []featureTile
+	for _, tile := range t.tiles {
+		if tile.Extent.Intersects(region) {
+			tiles = append(tiles, tile)
+		}
+	}
+	return tiles
+}
+
+// evict unloads the tiles outside the loaded region: all of them without
+// a memory limit, otherwise the least recently seen until the loaded
+// tiles fit the limit
+func (t *FeatureTiles) evict() {
+	var away []featureTile
+	for _, tile := range t.tiles {
+		if loaded, ok := t.loaded[tile.Name]; ok && loaded.used != t.loads {
+			away = append(away, tile)
+		}
+	}
+	sort.Slice(away, func(i, j int) bool {
+		return t.loaded[away[i].Name].used < t.loaded[away[j].Name].used
+	})
+
+	size := t.size()
+	for _, tile := range away {
+		if t.limit > 0 && size <= t.limit {
+			return
+		}
+		size -= tile.Size
+		delete(t.loaded, tile.Name)
+	}
+}
+
+// size returns the estimated bytes of the loaded tiles
+func (t *FeatureTiles) size() int64 {
+	var size int64
+	for _, tile := range t.tiles {
+		if _, ok := t.loaded[tile.Name]; ok {
+			size += tile.Size
+		}
+	}
+	return size
+}
+
+// tilesSize returns the estimated bytes of tiles
+func tilesSize(tiles []featureTile) int64 {
+	var size int64
+	for _, tile := range tiles {
+		size += tile.Size
+	}
+	return size
+}
+
+// tileSize estimates the bytes a tile's features take in memory
+func tileSize(features map[FeatureType][]*Feature) int64 {
+	var size int64
+	for _, list := range features {
+		for _, feature := range list {
+			size += featureOverhead + int64(len(feature.Points))*16
+		}
+	}
+	return size
+}
+
+// Intersects reports whether two bounds overlap, allowing either to extend
+// past ±180° longitude
+func (b *Bounds) Intersects(other *Bounds) bool {
+	if b.MaxLat < other.MinLat || b.MinLat > other.MaxLat {
+		return false
+	}
+	for _, shift := range []float64{0, -360, 360} {
+		if b.MaxLon+shift >= other.MinLon && b.MinLon+shift <= other.MaxLon {
+			return true
+		}
+	}
+	return false
+}
+
+// Expand returns the bounds grown by margin times their own height and
+// width on every side, clamped at the poles; a margin of 1 makes them
+// three times as large each way
+func (b *Bounds) Expand(margin float64) *Bounds {
+	height, width := (b.MaxLat-b.MinLat)*margin, (b.MaxLon-b.MinLon)*margin
+	return &Bounds{
+		MinLat: math.Max(b.MinLat-height, -90),
+		MaxLat: math.Min(b.MaxLat+height, 90),
+		MinLon: b.MinLon - width,
+		MaxLon: b.MaxLon + width,
+	}
+}
+
+// featureExtent returns the bounding box of a line feature
+func featureExtent(feature *Feature) Bounds {
+	extent := Bounds{MinLat: 90, MaxLat: -90, MinLon: 180, MaxLon: -180}
+	for _, point := range feature.Points {
+		extent.MinLat = math.Min(extent.MinLat, point.Lat)
+		extent.MaxLat = math.Max(extent.MaxLat, point.Lat)
+		extent.MinLon = math.Min(extent.MinLon, point.Lon)
+		extent.MaxLon = math.Max(extent.MaxLon, point.Lon)
+	}
+	return extent
+}
+
+// splitTiled separates the tiled line layers from the features kept in
+// memory
+func splitTiled(features map[FeatureType][]*Feature) (tiled, rest map[FeatureType][]*Feature) {
+	tiled = make(map[FeatureType][]*Feature)
+	rest = make(map[FeatureType][]*Feature)
+	for ftype, list := range features {
+		rest[ftype] = list
+	}
+	for _, ftype := range tiledTypes {
+		if list, ok := rest[ftype]; ok {
+			tiled[ftype] = list
+			delete(rest, ftype)
+		}
+	}
+	return tiled, rest
+}
+
+// writeFeatureTiles replaces the tiles in dir with the features, each in
+// the grid cell holding the center of its bounding box. The tiles are
+// written to a temporary directory renamed into place, so a reader never
+// sees a partial set, then all but the newest keptTileSets are removed.
+func writeFeatureTiles(dir string, features map[FeatureType][]*Feature) ([]featureTile, error) {
+	parent := filepath.Dir(dir)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return nil, err
+	}
+	tmp, err := os.MkdirTemp(parent, ".tiles-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	tiles, err := writeTileFiles(tmp, features)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(dir); err == nil {
+		old := tmp + ".old"
+		if err := os.Rename(dir, old); err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(old)
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		return nil, err
+	}
+	pruneFeatureTiles(parent)
+	return tiles, nil
+}
+
+// pruneFeatureTiles removes all but the newest keptTileSets tile
+// directories
+func pruneFeatureTiles(parent string) {
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		return
+	}
+	type tileSet struct {
+		path     string
+		modified int64
+	}
+	var sets []tileSet
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		sets = append(sets, tileSet{filepath.Join(parent, entry.Name()), info.ModTime().UnixNano()})
+	}
+	sort.Slice(sets, func(i, j int) bool {
+		return sets[i].modified > sets[j].modified
+	})
+	for _, set := range sets[min(len(sets), keptTileSets):] {
+		log.Infof("Removing old feature tiles %s", filepath.Base(set.path))
+		os.RemoveAll(set.path)
+	}
+}
+
+// writeTileFiles writes the features into tile files in dir
+func writeTileFiles(dir string, features map[FeatureType][]*Feature) ([]featureTile, error) {
+	type cell struct{ lat, lon int }
+	grouped := make(map[cell]map[FeatureType][]*Feature)
+	extents := make(map[cell]Bounds)
+	for ftype, list := range features {
+		for _, feature := range list {
+			extent := featureExtent(feature)
+			key := cell{
+				int(math.Floor((extent.MinLat + extent.MaxLat) / 2 / featureTileDegrees)),
+				int(math.Floor((extent.MinLon + extent.MaxLon) / 2 / featureTileDegrees)),
+			}
+			if grouped[key] == nil {
+				grouped[key] = make(map[FeatureType][]*Feature)
+				extents[key] = extent
+			}
+			grouped[key][ftype] = append(grouped[key][ftype], feature)
+
+			union := extents[key]
+			union.MinLat = math.Min(union.MinLat, extent.MinLat)
+			union.MaxLat = math.Max(union.MaxLat, extent.MaxLat)
+			union.MinLon = math.Min(union.MinLon, extent.MinLon)
+			union.MaxLon = math.Max(union.MaxLon, extent.MaxLon)
+			extents[key] = union
+		}
+	}
+
+	tiles := make([]featureTile, 0, len(grouped))
+	for key, tileFeatures := range grouped {
+		name := fmt.Sprintf("%d_%d.gob", key.lat*featureTileDegrees, key.lon*featureTileDegrees)
+		file, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		err = gob.NewEncoder(file).Encode(tileFeatures)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, err
+		}
+		tiles = append(tiles, featureTile{Name: name, Extent: extents[key], Size: tileSize(tileFeatures)})
+	}
+	return tiles, nil
+}
+
+// readFeatureTile decodes one tile file
+func readFeatureTile(path string) (map[FeatureType][]*Feature, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open feature tile: %w", err)
+	}
+	defer file.Close()
+
+	var features map[FeatureType][]*Feature
+	if err := gob.NewDecoder(file).Decode(&features); err != nil {
+		return nil, fmt.Errorf("failed to decode feature tile %s: %w", filepath.Base(path), err)
+	}
+	fillProperties(features)
+	return features, nil
+}
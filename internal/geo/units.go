@@ -0,0 +1,175 @@
+package geo
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Units selects how distances, speeds and altitudes are shown and entered
+// Positions and speeds are kept internally in statute miles, knots and feet
+// as received; Units only converts at the edges.
+type Units int
+
+const (
+	UnitsAviation Units = iota // Nautical miles, knots, feet
+	UnitsImperial              // Statute miles, mph, feet
+	UnitsMetric                // Kilometers, km/h, meters
+)
+
+// Conversion factors from the internal units
+const (
+	KilometersPerMile = 1.609344
+	MphPerKnot        = 1.150779
+	KmhPerKnot        = 1.852
+	MetersPerFoot     = 0.3048
+)
+
+// String returns the name of the units setting
+func (u Units) String() string {
+	switch u {
+	case UnitsImperial:
+		return "imperial"
+	case UnitsMetric:
+		return "metric"
+	default:
+		return "aviation"
+	}
+}
+
+// ParseUnits parses "aviation", "imperial" or "metric"
+func ParseUnits(s string) (Units, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "aviation", "nautical":
+		return UnitsAviation, nil
+	case "imperial", "statute", "":
+		return UnitsImperial, nil
+	case "metric":
+		return UnitsMetric, nil
+	default:
+		return UnitsAviation, fmt.Errorf("unknown units %q (use aviation, imperial or metric)", s)
+	}
+}
+
+// DistanceUnit returns the distance abbreviation: "nm", "mi" or "km"
+func (u Units) DistanceUnit() string {
+	switch u {
+	case UnitsImperial:
+		return "mi"
+	case UnitsMetric:
+		return "km"
+	default:
+		return "nm"
+	}
+}
+
+// FromMiles converts statute miles to the distance unit
+func (u Units) FromMiles(miles float64) float64 {
+	switch u {
+	case UnitsImperial:
+		return miles
+	case UnitsMetric:
+		return miles * KilometersPerMile
+	default:
+		return miles * NauticalMilesPerMile
+	}
+}
+
+// ToMiles converts a distance in the distance unit to statute miles
+func (u Units) ToMiles(distance float64) float64 {
+	return distance / u.FromMiles(1)
+}
+
+// ParseDistance parses a distance such as "80", "80nm", "120 km" or
+// "50mi" into statute miles; a bare number is in the distance unit
+func (u Units) ParseDistance(s string) (float64, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	number, unit := s, u
+	for _, candidate := range []Units{UnitsAviation, UnitsImperial, UnitsMetric} {
+		if trimmed, ok := strings.CutSuffix(s, candidate.DistanceUnit()); ok {
+			number, unit = strings.TrimSpace(trimmed), candidate
+			break
+		}
+	}
+
+	distance, err := strconv.ParseFloat(number, 64)
+	if err != nil || distance <= 0 || math.IsInf(distance, 0) {
+		return 0, fmt.Errorf("invalid distance %q (e.g. 80, 80nm, 150km or 90mi)", s)
+	}
+	return unit.ToMiles(distance), nil
+}
+
+// FormatDistance formats statute miles in the distance unit, e.g. "23 nm",
+// with one decimal below 10
+func (u Units) FormatDistance(miles float64) string {
+	d := u.FromMiles(miles)
+	if d < 10 {
+		return fmt.Sprintf("%.1f %s", d, u.DistanceUnit())
+	}
+	return fmt.Sprintf("%.0f %s", d, u.DistanceUnit())
+}
+
+// SpeedUnit returns the speed abbreviation: "kts", "mph" or "km/h"
+func (u Units) SpeedUnit() string {
+	switch u {
+	case UnitsImperial:
+		return "mph"
+	case UnitsMetric:
+		return "km/h"
+	default:
+		return "kts"
+	}
+}
+
+// Speed converts knots to the speed unit
+func (u Units) Speed(knots int) int {
+	switch u {
+	case UnitsImperial:
+		return int(math.Round(float64(knots) * MphPerKnot))
+	case UnitsMetric:
+		return int(math.Round(float64(knots) * KmhPerKnot))
+	default:
+		return knots
+	}
+}
+
+// AltitudeUnit returns the altitude abbreviation: "ft" or "m"
+func (u Units) AltitudeUnit() string {
+	if u == UnitsMetric {
+		return "m"
+	}
+	return "ft"
+}
+
+// Altitude converts feet to the altitude unit
+func (u Units) Altitude(feet int) int {
+	if u == UnitsMetric {
+		return int(math.Round(float64(feet) * MetersPerFoot))
+	}
+	return feet
+}
+
+// ToFeet converts an altitude in the altitude unit to feet
+func (u Units) ToFeet(altitude int) int {
+	if u == UnitsMetric {
+		return int(math.Round(float64(altitude) / MetersPerFoot))
+	}
+	return altitude
+}
+
+// VerticalRateUnit returns the climb rate abbreviation: "ft/min" or "m/s"
+func (u Units) VerticalRateUnit() string {
+	if u == UnitsMetric {
+		return "m/s"
+	}
+	return "ft/min"
+}
+
+// VerticalRate converts feet per minute to the climb rate unit
+func (u Units) VerticalRate(fpm int) int {
+	if u == UnitsMetric {
+		return int(math.Round(float64(fpm) * MetersPerFoot / 60))
+	}
+	return fpm
+}
@@ -0,0 +1,78 @@
+package geo
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// WaypointLoader loads user-defined waypoints from a simple CSV file
+// Each line is: name,lat,lon[,symbol] - blank lines and lines starting
+// with # are ignored
+type WaypointLoader struct {
+	csvPath string
+}
+
+// NewWaypointLoader creates a new waypoint loader
+func NewWaypointLoader(csvPath string) *WaypointLoader {
+	return &WaypointLoader{
+		csvPath: csvPath,
+	}
+}
+
+// LoadWaypoints loads waypoints from the CSV file
+// Returns a slice of Feature objects representing waypoints
+func (w *WaypointLoader) LoadWaypoints() ([]*Feature, error) {
+	file, err := os.Open(w.csvPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open waypoints file: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var waypoints []*Feature
+	line := 0
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("waypoints line %d: %w", line, err)
+		}
+
+		if len(record) < 3 {
+			return nil, fmt.Errorf("waypoints line %d: expected name,lat,lon[,symbol]", line)
+		}
+
+		lat, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("waypoints line %d: invalid latitude %q", line, record[1])
+		}
+
+		lon, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("waypoints line %d: invalid longitude %q", line, record[2])
+		}
+
+		waypoint := NewPointFeature(FeatureWaypoint, LatLon{Lat: lat, Lon: lon}, strings.TrimSpace(record[0]))
+		if len(record) > 3 {
+			if symbol := strings.TrimSpace(record[3]); symbol != "" {
+				waypoint.Properties["symbol"] = symbol
+			}
+		}
+
+		waypoints = append(waypoints, waypoint)
+	}
+
+	return waypoints, nil
+}
@@ -0,0 +1,86 @@
+package render
+
+import (
+	"github.com/gdamore/tcell/v2"
+)
+
+// asciiFallback maps every non-ASCII glyph ascii1090 draws to a 7-bit
+// stand-in for terminals without working Unicode
+var asciiFallback = map[rune]rune{
+	// Box drawing for panel borders
+	'┌': '+', '┐': '+', '└': '+', '┘': '+', '─': '-', '│': '|',
+	'┼': '+',
+	// Markers and dots
+	'·': '.', '•': 'o', '●': 'o', '↕': '*', '°': '*', '▲': '^', '▼': 'v',
+	// Navaids
+	'⊙': 'O', '○': 'o', '□': 'D',
+	// Wind arrows and the plane symbol set
+	'✈': '>',
+	'↓': 'v', '↙': '/', '←': '<', '↖': '\\', '↑': '^', '↗': '/', '→': '>', '↘': '\\',
+	// Block elements, in case a block render mode slips through
+	'█': '#', '▀': '"', '▄': '_', '▌': '[', '▐': ']',
+	// Coverage shading
+	'░': '.', '▒': ':', '▓': '%',
+	// Sparkline levels
+	'▁': '_', '▂': '_', '▃': '-', '▅': '=', '▆': '=', '▇': '#',
+}
+
+// asciiAircraft replaces the box-corner diagonals used for aircraft
+// headings; as panel corners they would all become '+'
+var asciiAircraft = map[rune]rune{
+	'┐': '/',  // Northeast
+	'┘': '\\', // Southeast
+	'└': '/',  // Southwest
+	'┌': '\\', // Northwest
+}
+
+// latinFold strips accents from common Latin-1 letters so place names
+// stay readable in ASCII mode
+var latinFold = map[rune]rune{}
+
+func init() {
+	folds := []struct {
+		from string
+		to   rune
+	}{
+		{"ÀÁÂÃÄÅ", 'A'}, {"àáâãäå", 'a'}, {"Ç", 'C'}, {"ç", 'c'},
+		{"ÈÉÊË", 'E'}, {"èéêë", 'e'}, {"ÌÍÎÏ", 'I'}, {"ìíîï", 'i'},
+		{"Ñ", 'N'}, {"ñ", 'n'}, {"ÒÓÔÕÖØ", 'O'}, {"òóôõöø", 'o'},
+		{"ÙÚÛÜ", 'U'}, {"ùúûü", 'u'}, {"Ý", 'Y'}, {"ýÿ", 'y'},
+	}
+	for _, fold := range folds {
+		for _, r := range fold.from {
+			latinFold[r] = fold.to
+		}
+	}
+}
+
+// ToASCII returns a 7-bit replacement for r ('?' if there is no sensible one)
+func ToASCII(r rune) rune {
+	if r < 0x80 {
+		return r
+	}
+	if ascii, ok := asciiFallback[r]; ok {
+		return ascii
+	}
+	if ascii, ok := latinFold[r]; ok {
+		return ascii
+	}
+	return '?'
+}
+
+// asciiScreen wraps a tcell screen so everything drawn is 7-bit ASCII
+type asciiScreen struct {
+	tcell.Screen
+}
+
+// NewASCIIScreen returns a screen that replaces Unicode glyphs with ASCII
+// equivalents as they are drawn, for serial consoles and broken locales
+func NewASCIIScreen(screen tcell.Screen) tcell.Screen {
+	return &asciiScreen{Screen: screen}
+}
+
+// SetContent draws the ASCII equivalent of a rune, dropping combining marks
+func (s *asciiScreen) SetContent(x, y int, primary rune, combining []rune, style tcell.Style) {
+	s.Screen.SetContent(x, y, ToASCII(primary), nil, style)
+}
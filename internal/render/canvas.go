@@ -2,6 +2,7 @@ package render
 
 import (
 	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
 )
 
 // Canvas represents a 2D grid of cells for ASCII rendering
@@ -9,6 +10,15 @@ type Canvas struct {
 	width  int
 	height int
 	cells  [][]Cell
+
+	// Cells written while tracking is on, so they can be restored from a
+	// cached base layer next frame instead of redrawing everything
+	tracking bool
+	marked   []bool
+	touched  []int
+
+	// What the last Blit sent to the screen, so unchanged cells are skipped
+	blitted []Cell
 }
 
 // Cell represents a single character cell with style
@@ -17,6 +27,27 @@ type Cell struct {
 	Style tcell.Style
 }
 
+// wideTail fills the cell to the right of a double-width character, which
+// the terminal draws across both cells
+const wideTail rune = -2
+
+// RuneWidth returns how many cells a rune occupies (1 or 2)
+func RuneWidth(r rune) int {
+	if runewidth.RuneWidth(r) == 2 {
+		return 2
+	}
+	return 1
+}
+
+// TextWidth returns how many cells a string occupies when drawn
+func TextWidth(text string) int {
+	width := 0
+	for _, r := range text {
+		width += RuneWidth(r)
+	}
+	return width
+}
+
 // NewCanvas creates a new blank canvas
 func NewCanvas(width, height int) *Canvas {
 	cells := make([][]Cell, height)
@@ -35,19 +66,95 @@ func NewCanvas(width, height int) *Canvas {
 		width:  width,
 		height: height,
 		cells:  cells,
+		marked: make([]bool, width*height),
 	}
 }
 
 // Set sets the character and style at the given position
 // Coordinates are 0-indexed with (0,0) at top-left
+// A double-width character also claims the cell to its right; one that
+// would hang off the right edge is drawn as a space. Overwriting half of
+// an existing double-width character blanks the other half.
 func (c *Canvas) Set(x, y int, char rune, style tcell.Style) {
-	if x >= 0 && x < c.width && y >= 0 && y < c.height {
-		c.cells[y][x] = Cell{Char: char, Style: style}
+	if x < 0 || x >= c.width || y < 0 || y >= c.height {
+		return
+	}
+
+	if RuneWidth(char) == 2 {
+		if x+1 >= c.width {
+			c.put(x, y, ' ', style)
+			return
+		}
+		c.put(x, y, char, style)
+		c.put(x+1, y, wideTail, style)
+		return
+	}
+	c.put(x, y, char, style)
+}
+
+// put writes one cell, first breaking up any double-width character it
+// would split
+func (c *Canvas) put(x, y int, char rune, style tcell.Style) {
+	row := c.cells[y]
+	if row[x].Char == wideTail && x > 0 && char != wideTail {
+		c.write(x-1, y, Cell{Char: ' ', Style: row[x-1].Style})
+	}
+	if x+1 < c.width && row[x+1].Char == wideTail && RuneWidth(row[x].Char) == 2 {
+		c.write(x+1, y, Cell{Char: ' ', Style: row[x+1].Style})
+	}
+	c.write(x, y, Cell{Char: char, Style: style})
+}
+
+// write stores a cell, recording it while tracking is on
+func (c *Canvas) write(x, y int, cell Cell) {
+	c.cells[y][x] = cell
+	if c.tracking {
+		if i := y*c.width + x; !c.marked[i] {
+			c.marked[i] = true
+			c.touched = append(c.touched, i)
+		}
+	}
+}
+
+// Track turns recording of written cells on or off
+func (c *Canvas) Track(on bool) {
+	c.tracking = on
+}
+
+// RestoreTouched copies every cell written while tracking back from base
+// (which must be the same size) and forgets them
+func (c *Canvas) RestoreTouched(base *Canvas) {
+	for _, i := range c.touched {
+		x, y := i%c.width, i/c.width
+		c.cells[y][x] = base.cells[y][x]
+		c.marked[i] = false
+	}
+	c.touched = c.touched[:0]
+}
+
+// CopyFrom copies every cell from another canvas of the same size and
+// forgets any tracked cells
+func (c *Canvas) CopyFrom(src *Canvas) {
+	for y := 0; y < c.height && y < src.height; y++ {
+		copy(c.cells[y], src.cells[y])
+	}
+	for _, i := range c.touched {
+		c.marked[i] = false
 	}
+	c.touched = c.touched[:0]
+}
+
+// TouchedCount returns how many cells were written while tracking
+func (c *Canvas) TouchedCount() int {
+	return len(c.touched)
 }
 
 // Get retrieves the cell at the given position
+// The right half of a double-width character reads as a space
 func (c *Canvas) Get(x, y int) Cell {
+	if x >= 0 && x < c.width && y >= 0 && y < c.height && c.cells[y][x].Char == wideTail {
+		return Cell{Char: ' ', Style: c.cells[y][x].Style}
+	}
 	if x >= 0 && x < c.width && y >= 0 && y < c.height {
 		return c.cells[y][x]
 	}
@@ -72,10 +179,12 @@ func (c *Canvas) ClearRegion(x, y, width, height int) {
 	}
 }
 
-// DrawText draws a string at the given position
+// DrawText draws a string at the given position, advancing two cells for
+// each double-width character
 func (c *Canvas) DrawText(x, y int, text string, style tcell.Style) {
-	for i, char := range text {
-		c.Set(x+i, y, char, style)
+	for _, char := range text {
+		c.Set(x, y, char, style)
+		x += RuneWidth(char)
 	}
 }
 
@@ -124,11 +233,51 @@ func (c *Canvas) Height() int {
 }
 
 // Blit renders the canvas to a tcell screen
+// Only cells that differ from the previous Blit are sent, so the screen
+// must not be cleared between frames; anything else drawn over the
+// canvas area has to be reported with InvalidateRegion.
 func (c *Canvas) Blit(screen tcell.Screen, offsetX, offsetY int) {
+	full := len(c.blitted) != c.width*c.height
+	if full {
+		c.blitted = make([]Cell, c.width*c.height)
+	}
+
 	for y := 0; y < c.height; y++ {
-		for x := 0; x < c.width; x++ {
-			cell := c.cells[y][x]
+		row := c.blitted[y*c.width : (y+1)*c.width]
+		for x, cell := range c.cells[y] {
+			if !full && row[x] == cell {
+				continue
+			}
+			row[x] = cell
+
+			if cell.Char == wideTail {
+				// The terminal draws the right half from the character to
+				// the left, so resend that instead
+				if x > 0 {
+					head := c.cells[y][x-1]
+					screen.SetContent(offsetX+x-1, offsetY+y, head.Char, nil, head.Style)
+				}
+				continue
+			}
 			screen.SetContent(offsetX+x, offsetY+y, cell.Char, nil, cell.Style)
 		}
 	}
 }
+
+// InvalidateRegion marks a rectangle as overdrawn on screen, so the next
+// Blit resends it even if the canvas cells haven't changed
+func (c *Canvas) InvalidateRegion(x, y, width, height int) {
+	if len(c.blitted) != c.width*c.height {
+		return
+	}
+	for row := max(y, 0); row < min(y+height, c.height); row++ {
+		for col := max(x, 0); col < min(x+width, c.width); col++ {
+			c.blitted[row*c.width+col] = Cell{Char: -1}
+		}
+	}
+}
+
+// InvalidateAll makes the next Blit resend every cell
+func (c *Canvas) InvalidateAll() {
+	c.blitted = nil
+}
@@ -0,0 +1,40 @@
+package render
+
+import (
+	"ascii1090/internal/geo"
+	"math"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// StyleCoverage is the coverage heat layer's shading
+var StyleCoverage = tcell.StyleDefault.Foreground(tcell.ColorSlateBlue)
+
+// coverageShades are the heat layer's glyphs, lightest first
+var coverageShades = []rune{'░', '▒', '▓'}
+
+// RenderCoverage shades the empty map cells where positions have been
+// received, darker where more were, so the receiver's footprint shows
+// behind the aircraft. Density is on a log scale, since cells near the
+// receiver see hundreds of times more traffic than the fringes.
+func (m *MapRenderer) RenderCoverage(grid *geo.CoverageGrid) {
+	if grid == nil || !m.layers.Visible(LayerCoverage) || grid.Max() == 0 {
+		return
+	}
+
+	top := math.Log1p(float64(grid.Max()))
+	for y := 0; y < m.canvas.Height(); y++ {
+		for x := 0; x < m.canvas.Width(); x++ {
+			if m.canvas.Get(x, y).Char != ' ' {
+				continue
+			}
+			lat, lon := m.projection.Unproject(x, y)
+			positions := grid.Positions(lat, lon)
+			if positions == 0 {
+				continue
+			}
+			level := int(math.Log1p(float64(positions)) / top * float64(len(coverageShades)))
+			m.canvas.Set(x, y, coverageShades[min(level, len(coverageShades)-1)], StyleCoverage)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package render
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Fallback cell size used when the terminal doesn't report pixel dimensions
+const (
+	DefaultCellPixelWidth  = 8
+	DefaultCellPixelHeight = 16
+)
+
+// kittyImageID is the image id used for the map underlay so each frame
+// replaces the previous one
+const kittyImageID = 1090
+
+// kittyChunkSize is the largest base64 payload allowed per escape sequence
+const kittyChunkSize = 4096
+
+// GraphicsSequence returns the escape sequence that draws the current map
+// raster at the top-left of the screen, or "" if the mode isn't a graphics
+// mode or the image hasn't changed since the last call (unless force is set)
+func (m *MapRenderer) GraphicsSequence(force bool) string {
+	if !m.mode.IsGraphics() || m.raster == nil {
+		return ""
+	}
+
+	if !force && pixelsEqual(m.raster.pixels, m.sentPixels) {
+		return ""
+	}
+	m.sentPixels = append(m.sentPixels[:0], m.raster.pixels...)
+
+	var body string
+	switch m.mode {
+	case RenderModeSixel:
+		body = encodeSixel(m.raster)
+	case RenderModeKitty:
+		body = m.ClearGraphicsSequence() + encodeKitty(m.raster)
+	}
+
+	// Save cursor, home, draw, restore so tcell's idea of the cursor holds
+	return "\x1b7\x1b[1;1H" + body + "\x1b8"
+}
+
+// ClearGraphicsSequence returns the escape sequence that removes any image
+// left on screen by a graphics mode
+func (m *MapRenderer) ClearGraphicsSequence() string {
+	if m.mode == RenderModeKitty {
+		return fmt.Sprintf("\x1b_Ga=d,d=I,i=%d,q=2\x1b\\", kittyImageID)
+	}
+	return ""
+}
+
+// pixelsEqual compares two rasters
+func pixelsEqual(a, b []tcell.Color) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// rasterRGB returns a pixel color, drawing colorless (monochrome) lines white
+func rasterRGB(c tcell.Color) (int32, int32, int32) {
+	r, g, b := c.RGB()
+	if r < 0 {
+		return 0xff, 0xff, 0xff
+	}
+	return r, g, b
+}
+
+// encodeSixel encodes the raster as a sixel image with a transparent
+// background, so text under empty pixels stays visible
+func encodeSixel(b *subpixelBuffer) string {
+	// Map colors are a handful of named styles, so the palette is exact
+	palette := make(map[tcell.Color]int)
+	var colors []tcell.Color
+	for _, c := range b.pixels {
+		if c == tcell.ColorDefault {
+			continue
+		}
+		if _, ok := palette[c]; !ok && len(colors) < 256 {
+			palette[c] = len(colors)
+			colors = append(colors, c)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\x1bP0;1;0q")
+	fmt.Fprintf(&sb, "\"1;1;%d;%d", b.width, b.height)
+
+	for i, c := range colors {
+		r, g, bl := rasterRGB(c)
+		fmt.Fprintf(&sb, "#%d;2;%d;%d;%d", i, r*100/255, g*100/255, bl*100/255)
+	}
+
+	row := make([]byte, b.width)
+	for band := 0; band < b.height; band += 6 {
+		first := true
+		for i, c := range colors {
+			used := false
+			for x := 0; x < b.width; x++ {
+				var bits byte
+				for dy := 0; dy < 6; dy++ {
+					if b.get(x, band+dy) == c {
+						bits |= 1 << dy
+					}
+				}
+				row[x] = '?' + bits
+				used = used || bits != 0
+			}
+			if !used {
+				continue
+			}
+
+			if !first {
+				sb.WriteByte('$')
+			}
+			first = false
+
+			fmt.Fprintf(&sb, "#%d", i)
+			writeSixelRow(&sb, row)
+		}
+		sb.WriteByte('-')
+	}
+
+	sb.WriteString("\x1b\\")
+	return sb.String()
+}
+
+// writeSixelRow writes one band for one color with run-length encoding
+func writeSixelRow(sb *strings.Builder, row []byte) {
+	// Trailing empty sixels are implied
+	end := len(row)
+	for end > 0 && row[end-1] == '?' {
+		end--
+	}
+
+	for i := 0; i < end; {
+		j := i
+		for j < end && row[j] == row[i] {
+			j++
+		}
+		if n := j - i; n > 3 {
+			fmt.Fprintf(sb, "!%d%c", n, row[i])
+		} else {
+			for k := 0; k < n; k++ {
+				sb.WriteByte(row[i])
+			}
+		}
+		i = j
+	}
+}
+
+// encodeKitty encodes the raster as a PNG and places it beneath the text
+// layer using the Kitty graphics protocol
+func encodeKitty(b *subpixelBuffer) string {
+	img := image.NewNRGBA(image.Rect(0, 0, b.width, b.height))
+	for y := 0; y < b.height; y++ {
+		for x := 0; x < b.width; x++ {
+			c := b.get(x, y)
+			if c == tcell.ColorDefault {
+				continue
+			}
+			r, g, bl := rasterRGB(c)
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(r), G: uint8(g), B: uint8(bl), A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return ""
+	}
+	payload := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	var sb strings.Builder
+	for i := 0; i < len(payload); i += kittyChunkSize {
+		end := i + kittyChunkSize
+		more := 1
+		if end >= len(payload) {
+			end = len(payload)
+			more = 0
+		}
+
+		if i == 0 {
+			// z=-1 keeps the image under text; C=1 leaves the cursor alone
+			fmt.Fprintf(&sb, "\x1b_Ga=T,f=100,i=%d,q=2,C=1,z=-1,m=%d;", kittyImageID, more)
+		} else {
+			fmt.Fprintf(&sb, "\x1b_Gm=%d;", more)
+		}
+		sb.WriteString(payload[i:end])
+		sb.WriteString("\x1b\\")
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,179 @@
+package render
+
+import (
+	"ascii1090/internal/adsb"
+	"ascii1090/internal/geo"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// AirportLabelMode selects which identifier is drawn next to airports
+type AirportLabelMode int
+
+const (
+	AirportLabelIATA AirportLabelMode = iota // 3-letter IATA code (DFW), falling back to ICAO
+	AirportLabelICAO                         // ICAO ident (KDFW)
+	AirportLabelName                         // Full airport name
+)
+
+// String returns the flag/config spelling of the label mode
+func (l AirportLabelMode) String() string {
+	switch l {
+	case AirportLabelICAO:
+		return "icao"
+	case AirportLabelName:
+		return "name"
+	default:
+		return "iata"
+	}
+}
+
+// Next returns the label mode that follows l when cycling with a key
+func (l AirportLabelMode) Next() AirportLabelMode {
+	return (l + 1) % 3
+}
+
+// ParseAirportLabelMode parses "iata", "icao" or "name"
+func ParseAirportLabelMode(s string) (AirportLabelMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "iata", "":
+		return AirportLabelIATA, nil
+	case "icao", "ident":
+		return AirportLabelICAO, nil
+	case "name":
+		return AirportLabelName, nil
+	default:
+		return AirportLabelIATA, fmt.Errorf("unknown airport label mode %q (use iata, icao or name)", s)
+	}
+}
+
+// airportLabel returns the label for an airport feature in the given mode
+func airportLabel(airport *geo.Feature, mode AirportLabelMode) string {
+	ident, _ := airport.Properties["ident"].(string)
+	iata, _ := airport.Properties["iata"].(string)
+	name, _ := airport.Properties["full_name"].(string)
+
+	switch mode {
+	case AirportLabelICAO:
+		if ident != "" {
+			return ident
+		}
+	case AirportLabelName:
+		if name != "" {
+			return name
+		}
+	default:
+		if iata != "" {
+			return iata
+		}
+		if ident != "" {
+			return ident
+		}
+	}
+
+	return airport.Name
+}
+
+// labelGrid tracks which canvas cells are covered by labels drawn this
+// frame so lower-priority labels can be dropped instead of overwriting
+type labelGrid struct {
+	width    int
+	height   int
+	occupied []bool
+}
+
+// newLabelGrid creates an empty label grid for a canvas size
+func newLabelGrid(width, height int) *labelGrid {
+	return &labelGrid{
+		width:    width,
+		height:   height,
+		occupied: make([]bool, width*height),
+	}
+}
+
+// mark claims a single cell without padding or collision checks
+func (g *labelGrid) mark(x, y int) {
+	if x >= 0 && x < g.width && y >= 0 && y < g.height {
+		g.occupied[y*g.width+x] = true
+	}
+}
+
+// reserve claims a horizontal run of cells for a label, with one cell of
+// padding on either side. Returns false (claiming nothing) if any cell is
+// already taken or the label would run off the canvas.
+func (g *labelGrid) reserve(x, y, length int) bool {
+	if y < 0 || y >= g.height || x < 0 || x+length > g.width {
+		return false
+	}
+
+	start := max(x-1, 0)
+	end := min(x+length+1, g.width)
+	for col := start; col < end; col++ {
+		if g.occupied[y*g.width+col] {
+			return false
+		}
+	}
+
+	for col := start; col < end; col++ {
+		g.occupied[y*g.width+col] = true
+	}
+	return true
+}
+
+// AircraftLabelCellsPerLabel is the screen area (in cells) each visible
+// aircraft needs for labels to stay on; above that density the map is
+// too crowded and only the selected aircraft is labeled
+const AircraftLabelCellsPerLabel = 150
+
+// renderAircraftLabels tags aircraft with callsign and flight level,
+// right of the symbol if there's room, else left. Labels never cover
+// another aircraft or each other; map labels underneath are overwritten.
+func (m *MapRenderer) renderAircraftLabels(aircraft []*adsb.Aircraft, selectedICAO string) {
+	grid := newLabelGrid(m.canvas.Width(), m.canvas.Height())
+
+	type placed struct {
+		ac    *adsb.Aircraft
+		point geo.Point
+	}
+	var visible []placed
+	for _, ac := range aircraft {
+		if !ac.PositionLocked() || !m.projection.IsInBounds(*ac.Latitude, *ac.Longitude) {
+			continue
+		}
+		point := m.projection.Project(*ac.Latitude, *ac.Longitude)
+		grid.mark(point.X, point.Y)
+		visible = append(visible, placed{ac, point})
+	}
+
+	crowded := len(visible)*AircraftLabelCellsPerLabel > m.canvas.Width()*m.canvas.Height()
+
+	// The selected aircraft claims its spot first so it is always labeled
+	sort.SliceStable(visible, func(i, j int) bool {
+		return visible[i].ac.ICAO == selectedICAO && visible[j].ac.ICAO != selectedICAO
+	})
+
+	for _, v := range visible {
+		selected := v.ac.ICAO == selectedICAO
+		_, alerting := m.alerts[v.ac.ICAO]
+		if crowded && !selected && !alerting {
+			continue
+		}
+
+		label := v.ac.MapLabel()
+		style := StyleLabel
+		if selected {
+			style = StyleSelected
+		}
+		if kind, ok := m.alerts[v.ac.ICAO]; ok {
+			style = alertStyle(kind)
+		}
+
+		switch {
+		case grid.reserve(v.point.X+2, v.point.Y, TextWidth(label)):
+			m.canvas.DrawText(v.point.X+2, v.point.Y, label, style)
+		case grid.reserve(v.point.X-TextWidth(label)-2, v.point.Y, TextWidth(label)):
+			m.canvas.DrawText(v.point.X-TextWidth(label)-2, v.point.Y, label, style)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Layer is an independently toggleable part of the map
+type Layer int
+
+const (
+	LayerCoastlines Layer = iota
+	LayerRivers
+	LayerBorders
+	LayerHighways
+	LayerCities
+	LayerAirports
+	LayerAirspace
+	LayerNavaids
+	LayerTimeZones
+	LayerOverlays
+	LayerWaypoints
+	LayerTrails
+	LayerRings
+	LayerScale
+	LayerCoverage
+	numLayers
+)
+
+// layerNames are the flag spellings of each layer, indexed by Layer
+var layerNames = [numLayers]string{
+	"coastlines", "rivers", "borders", "highways", "cities", "airports",
+	"airspace", "navaids", "timezones", "overlays", "waypoints", "trails", "rings",
+	"scale", "coverage",
+}
+
+// String returns the flag spelling of the layer
+func (l Layer) String() string {
+	if l >= 0 && l < numLayers {
+		return layerNames[l]
+	}
+	return "unknown"
+}
+
+// AllLayers returns every layer in drawing order
+func AllLayers() []Layer {
+	layers := make([]Layer, numLayers)
+	for i := range layers {
+		layers[i] = Layer(i)
+	}
+	return layers
+}
+
+// ParseLayers parses a comma-separated list of layer names
+func ParseLayers(list string) ([]Layer, error) {
+	var layers []Layer
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		found := false
+		for i, layerName := range layerNames {
+			if name == layerName || name+"s" == layerName {
+				layers = append(layers, Layer(i))
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown layer %q (layers: %s)", name, strings.Join(layerNames[:], ", "))
+		}
+	}
+	return layers, nil
+}
+
+// LayerSet records which layers are visible
+type LayerSet [numLayers]bool
+
+// DefaultLayers returns the startup visibility: everything except time
+// zones, range rings and the coverage heat layer
+func DefaultLayers() LayerSet {
+	var set LayerSet
+	for i := range set {
+		set[i] = true
+	}
+	set[LayerTimeZones] = false
+	set[LayerRings] = false
+	set[LayerCoverage] = false
+	return set
+}
+
+// Visible reports whether a layer is shown
+func (s *LayerSet) Visible(layer Layer) bool {
+	return layer >= 0 && layer < numLayers && s[layer]
+}
+
+// Set shows or hides a layer
+func (s *LayerSet) Set(layer Layer, visible bool) {
+	if layer >= 0 && layer < numLayers {
+		s[layer] = visible
+	}
+}
+
+// Toggle flips a layer and returns its new visibility
+func (s *LayerSet) Toggle(layer Layer) bool {
+	s.Set(layer, !s.Visible(layer))
+	return s.Visible(layer)
+}
@@ -0,0 +1,101 @@
+package render
+
+import (
+	"ascii1090/internal/adsb"
+	"ascii1090/internal/geo"
+	"ascii1090/internal/weather"
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// LegendEntry is one line of the map legend: a sample of what is drawn,
+// in the style it is drawn with, and what it means
+type LegendEntry struct {
+	Sample string
+	Style  tcell.Style
+	Label  string
+}
+
+// LegendSection groups legend entries under a heading
+type LegendSection struct {
+	Title   string
+	Entries []LegendEntry
+}
+
+// Legend describes what the map's colors and characters currently mean
+// It reads the live styles, so it follows the theme, night mode, color
+// depth and symbol set; only visible layers are listed. Altitude bands are
+// labeled in units.
+func Legend(layers LayerSet, showWeather bool, units geo.Units) []LegendSection {
+	var features []LegendEntry
+	add := func(layer Layer, sample string, style tcell.Style, label string) {
+		if layers.Visible(layer) {
+			features = append(features, LegendEntry{Sample: sample, Style: style, Label: label})
+		}
+	}
+	add(LayerCoastlines, "/-\\", StyleCoastline, "Coastline")
+	add(LayerRivers, "~~~", StyleRiver, "River")
+	add(LayerBorders, "/-\\", StyleStateBorder, "Border")
+	add(LayerHighways, "===", StyleHighway, "Highway")
+	add(LayerCities, "Abc", StyleLabel, "City")
+	add(LayerAirports, "@", StyleAirport, "Airport")
+	add(LayerAirports, "###", StyleRunway, "Runway")
+	add(LayerAirspace, "###", StyleAirspaceB, "Class B")
+	add(LayerAirspace, ":::", StyleAirspaceC, "Class C")
+	add(LayerAirspace, "...", StyleAirspaceD, "Class D")
+	add(LayerNavaids, string(GetCharForNavaid("VOR")), StyleNavaid, "VOR")
+	add(LayerNavaids, string(GetCharForNavaid("NDB")), StyleNavaid, "NDB")
+	add(LayerNavaids, string(GetCharForNavaid("DME")), StyleNavaid, "DME")
+	add(LayerTimeZones, ":::", StyleTimeZone, "Time zone")
+	add(LayerOverlays, "+++", StyleOverlay, "Overlay")
+	add(LayerWaypoints, "+", StyleWaypoint, "Waypoint")
+	add(LayerRings, "···", StyleRing, "Range ring")
+	add(LayerCoverage, "░▒▓", StyleCoverage, "Coverage")
+
+	symbol := string(aircraftSymbol(&adsb.Aircraft{}))
+	aircraft := []LegendEntry{
+		{Sample: symbol, Style: StyleAircraft, Label: "Aircraft"},
+		{Sample: symbol, Style: StyleSelected, Label: "Selected"},
+		{Sample: symbol, Style: StyleEmergency, Label: "Emergency"},
+		{Sample: symbol, Style: StyleWatch, Label: "Watch, nearby, notable"},
+		{Sample: symbol, Style: positionSourceStyle(StyleAircraft, adsb.PositionMLAT), Label: "MLAT/ADS-R"},
+		{Sample: symbol, Style: positionSourceStyle(StyleAircraft, adsb.PositionTISB), Label: "TIS-B"},
+	}
+	if layers.Visible(LayerTrails) {
+		aircraft = append(aircraft, LegendEntry{Sample: "···", Style: StyleAircraft.Bold(false).Dim(true), Label: "Trail"})
+	}
+
+	sections := []LegendSection{
+		{Title: "Map", Entries: features},
+		{Title: "Aircraft", Entries: aircraft},
+	}
+
+	// Altitude bands only mean something when the gradient is in use
+	if AltitudeColor(0) != tcell.ColorDefault {
+		var bands []LegendEntry
+		for _, stop := range altitudeStops {
+			bands = append(bands, LegendEntry{
+				Sample: symbol,
+				Style:  GetStyleForAltitude(stop.feet),
+				Label:  fmt.Sprintf("%d %s", units.Altitude(stop.feet), units.AltitudeUnit()),
+			})
+		}
+		bands[len(bands)-1].Label += "+"
+		sections = append(sections, LegendSection{Title: "Altitude", Entries: bands})
+	}
+
+	if showWeather {
+		var stations []LegendEntry
+		for _, category := range []weather.FlightCategory{weather.CategoryVFR, weather.CategoryMVFR, weather.CategoryIFR, weather.CategoryLIFR} {
+			stations = append(stations, LegendEntry{
+				Sample: string(GetCharForCategory(category)),
+				Style:  GetStyleForCategory(category),
+				Label:  category.String(),
+			})
+		}
+		sections = append(sections, LegendSection{Title: "Weather", Entries: stations})
+	}
+
+	return sections
+}
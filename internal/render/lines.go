@@ -0,0 +1,65 @@
+package render
+
+import "math"
+
+// SlopeGlyph is a placeholder drawing character that tells DrawLine to pick
+// a glyph per cell from the line's direction instead of repeating one rune
+const SlopeGlyph rune = 0
+
+// slopeGlyph picks the ASCII stroke closest to a line's on-screen angle
+// Cells are taller than they are wide, so dy is scaled by the aspect ratio
+// before measuring the angle
+func slopeGlyph(dx, dy int, aspect float64) rune {
+	if dx == 0 && dy == 0 {
+		return '-'
+	}
+	if aspect <= 0 {
+		aspect = 1
+	}
+
+	// Screen y grows downward; flip it so positive angles point up
+	angle := math.Atan2(-float64(dy)*aspect, float64(dx)) * 180 / math.Pi
+	if angle < 0 {
+		angle += 180
+	}
+
+	switch {
+	case angle < 22.5 || angle >= 157.5:
+		return '-'
+	case angle < 67.5:
+		return '/'
+	case angle < 112.5:
+		return '|'
+	default:
+		return '\\'
+	}
+}
+
+// isSlopeGlyph reports whether r was drawn by a slope-aware line
+func isSlopeGlyph(r rune) bool {
+	switch r {
+	case '-', '_', '|', '/', '\\', '+', 'X':
+		return true
+	}
+	return false
+}
+
+// junctionGlyph merges a new stroke into a cell already holding one, so
+// crossing lines show a junction instead of the last one drawn
+func junctionGlyph(existing, glyph rune) rune {
+	if existing == glyph {
+		return glyph
+	}
+
+	horizontal := func(r rune) bool { return r == '-' || r == '_' }
+	if horizontal(existing) && horizontal(glyph) {
+		return glyph
+	}
+
+	diagonal := func(r rune) bool { return r == '/' || r == '\\' || r == 'X' }
+	if diagonal(existing) && diagonal(glyph) {
+		return 'X'
+	}
+
+	return '+'
+}
@@ -2,17 +2,109 @@ package render
 
 import (
 	"ascii1090/internal/adsb"
+	"ascii1090/internal/alert"
 	"ascii1090/internal/debug"
 	"ascii1090/internal/geo"
+	"math"
 
 	"github.com/gdamore/tcell/v2"
 )
 
+// log is the render package's debug scope
+var log = debug.NewScope("render")
+
 // MapRenderer renders geographic features and aircraft to a canvas
 type MapRenderer struct {
 	projection *geo.Projection
 	features   map[geo.FeatureType][]*geo.Feature
 	canvas     *Canvas
+
+	// Line features pre-simplified for the current zoom level
+	simplified        map[geo.FeatureType][]*geo.Feature
+	simplifyTolerance float64
+
+	// Screen-space polylines for visible line features, reused until the
+	// projection changes since the map is static between pans
+	projected *lineCache
+
+	// Per-layer scratch buffers for the line-layer workers
+	lineBuffers []*lineBuffer
+
+	layers        LayerSet
+	airportLabels AirportLabelMode
+
+	// Highways are drawn up to this Natural Earth scalerank
+	highwayDetail int
+
+	// Range rings are centered here, or on the map center if nil
+	receiver *geo.LatLon
+
+	// Label cells claimed during the current frame
+	labels *labelGrid
+
+	// High-density modes draw line layers into a sub-cell buffer using a
+	// finer projection, then fold it back into block glyphs; graphics modes
+	// keep the buffer as a pixel image instead
+	mode       RenderMode
+	lineProj   *geo.Projection
+	sub        *subpixelBuffer
+	raster     *subpixelBuffer
+	cellPixelW int
+	cellPixelH int
+
+	// Last image sent to the terminal in a graphics mode
+	sentPixels []tcell.Color
+
+	// Draw aircraft headings with 7-bit characters
+	ascii bool
+
+	// Units range rings and the scale bar are labeled in
+	units geo.Units
+
+	showAircraftLabels bool
+
+	// Active alerts by ICAO, and a frame counter driving blink animations
+	alerts map[string]alert.Kind
+	frame  uint64
+
+	// The static map (everything RenderMap draws) is cached in base and only
+	// redrawn when baseKey changes; each frame just undoes the cells the
+	// dynamic layers (aircraft, labels, weather) touched the frame before
+	base       *Canvas
+	baseKey    mapKey
+	generation uint64
+}
+
+// mapKey captures everything the static map depends on
+type mapKey struct {
+	projection    geo.ProjectionState
+	layers        LayerSet
+	airportLabels AirportLabelMode
+	highwayDetail int
+	mode          RenderMode
+	cellPixelW    int
+	cellPixelH    int
+	theme         uint64
+	generation    uint64
+}
+
+// NavaidMaxRadius is the largest map radius (miles) at which navaids are
+// drawn; beyond it they would swamp the map
+const NavaidMaxRadius = 60.0
+
+// RunwayMaxRadius is the largest map radius (miles) at which runway
+// centerlines are drawn; at wider zooms a runway is smaller than a cell
+const RunwayMaxRadius = 30.0
+
+// WaterLabelMaxRadius is the largest map radius (miles) at which river and
+// lake names are drawn
+const WaterLabelMaxRadius = 75.0
+
+// projectedLine is a screen-space polyline along with the feature it came
+// from, so per-feature styling survives projection caching
+type projectedLine struct {
+	feature *geo.Feature
+	points  []geo.Point
 }
 
 // NewMapRenderer creates a new map renderer
@@ -21,58 +113,441 @@ func NewMapRenderer(projection *geo.Projection, features map[geo.FeatureType][]*
 		projection: projection,
 		features:   features,
 		canvas:     canvas,
+		layers:     DefaultLayers(),
+
+		highwayDetail:      geo.MaxHighwayDetail,
+		showAircraftLabels: true,
 	}
 }
 
 // RenderMap draws all geographic features to the canvas
+// The result is cached: when nothing the map depends on has changed, only
+// the cells drawn over since the last call are restored from the cache.
+// Anything drawn after RenderMap is tracked for the next restore.
 func (m *MapRenderer) RenderMap() {
+	key := m.currentKey()
+	if m.base != nil && key == m.baseKey && m.base.Width() == m.canvas.Width() && m.base.Height() == m.canvas.Height() {
+		m.canvas.RestoreTouched(m.base)
+		m.canvas.Track(true)
+		return
+	}
+
+	if m.base == nil || m.base.Width() != m.canvas.Width() || m.base.Height() != m.canvas.Height() {
+		m.base = NewCanvas(m.canvas.Width(), m.canvas.Height())
+	}
+
+	target := m.canvas
+	m.canvas = m.base
+	m.canvas.Clear()
+	m.renderStatic()
+	m.canvas = target
+
+	m.baseKey = key
+	m.canvas.Track(false)
+	m.canvas.CopyFrom(m.base)
+	m.canvas.Track(true)
+}
+
+// currentKey returns the cache key for the static map as things stand
+func (m *MapRenderer) currentKey() mapKey {
+	return mapKey{
+		projection:    m.projection.State(),
+		layers:        m.layers,
+		airportLabels: m.airportLabels,
+		highwayDetail: m.highwayDetail,
+		mode:          m.mode,
+		cellPixelW:    m.cellPixelW,
+		cellPixelH:    m.cellPixelH,
+		theme:         themeGeneration,
+		generation:    m.generation,
+	}
+}
+
+// Invalidate forces the static map to be redrawn on the next frame, for
+// changes the cache key can't see (such as edited feature data)
+func (m *MapRenderer) Invalidate() {
+	m.generation++
+}
+
+// renderStatic draws every static map layer into m.canvas
+func (m *MapRenderer) renderStatic() {
+	m.lineProj = m.projection
+	m.sub = nil
+	if m.mode != RenderModeText {
+		sx, sy := m.subCells()
+		m.lineProj = subProjection(m.projection, sx, sy)
+		st := m.lineProj.State()
+		if m.raster == nil || m.raster.width != st.ScreenWidth || m.raster.height != st.ScreenHeight {
+			m.raster = newSubpixelBuffer(st.ScreenWidth, st.ScreenHeight)
+		} else {
+			m.raster.clear()
+		}
+		m.sub = m.raster
+	}
+
+	m.ensureSimplified()
+	m.labels = newLabelGrid(m.canvas.Width(), m.canvas.Height())
+
 	// Get visible bounds
 	bounds := m.projection.GetBounds()
 
-	// Render in order: coastlines, rivers, borders, highways, cities, airports
-	// This ensures proper layering (airports on top for visibility)
-	m.renderFeatureType(geo.FeatureCoastline, bounds)
-	m.renderFeatureType(geo.FeatureRiver, bounds)
-	m.renderFeatureType(geo.FeatureStateBorder, bounds)
-	m.renderFeatureType(geo.FeatureHighway, bounds)
+	// Line layers are drawn concurrently and composited in order:
+	// time zones, coastlines, rivers, borders, highways, airspace, runways,
+	// so point layers drawn afterwards (airports) stay on top
+	m.renderLineLayers(m.visibleLineTypes(), bounds)
+
+	// Fold sub-cell line layers back into the canvas before point layers;
+	// graphics modes leave them in the raster for GraphicsSequence
+	if m.sub != nil {
+		if !m.mode.IsGraphics() {
+			m.sub.composite(m.canvas, m.mode)
+		}
+		m.sub = nil
+	}
+
+	if m.layers.Visible(LayerRings) {
+		m.renderRings()
+	}
+
+	if m.layers.Visible(LayerNavaids) && m.projection.GetRadius() <= NavaidMaxRadius {
+		m.renderNavaids(bounds)
+	}
 
 	// Render cities and airports together to avoid overlapping labels
 	m.renderCitiesAndAirports(bounds)
+
+	// Water names have the lowest label priority
+	if m.layers.Visible(LayerRivers) && m.projection.GetRadius() <= WaterLabelMaxRadius {
+		m.renderWaterLabels(bounds)
+	}
+
+	// User overlays and waypoints go on top so local annotations are never hidden
+	if m.layers.Visible(LayerOverlays) {
+		m.renderOverlays(bounds)
+	}
+	if m.layers.Visible(LayerWaypoints) {
+		m.renderWaypoints(bounds)
+	}
+
+	if m.layers.Visible(LayerScale) {
+		m.renderCompass()
+		m.renderScaleBar()
+	}
 }
 
-// renderFeatureType renders all features of a specific type
-func (m *MapRenderer) renderFeatureType(ftype geo.FeatureType, bounds *geo.Bounds) {
-	features, exists := m.features[ftype]
+// renderWaterLabels names rivers and lakes at the midpoint of their
+// on-screen geometry, once per name, where the label doesn't collide
+func (m *MapRenderer) renderWaterLabels(bounds *geo.Bounds) {
+	lines, exists := m.projectedLines(geo.FeatureRiver, bounds)
 	if !exists {
 		return
 	}
 
-	// Filter to only visible features
-	visibleFeatures := geo.FilterByBounds(features, bounds)
+	labeled := make(map[string]bool)
+	for _, line := range lines {
+		name := line.feature.Name
+		if name == "" || labeled[name] || len(line.points) < 2 {
+			continue
+		}
+
+		mid := m.toCell(line.points[len(line.points)/2])
+		if mid.X < 0 || mid.Y < 0 || mid.X >= m.canvas.Width() || mid.Y >= m.canvas.Height() {
+			continue
+		}
+
+		// Center the label on the midpoint, one row above the line
+		x := mid.X - TextWidth(name)/2
+		y := mid.Y - 1
+		if m.labels.reserve(x, y, TextWidth(name)) {
+			m.canvas.DrawText(x, y, name, StyleWaterLabel)
+			labeled[name] = true
+		}
+	}
+}
+
+// renderAirspace draws Class B/C/D airspace boundaries, styled by class
+func (m *MapRenderer) renderAirspace(bounds *geo.Bounds) {
+	lines, exists := m.projectedLines(geo.FeatureAirspace, bounds)
+	if !exists {
+		return
+	}
+
+	for _, line := range lines {
+		class, _ := line.feature.Properties["class"].(string)
+		m.drawPolyline(line.points, GetCharForAirspace(class), GetStyleForAirspace(class))
+	}
+}
+
+// renderNavaids draws VOR/NDB/DME stations with their identifiers
+func (m *MapRenderer) renderNavaids(bounds *geo.Bounds) {
+	navaids, exists := m.features[geo.FeatureNavaid]
+	if !exists {
+		return
+	}
+
+	for _, navaid := range geo.FilterByBounds(navaids, bounds) {
+		navaidType, _ := navaid.Properties["type"].(string)
+		point := m.projection.Project(navaid.Point.Lat, navaid.Point.Lon)
+		m.canvas.Set(point.X, point.Y, GetCharForNavaid(geo.NavaidKind(navaidType)), StyleNavaid)
+
+		if navaid.Name != "" && point.X < m.canvas.Width()-TextWidth(navaid.Name)-1 {
+			m.canvas.DrawText(point.X+1, point.Y, navaid.Name, StyleNavaid)
+		}
+	}
+}
+
+// SetFeatures replaces the geographic features drawn, such as when map
+// data finishes loading after startup
+func (m *MapRenderer) SetFeatures(features map[geo.FeatureType][]*geo.Feature) {
+	m.features = features
+	m.simplified = nil
+	m.projected = nil
+	m.Invalidate()
+}
+
+// SetAirportLabelMode selects which identifier labels airports
+func (m *MapRenderer) SetAirportLabelMode(mode AirportLabelMode) {
+	m.airportLabels = mode
+}
+
+// AirportLabelMode returns the current airport label mode
+func (m *MapRenderer) AirportLabelMode() AirportLabelMode {
+	return m.airportLabels
+}
+
+// SetHighwayDetail sets the highest road scalerank drawn, from
+// geo.MinHighwayDetail to geo.MaxHighwayDetail
+func (m *MapRenderer) SetHighwayDetail(level int) {
+	if level != m.highwayDetail {
+		m.highwayDetail = level
+		m.projected = nil // Cached highway lines were filtered at the old level
+	}
+}
+
+// HighwayDetail returns the highest road scalerank drawn
+func (m *MapRenderer) HighwayDetail() int {
+	return m.highwayDetail
+}
+
+// SetLayer shows or hides a map layer
+func (m *MapRenderer) SetLayer(layer Layer, visible bool) {
+	m.layers.Set(layer, visible)
+}
+
+// ToggleLayer flips a map layer and returns its new visibility
+func (m *MapRenderer) ToggleLayer(layer Layer) bool {
+	return m.layers.Toggle(layer)
+}
+
+// SetLayers replaces the whole layer visibility set
+func (m *MapRenderer) SetLayers(layers LayerSet) {
+	m.layers = layers
+}
+
+// Layers returns the current layer visibility
+func (m *MapRenderer) Layers() LayerSet {
+	return m.layers
+}
+
+// LayerVisible reports whether a map layer is shown
+func (m *MapRenderer) LayerVisible(layer Layer) bool {
+	return m.layers.Visible(layer)
+}
+
+// SetReceiver sets the receiver location used to center range rings
+func (m *MapRenderer) SetReceiver(receiver *geo.LatLon) {
+	m.receiver = receiver
+}
+
+// SetUnits sets the distance unit range rings and the scale bar use
+func (m *MapRenderer) SetUnits(units geo.Units) {
+	m.units = units
+}
+
+// renderWaypoints draws user waypoints with their symbol and a label
+func (m *MapRenderer) renderWaypoints(bounds *geo.Bounds) {
+	waypoints, exists := m.features[geo.FeatureWaypoint]
+	if !exists {
+		return
+	}
 
-	// Debug log cities
-	if ftype == geo.FeatureCity && debug.Enabled() {
-		debug.Log("Rendering %d cities (of %d total)", len(visibleFeatures), len(features))
-		for _, city := range visibleFeatures {
-			if city.Name != "" {
-				debug.Log("  City: %s at %.2f, %.2f", city.Name, city.Point.Lat, city.Point.Lon)
+	for _, waypoint := range geo.FilterByBounds(waypoints, bounds) {
+		symbol := '+'
+		if value, ok := waypoint.Properties["symbol"].(string); ok {
+			for _, r := range value {
+				symbol = r
+				break
 			}
 		}
+
+		point := m.projection.Project(waypoint.Point.Lat, waypoint.Point.Lon)
+		m.canvas.Set(point.X, point.Y, symbol, StyleWaypoint)
+
+		if waypoint.Name != "" && point.X < m.canvas.Width()-TextWidth(waypoint.Name)-1 {
+			m.canvas.DrawText(point.X+1, point.Y, waypoint.Name, StyleLabel)
+		}
+	}
+}
+
+// renderOverlays draws user-supplied GeoJSON features with their own styles
+func (m *MapRenderer) renderOverlays(bounds *geo.Bounds) {
+	overlays, exists := m.simplified[geo.FeatureOverlay]
+	if !exists {
+		return
 	}
 
-	// Debug log airports
-	if ftype == geo.FeatureAirport && debug.Enabled() {
-		debug.Log("Rendering %d airports (of %d total)", len(visibleFeatures), len(features))
-		for i, airport := range visibleFeatures {
-			if airport.Name != "" && i < 20 { // Limit to first 20 to avoid spam
-				debug.Log("  Airport: %s at %.2f, %.2f", airport.Name, airport.Point.Lat, airport.Point.Lon)
+	for _, feature := range geo.FilterByBounds(overlays, bounds) {
+		style := GetStyleForOverlay(feature)
+		char := GetCharForOverlay(feature)
+
+		if feature.IsPoint() {
+			point := m.projection.Project(feature.Point.Lat, feature.Point.Lon)
+			m.canvas.Set(point.X, point.Y, char, style)
+			if feature.Name != "" && point.X < m.canvas.Width()-TextWidth(feature.Name)-1 {
+				m.canvas.DrawText(point.X+1, point.Y, feature.Name, StyleLabel)
 			}
+			continue
 		}
+
+		for i := 0; i < len(feature.Points)-1; i++ {
+			p1 := m.projection.Project(feature.Points[i].Lat, feature.Points[i].Lon)
+			p2 := m.projection.Project(feature.Points[i+1].Lat, feature.Points[i+1].Lon)
+			if abs(p2.X-p1.X) > m.canvas.Width() {
+				continue
+			}
+			m.DrawLine(p1.X, p1.Y, p2.X, p2.Y, char, style)
+		}
+	}
+}
+
+// renderFeatureType renders all line features of a specific type from the
+// projected geometry cache
+func (m *MapRenderer) renderFeatureType(ftype geo.FeatureType, bounds *geo.Bounds) {
+	polylines, exists := m.projectedLines(ftype, bounds)
+	if !exists {
+		return
 	}
 
+	style := GetStyleForFeature(ftype)
+	char := GetCharForFeature(ftype)
+	for _, line := range polylines {
+		m.drawPolyline(line.points, char, style)
+	}
+}
+
+// filterRoadDataset keeps only roads from the dataset best suited to the
+// current map center: the detailed North American set when the center is
+// inside it, the global set everywhere else. If only one dataset is loaded
+// it is always used.
+func (m *MapRenderer) filterRoadDataset(roads []*geo.Feature) []*geo.Feature {
+	datasets := make(map[string]bool)
+	for _, road := range roads {
+		if dataset, ok := road.Properties["dataset"].(string); ok {
+			datasets[dataset] = true
+		}
+	}
+	if len(datasets) < 2 {
+		return roads
+	}
+
+	want := geo.RoadsGlobal
+	if lat, lon := m.projection.GetCenter(); geo.NorthAmericaBounds.Contains(lat, lon) {
+		want = geo.RoadsNorthAmerica
+	}
+
+	filtered := make([]*geo.Feature, 0, len(roads))
+	for _, road := range roads {
+		if road.Properties["dataset"] == want {
+			filtered = append(filtered, road)
+		}
+	}
+	return filtered
+}
+
+// filterRoadDetail drops roads above the highway detail level; roads
+// without a scalerank are always kept
+func (m *MapRenderer) filterRoadDetail(roads []*geo.Feature) []*geo.Feature {
+	filtered := make([]*geo.Feature, 0, len(roads))
+	for _, road := range roads {
+		if scalerank, ok := road.Properties["scalerank"].(int); !ok || scalerank <= m.highwayDetail {
+			filtered = append(filtered, road)
+		}
+	}
+	return filtered
+}
+
+// drawPolyline draws connected line segments through screen points
+// While line layers are being rasterized in a high-density mode, points are
+// sub-cell coordinates and go to the sub-cell buffer instead
+func (m *MapRenderer) drawPolyline(points []geo.Point, char rune, style tcell.Style) {
+	if m.sub != nil {
+		fg, _, _ := style.Decompose()
+		if fg == tcell.ColorDefault {
+			// ColorDefault marks an empty sub-cell; monochrome styles still draw
+			fg = tcell.ColorReset
+		}
+		for i := 0; i < len(points)-1; i++ {
+			m.sub.line(points[i].X, points[i].Y, points[i+1].X, points[i+1].Y, fg)
+		}
+		return
+	}
+
+	for i := 0; i < len(points)-1; i++ {
+		m.drawLine(points[i].X, points[i].Y, points[i+1].X, points[i+1].Y, char, style, i > 0)
+	}
+}
+
+// projectedLines returns cached screen-space polylines for a feature type,
+// rebuilding the whole cache if the projection or simplification changed
+// It is safe to call from line-layer workers once lineCacheFor has been
+// called for the current projection
+func (m *MapRenderer) projectedLines(ftype geo.FeatureType, bounds *geo.Bounds) ([]projectedLine, bool) {
+	proj := m.lineProj
+	if proj == nil {
+		proj = m.projection
+	}
+
+	state := proj.State()
+	cache := m.lineCacheFor(state)
+	if lines, ok := cache.get(ftype); ok {
+		return lines, true
+	}
+
+	features, exists := m.simplified[ftype]
+	if !exists {
+		return nil, false
+	}
+
+	// Filter to only visible features
+	visibleFeatures := geo.FilterByBounds(features, bounds)
+
+	if ftype == geo.FeatureHighway {
+		visibleFeatures = m.filterRoadDetail(m.filterRoadDataset(visibleFeatures))
+	}
+
+	// A segment jumping more than a screen width crosses the wrap seam on
+	// the far side of the globe; split the polyline there instead of
+	// drawing a line across the whole map
+	maxJump := state.ScreenWidth
+	lines := make([]projectedLine, 0, len(visibleFeatures))
 	for _, feature := range visibleFeatures {
-		m.RenderFeature(feature)
+		if !feature.IsLine() {
+			continue
+		}
+		line := make([]geo.Point, 0, len(feature.Points))
+		for _, point := range feature.Points {
+			p := proj.Project(point.Lat, point.Lon)
+			if len(line) > 0 && abs(p.X-line[len(line)-1].X) > maxJump {
+				lines = append(lines, projectedLine{feature: feature, points: line})
+				line = make([]geo.Point, 0, len(feature.Points))
+			}
+			line = append(line, p)
+		}
+		lines = append(lines, projectedLine{feature: feature, points: line})
 	}
+
+	cache.put(ftype, lines)
+	return lines, true
 }
 
 // RenderFeature draws a single geographic feature
@@ -86,7 +561,7 @@ func (m *MapRenderer) RenderFeature(feature *geo.Feature) {
 		m.canvas.Set(point.X, point.Y, '●', style)
 
 		// Render label if available and not too close to edge
-		if feature.Name != "" && point.X < m.canvas.Width()-len(feature.Name)-1 {
+		if feature.Name != "" && point.X < m.canvas.Width()-TextWidth(feature.Name)-1 {
 			m.canvas.DrawText(point.X+1, point.Y, feature.Name, StyleLabel)
 		}
 	} else if feature.IsLine() {
@@ -105,6 +580,9 @@ func (m *MapRenderer) renderCitiesAndAirports(bounds *geo.Bounds) {
 	airports, hasAirports := m.features[geo.FeatureAirport]
 	cities, hasCities := m.features[geo.FeatureCity]
 
+	hasAirports = hasAirports && m.layers.Visible(LayerAirports)
+	hasCities = hasCities && m.layers.Visible(LayerCities)
+
 	// Filter to visible bounds
 	visibleAirports := []*geo.Feature{}
 	if hasAirports {
@@ -121,10 +599,15 @@ func (m *MapRenderer) renderCitiesAndAirports(bounds *geo.Bounds) {
 		X, Y int
 	}
 	airportPositions := make([]ScreenPoint, 0, len(visibleAirports))
-	for _, airport := range visibleAirports {
+	airportLabelShown := make([]bool, len(visibleAirports))
+	for i, airport := range visibleAirports {
 		if airport.Point != nil {
 			point := m.projection.Project(airport.Point.Lat, airport.Point.Lon)
 			airportPositions = append(airportPositions, ScreenPoint{X: point.X, Y: point.Y})
+
+			// Airport labels take priority, so claim their cells first
+			label := airportLabel(airport, m.airportLabels)
+			airportLabelShown[i] = label != "" && m.labels.reserve(point.X, point.Y, TextWidth(label)+1)
 		}
 	}
 
@@ -136,7 +619,7 @@ func (m *MapRenderer) renderCitiesAndAirports(bounds *geo.Bounds) {
 
 		point := m.projection.Project(city.Point.Lat, city.Point.Lon)
 
-		// Skip if this city is too close to any airport 
+		// Skip if this city is too close to any airport
 		skipCity := false
 		for _, airportPos := range airportPositions {
 			if airportPos.Y == point.Y && abs(airportPos.X-point.X) <= 5 {
@@ -154,13 +637,13 @@ func (m *MapRenderer) renderCitiesAndAirports(bounds *geo.Bounds) {
 			continue
 		}
 
-		if point.X < m.canvas.Width()-len(city.Name)-1 {
+		if point.X < m.canvas.Width()-TextWidth(city.Name)-1 && m.labels.reserve(point.X, point.Y, TextWidth(city.Name)) {
 			m.canvas.DrawText(point.X, point.Y, city.Name, StyleLabel)
 		}
 	}
 
 	// Render airports with @ symbol
-	for _, airport := range visibleAirports {
+	for i, airport := range visibleAirports {
 		if airport.Point == nil {
 			continue
 		}
@@ -169,34 +652,205 @@ func (m *MapRenderer) renderCitiesAndAirports(bounds *geo.Bounds) {
 		m.canvas.Set(point.X, point.Y, '@', StyleAirport)
 
 		// Render label if available and not too close to edge
-		if airport.Name != "" && point.X < m.canvas.Width()-len(airport.Name)-1 {
-			m.canvas.DrawText(point.X+1, point.Y, airport.Name, StyleLabel)
+		label := airportLabel(airport, m.airportLabels)
+		if airportLabelShown[i] && point.X < m.canvas.Width()-TextWidth(label)-1 {
+			m.canvas.DrawText(point.X+1, point.Y, label, StyleLabel)
 		}
 	}
 }
 
+// RenderRoute draws the great-circle path between two airports, used to
+// show the overall trajectory of the selected flight
+func (m *MapRenderer) RenderRoute(origin, destination *geo.Feature) {
+	if origin == nil || destination == nil || origin.Point == nil || destination.Point == nil {
+		return
+	}
+
+	path := geo.GreatCircle(*origin.Point, *destination.Point, 64)
+	points := make([]geo.Point, 0, len(path))
+	for _, p := range path {
+		point := m.projection.Project(p.Lat, p.Lon)
+		// Don't draw across the screen where the path wraps the antimeridian
+		if len(points) > 0 && abs(point.X-points[len(points)-1].X) > m.canvas.Width() {
+			m.drawPolyline(points, '·', StyleRoute)
+			points = points[:0]
+		}
+		points = append(points, point)
+	}
+	m.drawPolyline(points, '·', StyleRoute)
+}
+
 // RenderAircraft draws aircraft symbols on the canvas
+// Aircraft with an active alert are drawn in the alert style, flashing
+// between normal and reverse video with a pulsing marker ring around them
 func (m *MapRenderer) RenderAircraft(aircraft []*adsb.Aircraft, selectedICAO string) {
+	flashOn := m.Blink(AlertBlinkFrames)
+
+	if m.layers.Visible(LayerTrails) {
+		m.renderTrails(aircraft)
+	}
+	// The selected aircraft's whole path shows with trails on or off
+	for _, ac := range aircraft {
+		if ac.ICAO == selectedICAO {
+			m.renderHistory(ac)
+		}
+	}
+
+	if flashOn {
+		for _, ac := range aircraft {
+			if kind, ok := m.alerts[ac.ICAO]; ok && ac.PositionLocked() {
+				point := m.projection.Project(*ac.Latitude, *ac.Longitude)
+				m.drawAlertRing(point, alertStyle(kind))
+			}
+		}
+	}
+
 	for _, ac := range aircraft {
 		if !ac.PositionLocked() {
 			continue
 		}
 
 		point := m.projection.Project(*ac.Latitude, *ac.Longitude)
-		symbol := ac.CardinalDirection()
+		symbol := aircraftSymbol(ac)
+		if ascii, ok := asciiAircraft[symbol]; ok && m.ascii {
+			symbol = ascii
+		}
 
 		// Use different style for selected aircraft
-		style := StyleAircraft
+		style := GetStyleForAltitude(ac.Altitude)
 		if ac.ICAO == selectedICAO {
 			style = StyleSelected
 		}
 
+		if kind, ok := m.alerts[ac.ICAO]; ok {
+			style = alertStyle(kind).Blink(true).Reverse(flashOn)
+		}
+		style = positionSourceStyle(style, ac.PositionSource)
+
 		m.canvas.Set(point.X, point.Y, symbol, style)
 	}
+
+	if m.showAircraftLabels {
+		m.renderAircraftLabels(aircraft, selectedICAO)
+	}
+}
+
+// positionSourceStyle marks aircraft whose position isn't their own ADS-B
+// report: underlined for MLAT and ADS-R, and also dimmed for TIS-B, whose
+// radar-derived positions are the least precise
+func positionSourceStyle(style tcell.Style, source adsb.PositionSource) tcell.Style {
+	if !source.Indirect() {
+		return style
+	}
+	style = style.Underline(true)
+	if source == adsb.PositionTISB {
+		style = style.Dim(true)
+	}
+	return style
+}
+
+// AlertBlinkFrames is the half-period, in frames, of alert flashing
+const AlertBlinkFrames = 5
+
+// alertRing is the marker ring drawn around alerting aircraft, as cell
+// offsets; it's twice as wide as tall to look round with tall cells
+var alertRing = []geo.Point{
+	{X: -4, Y: 0}, {X: 4, Y: 0}, {X: 0, Y: -2}, {X: 0, Y: 2},
+	{X: -3, Y: -1}, {X: 3, Y: -1}, {X: -3, Y: 1}, {X: 3, Y: 1},
+	{X: -2, Y: -2}, {X: 2, Y: -2}, {X: -2, Y: 2}, {X: 2, Y: 2},
+}
+
+// drawAlertRing draws the marker ring centered on an aircraft
+func (m *MapRenderer) drawAlertRing(center geo.Point, style tcell.Style) {
+	for _, offset := range alertRing {
+		m.canvas.Set(center.X+offset.X, center.Y+offset.Y, '·', style)
+	}
+}
+
+// alertStyle returns the style for an alert kind
+func alertStyle(kind alert.Kind) tcell.Style {
+	if kind == alert.KindEmergency {
+		return StyleEmergency
+	}
+	return StyleWatch
+}
+
+// SetAlerts sets the aircraft to emphasize, keyed by ICAO
+func (m *MapRenderer) SetAlerts(alerts map[string]alert.Kind) {
+	m.alerts = alerts
+}
+
+// Tick advances the animation frame counter; call once per rendered frame
+func (m *MapRenderer) Tick() {
+	m.frame++
+}
+
+// Blink reports whether a blinking element with the given half-period in
+// frames is in its "on" phase for the current frame
+func (m *MapRenderer) Blink(halfPeriod int) bool {
+	if halfPeriod <= 0 {
+		return true
+	}
+	return (m.frame/uint64(halfPeriod))%2 == 0
+}
+
+// ToggleAircraftLabels toggles the callsign/flight level tags next to aircraft
+func (m *MapRenderer) ToggleAircraftLabels() {
+	m.showAircraftLabels = !m.showAircraftLabels
+}
+
+// AircraftLabelsVisible reports whether aircraft labels are enabled
+func (m *MapRenderer) AircraftLabelsVisible() bool {
+	return m.showAircraftLabels
 }
 
 // DrawLine implements Bresenham's line algorithm for drawing lines on the canvas
+// Passing SlopeGlyph as char picks / \ | - _ per cell from the line's slope
 func (m *MapRenderer) DrawLine(x0, y0, x1, y1 int, char rune, style tcell.Style) {
+	m.drawLine(x0, y0, x1, y1, char, style, false)
+}
+
+// drawLine is DrawLine with the option to leave the first cell alone, so a
+// polyline's shared vertices aren't drawn twice and mistaken for junctions
+func (m *MapRenderer) drawLine(x0, y0, x1, y1 int, char rune, style tcell.Style, skipFirst bool) {
+	if char != SlopeGlyph {
+		bresenham(x0, y0, x1, y1, func(x, y int) {
+			m.canvas.Set(x, y, char, style)
+		}, skipFirst)
+		return
+	}
+
+	base := slopeGlyph(x1-x0, y1-y0, m.projection.State().AspectRatio)
+
+	var cells []geo.Point
+	bresenham(x0, y0, x1, y1, func(x, y int) {
+		cells = append(cells, geo.Point{X: x, Y: y})
+	}, false)
+
+	for i, cell := range cells {
+		if i == 0 && skipFirst {
+			continue
+		}
+
+		glyph := base
+		if base == '-' {
+			// On shallow lines, the cell on the upper row at each step drops
+			// to an underscore so the line reads as continuous
+			if (i+1 < len(cells) && cells[i+1].Y > cell.Y) || (i > 0 && cells[i-1].Y > cell.Y) {
+				glyph = '_'
+			}
+		}
+
+		if existing := m.canvas.Get(cell.X, cell.Y).Char; isSlopeGlyph(existing) {
+			glyph = junctionGlyph(existing, glyph)
+		}
+
+		m.canvas.Set(cell.X, cell.Y, glyph, style)
+	}
+}
+
+// bresenham visits every cell on the line from (x0,y0) to (x1,y1)
+func bresenham(x0, y0, x1, y1 int, visit func(x, y int), skipFirst bool) {
 	dx := abs(x1 - x0)
 	dy := abs(y1 - y0)
 
@@ -212,8 +866,10 @@ func (m *MapRenderer) DrawLine(x0, y0, x1, y1 int, char rune, style tcell.Style)
 
 	err := dx - dy
 
-	for {
-		m.canvas.Set(x0, y0, char, style)
+	for step := 0; ; step++ {
+		if step > 0 || !skipFirst {
+			visit(x0, y0)
+		}
 
 		if x0 == x1 && y0 == y1 {
 			break
@@ -233,6 +889,38 @@ func (m *MapRenderer) DrawLine(x0, y0, x1, y1 int, char rune, style tcell.Style)
 	}
 }
 
+// ensureSimplified re-simplifies line features when the zoom level has
+// changed enough that the cached tolerance no longer matches the screen.
+// Tolerances are snapped to powers of two so small zoom steps reuse the cache.
+func (m *MapRenderer) ensureSimplified() {
+	// Half a cell is invisible on screen, so it's safe to drop that much detail
+	proj := m.lineProj
+	if proj == nil {
+		proj = m.projection
+	}
+	target := proj.DegreesPerCell() / 2
+	tolerance := math.Pow(2, math.Floor(math.Log2(target)))
+
+	if m.simplified != nil && tolerance == m.simplifyTolerance {
+		return
+	}
+
+	simplified := make(map[geo.FeatureType][]*geo.Feature, len(m.features))
+	before, after := 0, 0
+	for ftype, features := range m.features {
+		simplified[ftype] = geo.SimplifyFeatures(features, tolerance)
+		for i, feature := range features {
+			before += len(feature.Points)
+			after += len(simplified[ftype][i].Points)
+		}
+	}
+
+	m.simplified = simplified
+	m.simplifyTolerance = tolerance
+	m.projected = nil
+	log.Tracef("Simplified features at tolerance %.5f deg: %d -> %d vertices", tolerance, before, after)
+}
+
 // abs returns the absolute value of an integer
 func abs(x int) int {
 	if x < 0 {
@@ -241,6 +929,56 @@ func abs(x int) int {
 	return x
 }
 
+// SetRenderMode selects text, high-density block or graphics rendering for map lines
+func (m *MapRenderer) SetRenderMode(mode RenderMode) {
+	m.mode = mode
+	m.projected = nil
+	m.raster = nil
+	m.sentPixels = nil
+}
+
+// SetASCII switches aircraft heading symbols to 7-bit characters
+func (m *MapRenderer) SetASCII(ascii bool) {
+	m.ascii = ascii
+}
+
+// RenderMode returns the current render mode
+func (m *MapRenderer) RenderMode() RenderMode {
+	return m.mode
+}
+
+// SetCellPixels sets the terminal cell size in pixels, which graphics
+// modes use as their sub-cell resolution
+func (m *MapRenderer) SetCellPixels(width, height int) {
+	if width <= 0 || height <= 0 {
+		width, height = DefaultCellPixelWidth, DefaultCellPixelHeight
+	}
+	if width != m.cellPixelW || height != m.cellPixelH {
+		m.cellPixelW = width
+		m.cellPixelH = height
+		m.projected = nil
+	}
+}
+
+// subCells returns how many sub-cells wide and tall each character cell is
+// in the current mode
+func (m *MapRenderer) subCells() (sx, sy int) {
+	if m.mode.IsGraphics() {
+		if m.cellPixelW <= 0 || m.cellPixelH <= 0 {
+			return DefaultCellPixelWidth, DefaultCellPixelHeight
+		}
+		return m.cellPixelW, m.cellPixelH
+	}
+	return m.mode.subCells()
+}
+
+// toCell converts a point from the line-layer projection back to cell
+// coordinates (a no-op in text mode)
+func (m *MapRenderer) toCell(p geo.Point) geo.Point {
+	sx, sy := m.subCells()
+	return geo.Point{X: p.X / sx, Y: p.Y / sy}
+}
+
 // UpdateProjection updates the renderer's projection
 func (m *MapRenderer) UpdateProjection(projection *geo.Projection) {
 	m.projection = projection
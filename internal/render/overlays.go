@@ -0,0 +1,168 @@
+package render
+
+import (
+	"ascii1090/internal/adsb"
+	"ascii1090/internal/geo"
+	"fmt"
+	"time"
+)
+
+// ringSteps are the candidate range ring spacings in the distance unit
+var ringSteps = []float64{1, 2, 5, 10, 25, 50, 100, 250, 500}
+
+// RingCount is roughly how many range rings fit inside the map radius
+const RingCount = 4
+
+// ringSpacing picks a round ring spacing giving about RingCount rings
+func ringSpacing(radius float64) float64 {
+	for _, step := range ringSteps {
+		if step*RingCount >= radius {
+			return step
+		}
+	}
+	return ringSteps[len(ringSteps)-1]
+}
+
+// renderRings draws range rings around the receiver (or the map center)
+// with their distance labeled at the top of each ring
+func (m *MapRenderer) renderRings() {
+	centerLat, centerLon := m.projection.GetCenter()
+	if m.receiver != nil {
+		centerLat, centerLon = m.receiver.Lat, m.receiver.Lon
+	}
+
+	// Spacing is picked in the distance unit so the labels are round
+	radius := m.units.FromMiles(m.projection.GetRadius())
+	spacing := ringSpacing(radius)
+
+	// Rings reach the screen corners, which lie beyond the radius
+	for distance := spacing; distance <= radius*2; distance += spacing {
+		miles := m.units.ToMiles(distance)
+		points := make([]geo.Point, 0, 73)
+		for bearing := 0.0; bearing <= 360; bearing += 5 {
+			p := geo.Destination(centerLat, centerLon, bearing, miles)
+			points = append(points, m.projection.Project(p.Lat, p.Lon))
+		}
+		m.drawPolyline(points, '·', StyleRing)
+
+		top := points[0]
+		label := fmt.Sprintf("%g%s", distance, m.units.DistanceUnit())
+		if m.labels.reserve(top.X+1, top.Y, len(label)) {
+			m.canvas.DrawText(top.X+1, top.Y, label, StyleRing)
+		}
+	}
+}
+
+// renderTrails draws each aircraft's recent path as a dotted line in its
+// altitude color
+func (m *MapRenderer) renderTrails(aircraft []*adsb.Aircraft) {
+	for _, ac := range aircraft {
+		trail := ac.Trail
+		if len(trail) < 2 {
+			continue
+		}
+
+		points := make([]geo.Point, 0, len(trail))
+		for _, p := range trail {
+			points = append(points, m.projection.Project(p.Lat, p.Lon))
+		}
+
+		m.drawPolyline(points, '·', GetStyleForAltitude(ac.Altitude).Bold(false).Dim(true))
+	}
+}
+
+// renderHistory draws the selected aircraft's whole path since it was
+// first tracked: its sampled history, then its recent trail in full
+func (m *MapRenderer) renderHistory(ac *adsb.Aircraft) {
+	points := make([]geo.Point, 0, len(ac.History)+len(ac.Trail))
+	var last time.Time
+	for _, p := range ac.History {
+		points = append(points, m.projection.Project(p.Lat, p.Lon))
+		last = p.Time
+	}
+	for _, p := range ac.Trail {
+		if p.Time.After(last) {
+			points = append(points, m.projection.Project(p.Lat, p.Lon))
+		}
+	}
+	if len(points) < 2 {
+		return
+	}
+
+	m.drawPolyline(points, '·', GetStyleForAltitude(ac.Altitude).Bold(false))
+}
+
+// scaleSteps are the candidate scale bar lengths in the distance unit
+var scaleSteps = []float64{0.1, 0.25, 0.5, 1, 2, 5, 10, 25, 50, 100, 250, 500, 1000, 2500}
+
+// ScaleBarMaxCells is the longest the scale bar may grow, in cells
+const ScaleBarMaxCells = 20
+
+// compassRose is drawn in the top-right corner of the map, below the
+// status bar
+var compassRose = []string{
+	"  N  ",
+	"W─┼─E",
+	"  S  ",
+}
+
+// renderCompass draws a small compass rose in the top-right corner
+func (m *MapRenderer) renderCompass() {
+	x := m.canvas.Width() - len([]rune(compassRose[0])) - 1
+	if x < 0 {
+		return
+	}
+	for i, row := range compassRose {
+		col := x
+		for _, r := range row {
+			if r != ' ' {
+				m.canvas.Set(col, i+1, r, StyleRing)
+			}
+			col++
+		}
+	}
+}
+
+// renderScaleBar draws a bar of a round distance in the bottom-right
+// corner, sized from the current zoom so on-screen distances can be read
+func (m *MapRenderer) renderScaleBar() {
+	// Measure how many cells a mile spans east-west at the map center
+	lat, lon := m.projection.GetCenter()
+	origin := m.projection.Project(lat, lon)
+	probe := geo.Destination(lat, lon, 90, m.projection.GetRadius())
+	cellsPerMile := float64(m.projection.Project(probe.Lat, probe.Lon).X-origin.X) / m.projection.GetRadius()
+	if cellsPerMile <= 0 {
+		return
+	}
+	cellsPerUnit := cellsPerMile * m.units.ToMiles(1)
+
+	maxCells := ScaleBarMaxCells
+	if quarter := m.canvas.Width() / 4; quarter < maxCells {
+		maxCells = quarter
+	}
+
+	distance, cells := 0.0, 0
+	for _, step := range scaleSteps {
+		n := int(step*cellsPerUnit + 0.5)
+		if n > maxCells {
+			break
+		}
+		if n >= 2 {
+			distance, cells = step, n
+		}
+	}
+	if cells == 0 {
+		return
+	}
+
+	label := fmt.Sprintf(" %g %s", distance, m.units.DistanceUnit())
+	x := m.canvas.Width() - cells - len(label) - 1
+	y := m.canvas.Height() - 2
+	if x < 0 || y < 0 {
+		return
+	}
+	for i := 0; i < cells; i++ {
+		m.canvas.Set(x+i, y, '─', StyleRing)
+	}
+	m.canvas.DrawText(x+cells, y, label, StyleRing)
+}
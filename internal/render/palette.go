@@ -0,0 +1,136 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ColorDepth describes how many colors the terminal can show
+type ColorDepth int
+
+const (
+	ColorDepthAuto      ColorDepth = iota // Detect from the terminal
+	ColorDepth16                          // Basic ANSI colors
+	ColorDepth256                         // xterm 256-color palette
+	ColorDepthTrueColor                   // 24-bit RGB
+)
+
+// String returns the flag spelling of the color depth
+func (d ColorDepth) String() string {
+	switch d {
+	case ColorDepth16:
+		return "16"
+	case ColorDepth256:
+		return "256"
+	case ColorDepthTrueColor:
+		return "truecolor"
+	default:
+		return "auto"
+	}
+}
+
+// ParseColorDepth parses "auto", "16", "256" or "truecolor"
+func ParseColorDepth(s string) (ColorDepth, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "auto", "":
+		return ColorDepthAuto, nil
+	case "16", "8":
+		return ColorDepth16, nil
+	case "256":
+		return ColorDepth256, nil
+	case "truecolor", "24bit", "24":
+		return ColorDepthTrueColor, nil
+	default:
+		return ColorDepthAuto, fmt.Errorf("unknown color depth %q (use auto, 16, 256 or truecolor)", s)
+	}
+}
+
+// DetectColorDepth reports the color depth tcell found for the terminal
+func DetectColorDepth(screen tcell.Screen) ColorDepth {
+	switch colors := screen.Colors(); {
+	case colors >= 1<<24:
+		return ColorDepthTrueColor
+	case colors >= 256:
+		return ColorDepth256
+	default:
+		return ColorDepth16
+	}
+}
+
+// activeDepth is the depth the styles were last configured for
+var activeDepth = ColorDepth16
+
+// altitudeStops are the gradient anchors for aircraft altitude colors,
+// running warm near the ground to cool at cruise
+var altitudeStops = []struct {
+	feet    int
+	r, g, b int32
+}{
+	{0, 0xff, 0x80, 0x00},
+	{2000, 0xff, 0xd0, 0x00},
+	{6000, 0x80, 0xe0, 0x20},
+	{12000, 0x00, 0xd0, 0x80},
+	{20000, 0x00, 0xb0, 0xff},
+	{30000, 0x60, 0x70, 0xff},
+	{40000, 0xd0, 0x50, 0xff},
+}
+
+// ApplyColorDepth switches the map styles to a richer palette when the
+// terminal has 256 or more colors. RGB colors are mapped to the nearest
+// palette entry by tcell on 256-color terminals, so one palette serves both.
+// With 16 colors the basic styles are left untouched.
+func ApplyColorDepth(depth ColorDepth) {
+	activeDepth = depth
+	themeGeneration++
+	if depth < ColorDepth256 {
+		return
+	}
+
+	rgb := tcell.NewRGBColor
+	StyleStateBorder = tcell.StyleDefault.Foreground(rgb(0x6c, 0x6c, 0x78))
+	StyleHighway = tcell.StyleDefault.Foreground(rgb(0xb8, 0x9a, 0x3c))
+	StyleRiver = tcell.StyleDefault.Foreground(rgb(0x3a, 0x8f, 0xb0))
+	StyleCoastline = tcell.StyleDefault.Foreground(rgb(0x2f, 0x5f, 0xa8))
+	StyleAirspaceB = tcell.StyleDefault.Foreground(rgb(0x3f, 0x6f, 0xd8))
+	StyleAirspaceC = tcell.StyleDefault.Foreground(rgb(0xa0, 0x4c, 0xa8))
+	StyleAirspaceD = tcell.StyleDefault.Foreground(rgb(0x5a, 0x86, 0xb0))
+	StyleTimeZone = tcell.StyleDefault.Foreground(rgb(0x3a, 0x44, 0x48))
+	StyleWaterLabel = tcell.StyleDefault.Foreground(rgb(0x3a, 0x8f, 0xb0)).Italic(true)
+}
+
+// AltitudeColor returns the gradient color for an altitude in feet, or
+// ColorDefault when the terminal only has 16 colors or the theme turns
+// altitude colors off
+func AltitudeColor(feet int) tcell.Color {
+	if activeDepth < ColorDepth256 || !altitudeColors {
+		return tcell.ColorDefault
+	}
+
+	if feet <= altitudeStops[0].feet {
+		s := altitudeStops[0]
+		return tcell.NewRGBColor(s.r, s.g, s.b)
+	}
+
+	for i := 1; i < len(altitudeStops); i++ {
+		lo, hi := altitudeStops[i-1], altitudeStops[i]
+		if feet <= hi.feet {
+			t := float64(feet-lo.feet) / float64(hi.feet-lo.feet)
+			lerp := func(a, b int32) int32 { return a + int32(t*float64(b-a)) }
+			return tcell.NewRGBColor(lerp(lo.r, hi.r), lerp(lo.g, hi.g), lerp(lo.b, hi.b))
+		}
+	}
+
+	s := altitudeStops[len(altitudeStops)-1]
+	return tcell.NewRGBColor(s.r, s.g, s.b)
+}
+
+// GetStyleForAltitude returns the aircraft style for an altitude, colored
+// along the altitude gradient when the terminal supports it
+func GetStyleForAltitude(feet int) tcell.Style {
+	if color := AltitudeColor(feet); color != tcell.ColorDefault {
+		return StyleAircraft.Foreground(color)
+	}
+	return StyleAircraft
+}
@@ -0,0 +1,173 @@
+package render
+
+import (
+	"ascii1090/internal/geo"
+	"sync"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// lineCache holds projected polylines per feature type for one projection
+// state; line-layer workers fill it concurrently
+type lineCache struct {
+	mu    sync.Mutex
+	state geo.ProjectionState
+	lines map[geo.FeatureType][]projectedLine
+}
+
+// get returns the cached polylines for a feature type
+func (c *lineCache) get(ftype geo.FeatureType) ([]projectedLine, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	lines, ok := c.lines[ftype]
+	return lines, ok
+}
+
+// put stores the polylines for a feature type
+func (c *lineCache) put(ftype geo.FeatureType, lines []projectedLine) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lines[ftype] = lines
+}
+
+// lineCacheFor returns the projected line cache, starting a fresh one if
+// the projection state changed
+func (m *MapRenderer) lineCacheFor(state geo.ProjectionState) *lineCache {
+	if m.projected == nil || m.projected.state != state {
+		m.projected = &lineCache{
+			state: state,
+			lines: make(map[geo.FeatureType][]projectedLine),
+		}
+	}
+	return m.projected
+}
+
+// lineBuffer is the layer-local target one line-layer worker draws into
+type lineBuffer struct {
+	canvas *Canvas
+	sub    *subpixelBuffer
+}
+
+// visibleLineTypes returns the line feature types to draw, bottom first
+// Time zones sit underneath everything else as faint context
+func (m *MapRenderer) visibleLineTypes() []geo.FeatureType {
+	var types []geo.FeatureType
+	if m.layers.Visible(LayerTimeZones) {
+		types = append(types, geo.FeatureTimeZone)
+	}
+	if m.layers.Visible(LayerCoastlines) {
+		types = append(types, geo.FeatureCoastline)
+	}
+	if m.layers.Visible(LayerRivers) {
+		types = append(types, geo.FeatureRiver)
+	}
+	if m.layers.Visible(LayerBorders) {
+		types = append(types, geo.FeatureStateBorder)
+	}
+	if m.layers.Visible(LayerHighways) {
+		types = append(types, geo.FeatureHighway)
+	}
+	if m.layers.Visible(LayerAirspace) {
+		types = append(types, geo.FeatureAirspace)
+	}
+	if m.layers.Visible(LayerAirports) && m.projection.GetRadius() <= RunwayMaxRadius {
+		types = append(types, geo.FeatureRunway)
+	}
+	return types
+}
+
+// renderLineLayers projects and draws each line feature type on its own
+// goroutine into a layer-local buffer, then composites the buffers in
+// order so the result matches drawing them one after another
+func (m *MapRenderer) renderLineLayers(types []geo.FeatureType, bounds *geo.Bounds) {
+	proj := m.lineProj
+	if proj == nil {
+		proj = m.projection
+	}
+	// Settle the cache before the workers share it
+	m.lineCacheFor(proj.State())
+
+	buffers := m.lineBuffersFor(len(types))
+
+	var wg sync.WaitGroup
+	for i, ftype := range types {
+		wg.Add(1)
+		go func(ftype geo.FeatureType, buffer *lineBuffer) {
+			defer wg.Done()
+
+			// Each worker gets its own renderer view whose drawing target is
+			// its buffer; everything else it reads is shared and unchanged
+			worker := *m
+			worker.canvas = buffer.canvas
+			worker.sub = buffer.sub
+			if ftype == geo.FeatureAirspace {
+				worker.renderAirspace(bounds)
+			} else {
+				worker.renderFeatureType(ftype, bounds)
+			}
+		}(ftype, buffers[i])
+	}
+	wg.Wait()
+
+	for _, buffer := range buffers {
+		if m.sub != nil {
+			compositeSub(m.sub, buffer.sub)
+		} else {
+			compositeLines(m.canvas, buffer.canvas)
+		}
+	}
+}
+
+// lineBuffersFor returns n cleared layer buffers matching the current
+// canvas and sub-cell raster, reusing earlier ones where possible
+func (m *MapRenderer) lineBuffersFor(n int) []*lineBuffer {
+	for len(m.lineBuffers) < n {
+		m.lineBuffers = append(m.lineBuffers, &lineBuffer{})
+	}
+
+	buffers := m.lineBuffers[:n]
+	for _, buffer := range buffers {
+		if m.sub != nil {
+			if buffer.sub == nil || buffer.sub.width != m.sub.width || buffer.sub.height != m.sub.height {
+				buffer.sub = newSubpixelBuffer(m.sub.width, m.sub.height)
+			} else {
+				buffer.sub.clear()
+			}
+			continue
+		}
+
+		buffer.sub = nil
+		if buffer.canvas == nil || buffer.canvas.Width() != m.canvas.Width() || buffer.canvas.Height() != m.canvas.Height() {
+			buffer.canvas = NewCanvas(m.canvas.Width(), m.canvas.Height())
+		} else {
+			buffer.canvas.Clear()
+		}
+	}
+	return buffers
+}
+
+// compositeLines copies the drawn cells of a layer canvas onto dst,
+// merging strokes that cross strokes of a lower layer into junctions
+func compositeLines(dst, layer *Canvas) {
+	for y := 0; y < layer.height; y++ {
+		for x, cell := range layer.cells[y] {
+			if cell.Char == ' ' {
+				continue
+			}
+			char := cell.Char
+			if existing := dst.cells[y][x].Char; isSlopeGlyph(existing) && isSlopeGlyph(char) {
+				char = junctionGlyph(existing, char)
+			}
+			dst.Set(x, y, char, cell.Style)
+		}
+	}
+}
+
+// compositeSub copies the drawn sub-cells of a layer buffer onto dst
+func compositeSub(dst, layer *subpixelBuffer) {
+	for i, color := range layer.pixels {
+		if color != tcell.ColorDefault {
+			dst.pixels[i] = color
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package render
+
+import "math"
+
+// sparkLevels are the bar heights of a sparkline, lowest first
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws values as a row of bars scaled between low and high
+// NaN values are gaps and draw as spaces; when low equals high every
+// value draws at mid height.
+func Sparkline(values []float64, low, high float64) string {
+	bars := make([]rune, len(values))
+	for i, value := range values {
+		switch {
+		case math.IsNaN(value):
+			bars[i] = ' '
+		case high <= low:
+			bars[i] = sparkLevels[len(sparkLevels)/2-1]
+		default:
+			level := int(math.Round((value - low) / (high - low) * float64(len(sparkLevels)-1)))
+			bars[i] = sparkLevels[max(min(level, len(sparkLevels)-1), 0)]
+		}
+	}
+	return string(bars)
+}
@@ -8,16 +8,30 @@ import (
 
 // Style definitions for different map features
 var (
-	StyleStateBorder = tcell.StyleDefault.Foreground(tcell.ColorDarkGray)
-	StyleHighway     = tcell.StyleDefault.Foreground(tcell.ColorYellow)
-	StyleRiver       = tcell.StyleDefault.Foreground(tcell.ColorDarkCyan)
-	StyleCoastline   = tcell.StyleDefault.Foreground(tcell.ColorDarkBlue)
-	StyleCity        = tcell.StyleDefault.Foreground(tcell.ColorWhite)
-	StyleAirport     = tcell.StyleDefault.Foreground(tcell.ColorOrange)
-	StyleAircraft    = tcell.StyleDefault.Foreground(tcell.ColorGreen).Bold(true)
-	StyleSelected    = tcell.StyleDefault.Foreground(tcell.ColorGreen).Bold(true).Reverse(true)
-	StyleLabel       = tcell.StyleDefault.Foreground(tcell.ColorWhite)
-	StyleListItem    = tcell.StyleDefault.Foreground(tcell.ColorWhite)
+	StyleStateBorder  = tcell.StyleDefault.Foreground(tcell.ColorDarkGray)
+	StyleHighway      = tcell.StyleDefault.Foreground(tcell.ColorYellow)
+	StyleRiver        = tcell.StyleDefault.Foreground(tcell.ColorDarkCyan)
+	StyleCoastline    = tcell.StyleDefault.Foreground(tcell.ColorDarkBlue)
+	StyleCity         = tcell.StyleDefault.Foreground(tcell.ColorWhite)
+	StyleAirport      = tcell.StyleDefault.Foreground(tcell.ColorOrange)
+	StyleOverlay      = tcell.StyleDefault.Foreground(tcell.ColorFuchsia)
+	StyleWaypoint     = tcell.StyleDefault.Foreground(tcell.ColorAqua)
+	StyleAirspaceB    = tcell.StyleDefault.Foreground(tcell.ColorBlue)
+	StyleAirspaceC    = tcell.StyleDefault.Foreground(tcell.ColorPurple)
+	StyleAirspaceD    = tcell.StyleDefault.Foreground(tcell.ColorSteelBlue)
+	StyleNavaid       = tcell.StyleDefault.Foreground(tcell.ColorTeal)
+	StyleRunway       = tcell.StyleDefault.Foreground(tcell.ColorSilver).Bold(true)
+	StyleRoute        = tcell.StyleDefault.Foreground(tcell.ColorFuchsia)
+	StyleRing         = tcell.StyleDefault.Foreground(tcell.ColorGray)
+	StyleTimeZone     = tcell.StyleDefault.Foreground(tcell.ColorDarkSlateGray).Dim(true)
+	StyleAircraft     = tcell.StyleDefault.Foreground(tcell.ColorGreen).Bold(true)
+	StyleSelected     = tcell.StyleDefault.Foreground(tcell.ColorGreen).Bold(true).Reverse(true)
+	StyleEmergency    = tcell.StyleDefault.Foreground(tcell.ColorRed).Bold(true)
+	StyleWatch        = tcell.StyleDefault.Foreground(tcell.ColorYellow).Bold(true)
+	StyleLabel        = tcell.StyleDefault.Foreground(tcell.ColorWhite)
+	StyleWaterLabel   = tcell.StyleDefault.Foreground(tcell.ColorDarkCyan).Italic(true)
+	StyleListItem     = tcell.StyleDefault.Foreground(tcell.ColorWhite)
+	StyleStatusBar    = tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(tcell.ColorSilver)
 	StyleListSelected = tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(tcell.ColorWhite)
 )
 
@@ -36,6 +50,16 @@ func GetStyleForFeature(ftype geo.FeatureType) tcell.Style {
 		return StyleCity
 	case geo.FeatureAirport:
 		return StyleAirport
+	case geo.FeatureOverlay:
+		return StyleOverlay
+	case geo.FeatureWaypoint:
+		return StyleWaypoint
+	case geo.FeatureNavaid:
+		return StyleNavaid
+	case geo.FeatureRunway:
+		return StyleRunway
+	case geo.FeatureTimeZone:
+		return StyleTimeZone
 	default:
 		return tcell.StyleDefault
 	}
@@ -45,14 +69,95 @@ func GetStyleForFeature(ftype geo.FeatureType) tcell.Style {
 func GetCharForFeature(ftype geo.FeatureType) rune {
 	switch ftype {
 	case geo.FeatureStateBorder:
-		return '-' // Simple dash for borders
+		return SlopeGlyph // Slope-aware strokes for borders
 	case geo.FeatureHighway:
 		return '=' // Double line for highways
 	case geo.FeatureRiver:
 		return '~' // Wavy for rivers
 	case geo.FeatureCoastline:
-		return '-' // Dash for coastlines
+		return SlopeGlyph // Slope-aware strokes for coastlines
+	case geo.FeatureOverlay:
+		return '+' // Plus for user overlays
+	case geo.FeatureRunway:
+		return '#' // Solid block for runways
+	case geo.FeatureTimeZone:
+		return ':' // Dotted for time zone boundaries
 	default:
 		return '·'
 	}
 }
+
+// GetStyleForOverlay returns the style for a user overlay feature, honoring
+// the simplestyle-spec "stroke" (lines) and "marker-color" (points)
+// properties. Colors may be names ("red") or hex ("#ff8800").
+func GetStyleForOverlay(feature *geo.Feature) tcell.Style {
+	key := "stroke"
+	if feature.IsPoint() {
+		key = "marker-color"
+	}
+
+	if value, ok := feature.Properties[key].(string); ok && value != "" {
+		if color := tcell.GetColor(value); color != tcell.ColorDefault {
+			return tcell.StyleDefault.Foreground(color)
+		}
+	}
+
+	return StyleOverlay
+}
+
+// GetCharForOverlay returns the drawing character for a user overlay
+// feature, taken from the first rune of "marker-symbol" (points) or
+// "stroke-char" (lines) when present
+func GetCharForOverlay(feature *geo.Feature) rune {
+	key := "stroke-char"
+	fallback := GetCharForFeature(geo.FeatureOverlay)
+	if feature.IsPoint() {
+		key = "marker-symbol"
+		fallback = '*'
+	}
+
+	if value, ok := feature.Properties[key].(string); ok && value != "" {
+		for _, r := range value {
+			return r
+		}
+	}
+
+	return fallback
+}
+
+// GetStyleForAirspace returns the style for an airspace boundary by class
+// Colors follow VFR sectional conventions: B blue, C magenta, D dashed blue
+func GetStyleForAirspace(class string) tcell.Style {
+	switch class {
+	case "B":
+		return StyleAirspaceB
+	case "C":
+		return StyleAirspaceC
+	default:
+		return StyleAirspaceD
+	}
+}
+
+// GetCharForAirspace returns the drawing character for an airspace class
+func GetCharForAirspace(class string) rune {
+	switch class {
+	case "B":
+		return '#'
+	case "C":
+		return ':'
+	default:
+		return '.'
+	}
+}
+
+// GetCharForNavaid returns the map symbol for a navaid kind (VOR, NDB, DME)
+func GetCharForNavaid(kind string) rune {
+	switch kind {
+	case "VOR":
+		return '⊙'
+	case "NDB":
+		return '○'
+	default:
+		return '□'
+	}
+}
@@ -0,0 +1,203 @@
+package render
+
+import (
+	"ascii1090/internal/geo"
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// RenderMode selects how map line features are rasterized into cells
+type RenderMode int
+
+const (
+	RenderModeText      RenderMode = iota // One character per cell, per-feature glyphs
+	RenderModeHalfBlock                   // ▀▄█ with fg/bg colors, 1x2 sub-cells
+	RenderModeQuadrant                    // Quadrant blocks, 2x2 sub-cells
+	RenderModeSixel                       // Map lines as a sixel image
+	RenderModeKitty                       // Map lines as a Kitty graphics protocol image
+)
+
+// IsGraphics reports whether map lines are sent to the terminal as an image
+func (r RenderMode) IsGraphics() bool {
+	return r == RenderModeSixel || r == RenderModeKitty
+}
+
+// String returns the flag/config spelling of the render mode
+func (r RenderMode) String() string {
+	switch r {
+	case RenderModeHalfBlock:
+		return "halfblock"
+	case RenderModeQuadrant:
+		return "quadrant"
+	case RenderModeSixel:
+		return "sixel"
+	case RenderModeKitty:
+		return "kitty"
+	default:
+		return "text"
+	}
+}
+
+// ParseRenderMode parses "text", "halfblock" or "quadrant"
+func ParseRenderMode(s string) (RenderMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "text", "":
+		return RenderModeText, nil
+	case "halfblock", "half":
+		return RenderModeHalfBlock, nil
+	case "quadrant", "quad":
+		return RenderModeQuadrant, nil
+	case "sixel":
+		return RenderModeSixel, nil
+	case "kitty":
+		return RenderModeKitty, nil
+	default:
+		return RenderModeText, fmt.Errorf("unknown render mode %q (use text, halfblock, quadrant, sixel or kitty)", s)
+	}
+}
+
+// subCells returns how many sub-cells wide and tall each character cell is
+// for the block modes; graphics modes depend on the terminal's cell size
+func (r RenderMode) subCells() (sx, sy int) {
+	switch r {
+	case RenderModeHalfBlock:
+		return 1, 2
+	case RenderModeQuadrant:
+		return 2, 2
+	default:
+		return 1, 1
+	}
+}
+
+// quadrantGlyphs maps a 4-bit mask (1=top-left, 2=top-right, 4=bottom-left,
+// 8=bottom-right) to the Unicode quadrant block with those cells filled
+var quadrantGlyphs = [16]rune{' ', '▘', '▝', '▀', '▖', '▌', '▞', '▛', '▗', '▚', '▐', '▜', '▄', '▙', '▟', '█'}
+
+// subpixelBuffer is a higher-resolution color grid that line features are
+// drawn into before being folded back into character cells
+type subpixelBuffer struct {
+	width  int
+	height int
+	pixels []tcell.Color // ColorDefault means empty
+}
+
+// newSubpixelBuffer creates an empty buffer of the given sub-cell size
+func newSubpixelBuffer(width, height int) *subpixelBuffer {
+	return &subpixelBuffer{
+		width:  width,
+		height: height,
+		pixels: make([]tcell.Color, width*height),
+	}
+}
+
+// clear empties every sub-cell
+func (b *subpixelBuffer) clear() {
+	for i := range b.pixels {
+		b.pixels[i] = tcell.ColorDefault
+	}
+}
+
+// set colors one sub-cell
+func (b *subpixelBuffer) set(x, y int, color tcell.Color) {
+	if x >= 0 && x < b.width && y >= 0 && y < b.height {
+		b.pixels[y*b.width+x] = color
+	}
+}
+
+// get returns the color of one sub-cell (ColorDefault if empty)
+func (b *subpixelBuffer) get(x, y int) tcell.Color {
+	if x >= 0 && x < b.width && y >= 0 && y < b.height {
+		return b.pixels[y*b.width+x]
+	}
+	return tcell.ColorDefault
+}
+
+// line draws a Bresenham line in sub-cell coordinates
+func (b *subpixelBuffer) line(x0, y0, x1, y1 int, color tcell.Color) {
+	dx := abs(x1 - x0)
+	dy := abs(y1 - y0)
+
+	sx := -1
+	if x0 < x1 {
+		sx = 1
+	}
+
+	sy := -1
+	if y0 < y1 {
+		sy = 1
+	}
+
+	err := dx - dy
+
+	for {
+		b.set(x0, y0, color)
+
+		if x0 == x1 && y0 == y1 {
+			break
+		}
+
+		e2 := 2 * err
+
+		if e2 > -dy {
+			err -= dy
+			x0 += sx
+		}
+
+		if e2 < dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// composite folds the buffer into canvas cells using block glyphs
+func (b *subpixelBuffer) composite(canvas *Canvas, mode RenderMode) {
+	for y := 0; y < canvas.Height(); y++ {
+		for x := 0; x < canvas.Width(); x++ {
+			switch mode {
+			case RenderModeHalfBlock:
+				top := b.get(x, y*2)
+				bottom := b.get(x, y*2+1)
+				switch {
+				case top == tcell.ColorDefault && bottom == tcell.ColorDefault:
+				case top == bottom:
+					canvas.Set(x, y, '█', tcell.StyleDefault.Foreground(top))
+				case bottom == tcell.ColorDefault:
+					canvas.Set(x, y, '▀', tcell.StyleDefault.Foreground(top))
+				case top == tcell.ColorDefault:
+					canvas.Set(x, y, '▄', tcell.StyleDefault.Foreground(bottom))
+				default:
+					canvas.Set(x, y, '▀', tcell.StyleDefault.Foreground(top).Background(bottom))
+				}
+
+			case RenderModeQuadrant:
+				quads := [4]tcell.Color{
+					b.get(x*2, y*2), b.get(x*2+1, y*2),
+					b.get(x*2, y*2+1), b.get(x*2+1, y*2+1),
+				}
+				mask := 0
+				color := tcell.ColorDefault
+				for i, c := range quads {
+					if c != tcell.ColorDefault {
+						mask |= 1 << i
+						// Later layers win, matching text mode overdraw order
+						color = c
+					}
+				}
+				if mask != 0 {
+					canvas.Set(x, y, quadrantGlyphs[mask], tcell.StyleDefault.Foreground(color))
+				}
+			}
+		}
+	}
+}
+
+// subProjection derives a projection at sub-cell resolution from a cell
+// projection, keeping the same geographic extent
+func subProjection(p *geo.Projection, sx, sy int) *geo.Projection {
+	st := p.State()
+	return geo.NewProjection(st.CenterLat, st.CenterLon, st.RadiusMiles,
+		st.ScreenWidth*sx, st.ScreenHeight*sy, st.AspectRatio*float64(sx)/float64(sy))
+}
@@ -0,0 +1,108 @@
+package render
+
+import (
+	"ascii1090/internal/adsb"
+	"fmt"
+	"strings"
+)
+
+// SymbolSet selects how aircraft are drawn on the map
+type SymbolSet int
+
+const (
+	SymbolsArrows   SymbolSet = iota // ^ ┐ > ┘ v └ < ┌ by heading
+	SymbolsPlane                     // ✈ eastbound, Unicode arrows for other headings
+	SymbolsCategory                  // Emitter category letter (H helicopter, ...), arrows if unknown
+)
+
+// String returns the flag/theme spelling of the symbol set
+func (s SymbolSet) String() string {
+	switch s {
+	case SymbolsPlane:
+		return "plane"
+	case SymbolsCategory:
+		return "category"
+	default:
+		return "arrows"
+	}
+}
+
+// ParseSymbolSet parses "arrows", "plane" or "category"
+func ParseSymbolSet(s string) (SymbolSet, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "arrows", "arrow", "":
+		return SymbolsArrows, nil
+	case "plane", "airplane":
+		return SymbolsPlane, nil
+	case "category", "categories":
+		return SymbolsCategory, nil
+	default:
+		return SymbolsArrows, fmt.Errorf("unknown symbol set %q (use arrows, plane or category)", s)
+	}
+}
+
+// aircraftSymbols is the symbol set in use
+var aircraftSymbols = SymbolsArrows
+
+// SetSymbolSet selects how aircraft are drawn
+func SetSymbolSet(set SymbolSet) {
+	aircraftSymbols = set
+}
+
+// planeSymbols approximate a rotated ✈ in 8 directions, N first. The glyph
+// itself points east, so it is used as-is there and arrows stand in for
+// the other headings.
+var planeSymbols = [8]rune{'↑', '↗', '✈', '↘', '↓', '↙', '←', '↖'}
+
+// categoryLetters maps ADS-B emitter categories to single-letter codes
+var categoryLetters = map[string]rune{
+	"A1": 'L', // Light (< 15,500 lb)
+	"A2": 'S', // Small (15,500 - 75,000 lb)
+	"A3": 'J', // Large (75,000 - 300,000 lb)
+	"A4": 'J', // High-vortex large (B757)
+	"A5": 'W', // Heavy (> 300,000 lb)
+	"A6": 'F', // High performance
+	"A7": 'H', // Rotorcraft
+	"B1": 'G', // Glider / sailplane
+	"B2": 'B', // Lighter than air
+	"B3": 'P', // Parachutist / skydiver
+	"B4": 'U', // Ultralight / paraglider
+	"B6": 'D', // Unmanned aerial vehicle
+	"B7": 'X', // Space vehicle
+	"C1": 'V', // Surface emergency vehicle
+	"C2": 'V', // Surface service vehicle
+	"C3": 'O', // Point obstacle
+}
+
+// distinctCategories keep their category letter in every symbol set, since
+// a helicopter or a ground vehicle moves unlike the aircraft around it
+var distinctCategories = map[string]bool{"A7": true, "C1": true, "C2": true}
+
+// aircraftSymbol picks the map glyph for an aircraft in the current set
+func aircraftSymbol(ac *adsb.Aircraft) rune {
+	category := strings.ToUpper(ac.Category)
+	if distinctCategories[category] {
+		return categoryLetters[category]
+	}
+
+	switch aircraftSymbols {
+	case SymbolsPlane:
+		return planeSymbols[headingIndex(ac)]
+	case SymbolsCategory:
+		if letter, ok := categoryLetters[category]; ok {
+			return letter
+		}
+	}
+	return ac.CardinalDirection()
+}
+
+// headingIndex returns the 8-way direction (0 = N, clockwise) the aircraft
+// is moving, preferring track over heading like CardinalDirection
+func headingIndex(ac *adsb.Aircraft) int {
+	direction := ac.Track
+	if direction == 0 && ac.Heading != 0 {
+		direction = ac.Heading
+	}
+	direction = (direction%360 + 360) % 360
+	return ((direction + 22) % 360) / 45
+}
@@ -0,0 +1,454 @@
+package render
+
+import (
+	"ascii1090/internal/config"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Theme is a named set of styles keyed by style name (see ThemeKeys).
+// Styles missing from a theme keep their current value when applied, so a
+// theme file only needs to list what it changes.
+type Theme struct {
+	Name           string
+	Styles         map[string]tcell.Style
+	AltitudeColors bool   // Color aircraft by altitude on 256+ color terminals
+	Symbols        string // Aircraft symbol set name, empty to leave unchanged
+}
+
+// styleVars maps theme keys to the live style variables used when drawing
+var styleVars = map[string]*tcell.Style{
+	"state_border":  &StyleStateBorder,
+	"highway":       &StyleHighway,
+	"river":         &StyleRiver,
+	"coastline":     &StyleCoastline,
+	"city":          &StyleCity,
+	"airport":       &StyleAirport,
+	"overlay":       &StyleOverlay,
+	"waypoint":      &StyleWaypoint,
+	"airspace_b":    &StyleAirspaceB,
+	"airspace_c":    &StyleAirspaceC,
+	"airspace_d":    &StyleAirspaceD,
+	"navaid":        &StyleNavaid,
+	"runway":        &StyleRunway,
+	"route":         &StyleRoute,
+	"ring":          &StyleRing,
+	"coverage":      &StyleCoverage,
+	"time_zone":     &StyleTimeZone,
+	"aircraft":      &StyleAircraft,
+	"selected":      &StyleSelected,
+	"emergency":     &StyleEmergency,
+	"watch":         &StyleWatch,
+	"label":         &StyleLabel,
+	"water_label":   &StyleWaterLabel,
+	"list_item":     &StyleListItem,
+	"list_selected": &StyleListSelected,
+	"status_bar":    &StyleStatusBar,
+	"vfr":           &StyleVFR,
+	"mvfr":          &StyleMVFR,
+	"ifr":           &StyleIFR,
+	"lifr":          &StyleLIFR,
+	"wind":          &StyleWind,
+}
+
+// themeGeneration changes whenever the live styles do, so cached renders
+// know to redraw
+var themeGeneration uint64
+
+// altitudeColors enables the altitude gradient for aircraft
+var altitudeColors = true
+
+// monochrome switches feature glyphs that normally rely on color to tell
+// them apart (METAR categories) to distinct characters
+var monochrome = false
+
+// IsMonochrome reports whether monochrome mode is on
+func IsMonochrome() bool {
+	return monochrome
+}
+
+// SetMonochrome applies the colorless "mono" theme and glyph choices
+func SetMonochrome(enabled bool) {
+	monochrome = enabled
+	if enabled {
+		ApplyTheme(BundledTheme("mono"))
+	}
+}
+
+// ThemeKeys returns every style name a theme can set, sorted
+func ThemeKeys() []string {
+	keys := make([]string, 0, len(styleVars))
+	for key := range styleVars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CurrentTheme captures the styles currently in use
+func CurrentTheme() *Theme {
+	theme := &Theme{Name: "current", Styles: make(map[string]tcell.Style), AltitudeColors: altitudeColors}
+	for key, style := range styleVars {
+		theme.Styles[key] = *style
+	}
+	return theme
+}
+
+// ApplyTheme makes a theme's styles the live styles
+func ApplyTheme(theme *Theme) {
+	if theme == nil {
+		return
+	}
+	for key, style := range theme.Styles {
+		if target, ok := styleVars[key]; ok {
+			*target = style
+		}
+	}
+	altitudeColors = theme.AltitudeColors
+	themeGeneration++
+	if set, err := ParseSymbolSet(theme.Symbols); err == nil && theme.Symbols != "" {
+		aircraftSymbols = set
+	}
+}
+
+// ParseStyle parses a style spec of the form "fg [on bg] [attributes...]",
+// e.g. "yellow", "#ff8800 bold" or "black on silver". Colors are tcell
+// names or #rrggbb; attributes are bold, dim, italic, reverse, underline
+// and blink.
+func ParseStyle(spec string) (tcell.Style, error) {
+	style := tcell.StyleDefault
+	fields := strings.Fields(strings.ToLower(spec))
+	if len(fields) == 0 {
+		return style, fmt.Errorf("empty style")
+	}
+
+	colorSet := false
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		switch field {
+		case "bold":
+			style = style.Bold(true)
+		case "dim":
+			style = style.Dim(true)
+		case "italic":
+			style = style.Italic(true)
+		case "reverse":
+			style = style.Reverse(true)
+		case "underline":
+			style = style.Underline(true)
+		case "blink":
+			style = style.Blink(true)
+		case "on":
+			if i+1 >= len(fields) {
+				return style, fmt.Errorf("missing background color in %q", spec)
+			}
+			i++
+			color, err := parseColor(fields[i])
+			if err != nil {
+				return style, err
+			}
+			style = style.Background(color)
+		default:
+			if colorSet {
+				return style, fmt.Errorf("unexpected %q in style %q", field, spec)
+			}
+			color, err := parseColor(field)
+			if err != nil {
+				return style, err
+			}
+			style = style.Foreground(color)
+			colorSet = true
+		}
+	}
+
+	return style, nil
+}
+
+// parseColor parses a tcell color name or #rrggbb
+func parseColor(name string) (tcell.Color, error) {
+	if name == "default" {
+		return tcell.ColorDefault, nil
+	}
+	color := tcell.GetColor(name)
+	if color == tcell.ColorDefault {
+		return color, fmt.Errorf("unknown color %q", name)
+	}
+	return color, nil
+}
+
+// LoadThemeFile reads a theme from a TOML file:
+//
+//	name = "mytheme"
+//	altitude_colors = true
+//	aircraft_symbols = "plane"
+//	[styles]
+//	highway = "#b89a3c"
+//	status_bar = "black on silver"
+func LoadThemeFile(path string) (*Theme, error) {
+	doc, err := config.ParseFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	theme := &Theme{
+		Name:           doc.String("", "name", name),
+		Styles:         make(map[string]tcell.Style),
+		AltitudeColors: doc.Bool("", "altitude_colors", true),
+		Symbols:        doc.String("", "aircraft_symbols", ""),
+	}
+
+	if _, err := ParseSymbolSet(theme.Symbols); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	for key, value := range doc.Table("styles") {
+		if _, ok := styleVars[key]; !ok {
+			return nil, fmt.Errorf("%s: unknown style %q", path, key)
+		}
+		spec, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: style %q must be a string", path, key)
+		}
+		style, err := ParseStyle(spec)
+		if err != nil {
+			return nil, fmt.Errorf("%s: style %q: %w", path, key, err)
+		}
+		theme.Styles[key] = style
+	}
+
+	return theme, nil
+}
+
+// LoadTheme resolves a -theme argument: a bundled theme name, a path to a
+// TOML file, or the name of a file in themesDir (without .toml)
+func LoadTheme(nameOrPath, themesDir string) (*Theme, error) {
+	if theme := BundledTheme(nameOrPath); theme != nil {
+		return theme, nil
+	}
+
+	if _, err := os.Stat(nameOrPath); err == nil {
+		return LoadThemeFile(nameOrPath)
+	}
+
+	if themesDir != "" {
+		path := filepath.Join(themesDir, nameOrPath+".toml")
+		if _, err := os.Stat(path); err == nil {
+			return LoadThemeFile(path)
+		}
+	}
+
+	return nil, fmt.Errorf("theme %q not found (bundled themes: %s)", nameOrPath, strings.Join(BundledThemeNames(), ", "))
+}
+
+// bundledThemes are style specs for the themes shipped with ascii1090.
+// "default" is captured from the built-in styles at startup.
+var bundledThemes = map[string]map[string]string{
+	"amber": {
+		"state_border":  "#7a4a00",
+		"highway":       "#b06a00",
+		"river":         "#6a4000 dim",
+		"coastline":     "#8a5400",
+		"city":          "#ffb000",
+		"airport":       "#ffc040 bold",
+		"overlay":       "#ffb000",
+		"waypoint":      "#ffb000",
+		"airspace_b":    "#9a6000",
+		"airspace_c":    "#9a6000 dim",
+		"airspace_d":    "#9a6000 dim",
+		"navaid":        "#c08000",
+		"runway":        "#ffc040 bold",
+		"route":         "#ffd080",
+		"ring":          "#6a4000",
+		"time_zone":     "#4a3000 dim",
+		"aircraft":      "#ffd080 bold",
+		"selected":      "#ffd080 bold reverse",
+		"emergency":     "#ffffff bold",
+		"watch":         "#ffd080 bold underline",
+		"label":         "#ffb000",
+		"water_label":   "#8a5400 italic",
+		"list_item":     "#ffb000",
+		"list_selected": "black on #ffb000",
+		"status_bar":    "black on #b06a00",
+		"vfr":           "#ffb000",
+		"mvfr":          "#c08000",
+		"ifr":           "#ffd080 bold",
+		"lifr":          "#ffd080 bold reverse",
+		"wind":          "#8a5400",
+	},
+	"solarized": {
+		"state_border":  "#586e75",
+		"highway":       "#b58900",
+		"river":         "#2aa198",
+		"coastline":     "#268bd2",
+		"city":          "#eee8d5",
+		"airport":       "#cb4b16",
+		"overlay":       "#d33682",
+		"waypoint":      "#2aa198",
+		"airspace_b":    "#268bd2",
+		"airspace_c":    "#6c71c4",
+		"airspace_d":    "#268bd2 dim",
+		"navaid":        "#2aa198",
+		"runway":        "#93a1a1 bold",
+		"route":         "#d33682",
+		"ring":          "#586e75",
+		"time_zone":     "#073642",
+		"aircraft":      "#859900 bold",
+		"selected":      "#859900 bold reverse",
+		"emergency":     "#dc322f bold",
+		"watch":         "#b58900 bold",
+		"label":         "#93a1a1",
+		"water_label":   "#2aa198 italic",
+		"list_item":     "#93a1a1",
+		"list_selected": "#002b36 on #93a1a1",
+		"status_bar":    "#002b36 on #839496",
+		"vfr":           "#859900",
+		"mvfr":          "#268bd2",
+		"ifr":           "#dc322f",
+		"lifr":          "#d33682",
+		"wind":          "#93a1a1",
+	},
+	// Low brightness for dark rooms: map features dimmed, aircraft and
+	// text red-shifted to preserve night vision
+	"night": {
+		"state_border":  "#3a2020 dim",
+		"highway":       "#4a3010 dim",
+		"river":         "#202c3a dim",
+		"coastline":     "#24304a dim",
+		"city":          "#7a3030",
+		"airport":       "#8a3a20",
+		"overlay":       "#6a2040",
+		"waypoint":      "#5a2030",
+		"airspace_b":    "#2a2a5a dim",
+		"airspace_c":    "#4a2040 dim",
+		"airspace_d":    "#2a2a4a dim",
+		"navaid":        "#5a2a2a",
+		"runway":        "#6a3030",
+		"route":         "#8a2040",
+		"ring":          "#3a1818 dim",
+		"time_zone":     "#201818 dim",
+		"aircraft":      "#d02020 bold",
+		"selected":      "#ff3030 bold reverse",
+		"emergency":     "#ff4040 bold",
+		"watch":         "#c06020 bold",
+		"label":         "#902828",
+		"water_label":   "#283448 italic",
+		"list_item":     "#902828",
+		"list_selected": "black on #902828",
+		"status_bar":    "#ff6040 on #300808",
+		"vfr":           "#6a2020",
+		"mvfr":          "#802030",
+		"ifr":           "#b02020",
+		"lifr":          "#d02020 bold",
+		"wind":          "#5a2020",
+	},
+	// No color at all: features are told apart by glyph and intensity
+	"mono": {
+		"state_border":  "default dim",
+		"highway":       "default",
+		"river":         "default dim",
+		"coastline":     "default bold",
+		"city":          "default",
+		"airport":       "default bold",
+		"overlay":       "default underline",
+		"waypoint":      "default underline",
+		"airspace_b":    "default dim",
+		"airspace_c":    "default",
+		"airspace_d":    "default dim",
+		"navaid":        "default",
+		"runway":        "default bold",
+		"route":         "default bold",
+		"ring":          "default dim",
+		"time_zone":     "default dim",
+		"aircraft":      "default bold",
+		"selected":      "default bold reverse",
+		"emergency":     "default bold",
+		"watch":         "default bold underline",
+		"label":         "default",
+		"water_label":   "default dim italic",
+		"list_item":     "default",
+		"list_selected": "default reverse",
+		"status_bar":    "default reverse",
+		"vfr":           "default dim",
+		"mvfr":          "default",
+		"ifr":           "default bold",
+		"lifr":          "default bold reverse",
+		"wind":          "default dim",
+	},
+	"high-contrast": {
+		"state_border":  "white",
+		"highway":       "yellow bold",
+		"river":         "aqua",
+		"coastline":     "aqua bold",
+		"city":          "white bold",
+		"airport":       "yellow bold",
+		"overlay":       "fuchsia bold",
+		"waypoint":      "aqua bold",
+		"airspace_b":    "blue bold",
+		"airspace_c":    "fuchsia",
+		"airspace_d":    "blue",
+		"navaid":        "aqua",
+		"runway":        "white bold",
+		"route":         "fuchsia bold",
+		"ring":          "white",
+		"time_zone":     "gray",
+		"aircraft":      "lime bold",
+		"selected":      "black on lime bold",
+		"emergency":     "red bold",
+		"watch":         "yellow bold",
+		"label":         "white bold",
+		"water_label":   "aqua italic",
+		"list_item":     "white bold",
+		"list_selected": "black on white",
+		"status_bar":    "black on white",
+		"vfr":           "lime bold",
+		"mvfr":          "blue bold",
+		"ifr":           "red bold",
+		"lifr":          "fuchsia bold",
+		"wind":          "white",
+	},
+}
+
+// defaultTheme is the built-in style set, captured before any theme or
+// color depth changes are applied
+var defaultTheme = CurrentTheme()
+
+// BundledThemeNames returns the names of the themes shipped with ascii1090
+func BundledThemeNames() []string {
+	names := []string{"default"}
+	for name := range bundledThemes {
+		names = append(names, name)
+	}
+	sort.Strings(names[1:])
+	return names
+}
+
+// BundledTheme returns a shipped theme by name, or nil if there isn't one
+func BundledTheme(name string) *Theme {
+	if name == "default" {
+		theme := *defaultTheme
+		theme.Name = "default"
+		return &theme
+	}
+
+	specs, ok := bundledThemes[name]
+	if !ok {
+		return nil
+	}
+
+	theme := &Theme{Name: name, Styles: make(map[string]tcell.Style), AltitudeColors: name != "amber" && name != "night" && name != "mono"}
+	for key, spec := range specs {
+		style, err := ParseStyle(spec)
+		if err != nil {
+			// Bundled specs are fixed, so this is a programming error
+			panic(fmt.Sprintf("bundled theme %s: %s: %v", name, key, err))
+		}
+		theme.Styles[key] = style
+	}
+	return theme
+}
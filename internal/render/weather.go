@@ -0,0 +1,76 @@
+package render
+
+import (
+	"ascii1090/internal/weather"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Flight category colors follow the usual aviation weather convention
+var (
+	StyleVFR  = tcell.StyleDefault.Foreground(tcell.ColorGreen)
+	StyleMVFR = tcell.StyleDefault.Foreground(tcell.ColorBlue)
+	StyleIFR  = tcell.StyleDefault.Foreground(tcell.ColorRed)
+	StyleLIFR = tcell.StyleDefault.Foreground(tcell.ColorFuchsia)
+	StyleWind = tcell.StyleDefault.Foreground(tcell.ColorSilver)
+)
+
+// GetStyleForCategory returns the style for a METAR flight category dot
+func GetStyleForCategory(category weather.FlightCategory) tcell.Style {
+	switch category {
+	case weather.CategoryVFR:
+		return StyleVFR
+	case weather.CategoryMVFR:
+		return StyleMVFR
+	case weather.CategoryIFR:
+		return StyleIFR
+	case weather.CategoryLIFR:
+		return StyleLIFR
+	default:
+		return StyleLabel.Dim(true)
+	}
+}
+
+// GetCharForCategory returns the station marker for a flight category
+// Colors carry the category normally; in monochrome the glyph does
+func GetCharForCategory(category weather.FlightCategory) rune {
+	if !monochrome {
+		return '•'
+	}
+	switch category {
+	case weather.CategoryVFR:
+		return '.'
+	case weather.CategoryMVFR:
+		return 'o'
+	case weather.CategoryIFR:
+		return 'O'
+	case weather.CategoryLIFR:
+		return '*'
+	default:
+		return '?'
+	}
+}
+
+// windArrow returns an arrow pointing the way the wind blows (downwind)
+func windArrow(fromDegrees int) rune {
+	arrows := []rune{'↓', '↙', '←', '↖', '↑', '↗', '→', '↘'}
+	index := ((fromDegrees%360 + 360 + 22) % 360) / 45
+	return arrows[index]
+}
+
+// RenderWeather draws a flight-category colored dot just left of each
+// reporting station, optionally preceded by a wind arrow
+func (m *MapRenderer) RenderWeather(stations []weather.Station, windBarbs bool) {
+	for _, station := range stations {
+		if !m.projection.IsInBounds(station.Lat, station.Lon) {
+			continue
+		}
+
+		point := m.projection.Project(station.Lat, station.Lon)
+		m.canvas.Set(point.X-1, point.Y, GetCharForCategory(station.Category), GetStyleForCategory(station.Category))
+
+		if windBarbs && station.WindSpeed > 0 && !station.Variable {
+			m.canvas.Set(point.X-2, point.Y, windArrow(station.WindDir), StyleWind)
+		}
+	}
+}
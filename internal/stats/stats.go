@@ -0,0 +1,178 @@
+package stats
+
+import (
+	"ascii1090/internal/adsb"
+	"ascii1090/internal/debug"
+	"ascii1090/internal/geo"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+var log = debug.NewScope("stats")
+
+// FileName is the statistics store in the ascii1090 home directory
+const FileName = "stats.json"
+
+// dateLayout is how days are keyed, in local time
+const dateLayout = "2006-01-02"
+
+// Day is one local calendar day's receiver totals, summed over every
+// session run that day
+type Day struct {
+	Date       string  `json:"date"` // YYYY-MM-DD, local time
+	Aircraft   int     `json:"unique_aircraft"`
+	Messages   int     `json:"messages"`
+	MaxRange   float64 `json:"max_range_miles,omitempty"`
+	MaxRangeBy string  `json:"max_range_by,omitempty"`
+	Hourly     [24]int `json:"hourly_messages"` // Messages by local hour
+
+	// Seen holds the ICAO addresses counted in Aircraft, so a restart
+	// later the same day doesn't count them again; only today keeps it
+	Seen []string `json:"seen,omitempty"`
+}
+
+// BusiestHour returns the local hour with the most messages, and how many
+// there were
+func (d Day) BusiestHour() (hour, messages int) {
+	for h, count := range d.Hourly {
+		if count > messages {
+			hour, messages = h, count
+		}
+	}
+	return hour, messages
+}
+
+// Store keeps per-day totals in a JSON file so receiver performance can
+// be compared across weeks and months
+type Store struct {
+	path  string
+	days  []Day // Oldest first
+	seen  map[string]bool
+	dirty bool
+}
+
+// DefaultPath returns ~/.ascii1090/stats.json
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".ascii1090", FileName), nil
+}
+
+// Open loads the store at path; a missing file starts an empty one
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, seen: make(map[string]bool)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read statistics: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.days); err != nil {
+		return nil, fmt.Errorf("failed to parse statistics %s: %w", path, err)
+	}
+	sort.Slice(s.days, func(i, j int) bool {
+		return s.days[i].Date < s.days[j].Date
+	})
+	if len(s.days) > 0 {
+		for _, icao := range s.days[len(s.days)-1].Seen {
+			s.seen[icao] = true
+		}
+	}
+	return s, nil
+}
+
+// Update folds the tracker's state into today's totals: every aircraft
+// tracked, the messages received since the last call and, with a
+// receiver location, the farthest position
+func (s *Store) Update(now time.Time, aircraft []*adsb.Aircraft, messages int, receiver *geo.LatLon) {
+	day := s.today(now)
+	if messages > 0 {
+		day.Messages += messages
+		day.Hourly[now.Hour()] += messages
+		s.dirty = true
+	}
+
+	for _, ac := range aircraft {
+		if !s.seen[ac.ICAO] {
+			s.seen[ac.ICAO] = true
+			day.Seen = append(day.Seen, ac.ICAO)
+			day.Aircraft++
+			s.dirty = true
+		}
+
+		if receiver == nil || !ac.PositionLocked() {
+			continue
+		}
+		distance := geo.Distance(*receiver, geo.LatLon{Lat: *ac.Latitude, Lon: *ac.Longitude})
+		if distance > day.MaxRange {
+			day.MaxRange = distance
+			day.MaxRangeBy = ac.DisplayName()
+			s.dirty = true
+		}
+	}
+}
+
+// today returns the entry for now's local date, starting a new day when
+// the date has changed
+func (s *Store) today(now time.Time) *Day {
+	date := now.Format(dateLayout)
+	if n := len(s.days); n > 0 && s.days[n-1].Date == date {
+		return &s.days[n-1]
+	}
+
+	// Only the current day needs its ICAO set
+	if n := len(s.days); n > 0 {
+		s.days[n-1].Seen = nil
+	}
+	s.seen = make(map[string]bool)
+	s.days = append(s.days, Day{Date: date})
+	log.Infof("Started statistics for %s", date)
+	return &s.days[len(s.days)-1]
+}
+
+// Days returns the recorded days, newest first
+func (s *Store) Days() []Day {
+	if s == nil {
+		return nil
+	}
+	days := make([]Day, len(s.days))
+	for i, day := range s.days {
+		days[len(s.days)-1-i] = day
+	}
+	return days
+}
+
+// Save writes the store if it changed since the last save, replacing the
+// file in one step so a crash can't leave it half written
+func (s *Store) Save() error {
+	if !s.dirty {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.days, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode statistics: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create statistics directory: %w", err)
+	}
+	partPath := s.path + ".part"
+	if err := os.WriteFile(partPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write statistics: %w", err)
+	}
+	if err := os.Rename(partPath, s.path); err != nil {
+		os.Remove(partPath)
+		return fmt.Errorf("failed to save statistics: %w", err)
+	}
+	s.dirty = false
+	return nil
+}
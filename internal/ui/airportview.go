@@ -0,0 +1,148 @@
+package ui
+
+import (
+	"ascii1090/internal/geo"
+	"ascii1090/internal/render"
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// AirportWidth is the width of the airport popup including its border
+const AirportWidth = 46
+
+// AirportView is a popup describing an airport: its name, codes,
+// elevation and runways, and the arrivals and departures seen there
+type AirportView struct {
+	ident         string
+	title         string
+	lines         []string
+	movements     string
+	units         geo.Units
+	x, y          int
+	width, height int
+}
+
+// NewAirportView creates an empty airport popup showing elevations and
+// runway lengths in units
+func NewAirportView(units geo.Units) *AirportView {
+	return &AirportView{units: units, width: AirportWidth}
+}
+
+// SetAirport fills the popup for an airport and its loaded runways
+func (v *AirportView) SetAirport(airport *geo.Feature, runways []*geo.Feature) {
+	property := func(key string) string {
+		value, _ := airport.Properties[key].(string)
+		return value
+	}
+
+	v.ident = property("ident")
+	v.title = airport.Name
+	v.lines = []string{
+		fmt.Sprintf("Name:      %s", orDash(property("full_name"))),
+	}
+	if city := property("municipality"); city != "" {
+		v.lines = append(v.lines, fmt.Sprintf("City:      %s", city))
+	}
+	v.lines = append(v.lines,
+		fmt.Sprintf("ICAO:      %s", orDash(property("ident"))),
+		fmt.Sprintf("IATA:      %s", orDash(property("iata"))),
+		fmt.Sprintf("Type:      %s", airportTypeString(property("type"))),
+	)
+	if elevation, ok := airport.Properties["elevation_ft"].(float64); ok {
+		v.lines = append(v.lines, fmt.Sprintf("Elevation: %d %s", v.units.Altitude(int(elevation)), v.units.AltitudeUnit()))
+	}
+
+	for i, runway := range runways {
+		label := "           "
+		if i == 0 {
+			label = "Runways:   "
+		}
+		line := label + runway.Name
+		if length, ok := runway.Properties["length_ft"].(float64); ok {
+			line = fmt.Sprintf("%s%-9s %d %s", label, runway.Name, v.units.Altitude(int(length)), v.units.AltitudeUnit())
+		}
+		v.lines = append(v.lines, line)
+	}
+	v.height = len(v.lines) + 3 // Border and the movements line
+}
+
+// Ident returns the ICAO ident of the airport shown
+func (v *AirportView) Ident() string {
+	return v.ident
+}
+
+// SetMovements sets the session's arrival and departure counts
+func (v *AirportView) SetMovements(arrivals, departures int) {
+	v.movements = fmt.Sprintf("Session:   %d arrivals, %d departures", arrivals, departures)
+}
+
+// airportTypeString turns an OurAirports type such as "large_airport"
+// into "Large airport"
+func airportTypeString(kind string) string {
+	if kind == "" {
+		return "-"
+	}
+	kind = strings.ReplaceAll(kind, "_", " ")
+	return strings.ToUpper(kind[:1]) + kind[1:]
+}
+
+// Place puts the popup beside screen cell x, y, keeping it on a screen of
+// the given size below the status bar
+func (v *AirportView) Place(x, y, screenWidth, screenHeight int) {
+	v.x = x + 2
+	if v.x+v.width > screenWidth {
+		v.x = x - v.width - 1
+	}
+	v.x = max(v.x, 0)
+	v.y = max(min(y-1, screenHeight-v.height), 1)
+}
+
+// Draw renders the popup to the screen
+func (v *AirportView) Draw(screen tcell.Screen) {
+	// Clear the panel area first (make it opaque)
+	for row := v.y + 1; row < v.y+v.height-1; row++ {
+		for col := v.x + 1; col < v.x+v.width-1; col++ {
+			screen.SetContent(col, row, ' ', nil, tcell.StyleDefault)
+		}
+	}
+
+	v.drawBorder(screen)
+
+	titleX := v.x + (v.width-len(v.title))/2
+	drawClipped(screen, titleX, v.y, v.x+v.width-1-titleX, v.title, render.StyleAirport)
+
+	for i, line := range append(v.lines, v.movements) {
+		row := v.y + 1 + i
+		if row >= v.y+v.height-1 {
+			break
+		}
+		drawClipped(screen, v.x+2, row, v.width-3, line, render.StyleLabel)
+	}
+}
+
+// drawBorder draws the popup border
+func (v *AirportView) drawBorder(screen tcell.Screen) {
+	style := render.StyleLabel
+
+	screen.SetContent(v.x, v.y, '┌', nil, style)
+	screen.SetContent(v.x+v.width-1, v.y, '┐', nil, style)
+	screen.SetContent(v.x, v.y+v.height-1, '└', nil, style)
+	screen.SetContent(v.x+v.width-1, v.y+v.height-1, '┘', nil, style)
+
+	for i := 1; i < v.width-1; i++ {
+		screen.SetContent(v.x+i, v.y, '─', nil, style)
+		screen.SetContent(v.x+i, v.y+v.height-1, '─', nil, style)
+	}
+
+	for i := 1; i < v.height-1; i++ {
+		screen.SetContent(v.x, v.y+i, '│', nil, style)
+		screen.SetContent(v.x+v.width-1, v.y+i, '│', nil, style)
+	}
+}
+
+// Bounds returns the popup's screen rectangle
+func (v *AirportView) Bounds() (x, y, width, height int) {
+	return v.x, v.y, v.width, v.height
+}
@@ -2,38 +2,153 @@ package ui
 
 import (
 	"ascii1090/internal/adsb"
+	"ascii1090/internal/alert"
+	"ascii1090/internal/cache"
+	"ascii1090/internal/config"
+	"ascii1090/internal/debug"
 	"ascii1090/internal/geo"
+	"ascii1090/internal/render"
+	"ascii1090/internal/stats"
+	"ascii1090/internal/weather"
 	"context"
 	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
 )
 
+// log is the ui package's debug scope
+var log = debug.NewScope("ui")
+
 // ViewMode represents the current view mode
 type ViewMode int
 
 const (
 	ViewModeMap ViewMode = iota
 	ViewModeDetail
+	ViewModeTable
 )
 
+// Options holds user-configurable display settings passed to NewApp
+type Options struct {
+	RadiusMiles   float64                 // Initial map radius in miles
+	ZoomStep      float64                 // Factor + and - change the radius by, DefaultZoomStep if not above 1
+	AspectRatio   float64                 // Character aspect ratio (height/width)
+	AirportLabels render.AirportLabelMode // Airport label style
+	METAR         bool                    // Fetch and display METAR flight categories
+	Routes        *geo.RouteTable         // Callsign to origin/destination lookup, may be nil
+	Stats         *stats.Store            // Daily statistics to add to, may be nil
+	Magnetic      *geo.MagneticModel      // Declination model for magnetic bearings, may be nil
+	CoordFormat   geo.CoordFormat         // Coordinate display format
+	Units         geo.Units               // Units for distances, speeds and altitudes
+	RenderMode    render.RenderMode       // Text or high-density block map lines
+	ColorDepth    render.ColorDepth       // Palette depth, ColorDepthAuto to detect
+	Theme         *render.Theme           // Styles to apply over the defaults, may be nil
+	ASCII         bool                    // Draw with 7-bit ASCII only
+	Monochrome    bool                    // No color, attributes and glyphs only
+	Symbols       *render.SymbolSet       // Aircraft symbol set, nil to keep the theme's
+	Watchlist     []string                // ICAO hex codes, callsigns or registrations to alert on
+	Proximity     *alert.Proximity        // Zone around the receiver to alert on, may be nil
+	Receiver      *geo.LatLon             // Receiver location, may be nil
+	ShowLayers    []render.Layer          // Layers to turn on at startup
+	HideLayers    []render.Layer          // Layers to turn off at startup
+	ScreenshotDir string                  // Where screenshots are saved
+	PositionsOnly bool                    // Start with unlocated aircraft hidden from the list
+	KeyMap        map[rune]rune           // Extra keys mapped to the built-in key they act as
+	Bookmarks     []config.Bookmark       // Saved map views, reached with keys 1-9
+	ConfigPath    string                  // Config file new bookmarks are saved to
+	LocalTime     bool                    // Show local time next to the UTC clock
+	SoundKinds    map[alert.Kind]bool     // Alert kinds that ring the bell or run SoundCommand
+	SoundCommand  string                  // Command to run for alerts instead of the bell
+	ConfirmQuit   bool                    // Ask for a second quit key press before exiting
+	ThemesDir     string                  // Where the :theme command looks for theme files
+	HighwayDetail int                     // Highest road scalerank drawn, changed live with ( and )
+	Cache         *cache.Manager          // Data cache :download re-downloads into, may be nil
+	Startup       StartupFunc             // Loads map data and databases once the UI is up, may be nil
+}
+
 // App is the main application controller
 type App struct {
 	screen      tcell.Screen
 	tracker     *adsb.Tracker
-	dump1090    *adsb.Dump1090Client
-	mapView     *MapView
+	feeds       *adsb.Feeds
+	mapView     *MapView // The current tab's map
+	tabs        []*tab
+	tabIndex    int
 	listView    *ListView
 	detailView  *DetailView
+	legendView  *LegendView
+	tableView   *TableView
+	showLegend  bool
+	feedsView   *FeedsView
+	showFeeds   bool
+	statsView   *StatsView
+	showStats   bool
+	airportView *AirportView
+	showAirport bool
+	mouseDown   bool      // The left button is held, so motion events aren't new clicks
+	cursor      geo.Point // Mouse position, when cursorOnMap
+	cursorOnMap bool      // The mouse is below the status bar; see overMap
+	movements   *airportMovements
+	coverage    *coverageRecorder
+	fetchView   *ProgressView // The latest :download, nil before the first
+	fetchName   string        // Dataset of the latest :download
+	showFetch   bool
+	loadView    *ProgressView // Startup loading, nil without a loader
+	showLoad    bool
+	loaded      chan StartupData
+	startup     StartupFunc
+	tiles       *geo.FeatureTiles // Line features loaded around the map, nil when all are in memory
+	tileLoads   chan tileLoad
+	tileBusy    bool      // A tile load is running
+	tileRetry   time.Time // No tile load is started before this after a failure
+	tileFails   int       // Failed tile loads in a row
+	statusBar   *StatusBar
+	prompt      *Prompt
 	currentView ViewMode
+	detailFrom  ViewMode // View to return to when the detail panel closes
+	detailICAO  string   // Aircraft the detail panel shows
+	receiver    *geo.LatLon
+	units       geo.Units
+	weather     *weather.Fetcher
+	alerts      *alert.Manager
+	sounder     *alert.Sounder
+	graphicsAt  time.Time
+	graphicsDue bool
+	dayTheme    *render.Theme // Styles to restore when night mode is turned off
+	shotDir     string
+	keyMap      map[rune]rune
+	followICAO  string          // Aircraft the map stays centered on, empty when not following
+	filter      *aircraftFilter // The current tab's filter, nil to show all aircraft
+	band        altitudeBand    // The current tab's quick altitude filter
+	themesDir   string
+	cache       *cache.Manager
+	paused      bool
+	frozen      []*adsb.Aircraft // Aircraft as they were when the display was paused
+	bookmarks   []config.Bookmark
+	configPath  string
+	confirmQuit bool
+	quitArmed   time.Time // Until when a second quit key press exits
+	started     time.Time
+	maxRange    float64 // Miles, for the session summary
+	maxRangeBy  string
+	peaks       map[string]Peak // Highest altitude by ICAO, for the session summary
+	stats       *stats.Store
+	statsSaved  time.Time
+	statsTotal  int // Tracker message total at the last statistics update
 	quit        chan struct{}
 	ctx         context.Context
 	cancel      context.CancelFunc
 }
 
 // NewApp creates a new application
-func NewApp(tracker *adsb.Tracker, dump1090 *adsb.Dump1090Client, features map[geo.FeatureType][]*geo.Feature, radiusMiles float64, aspectRatio float64) (*App, error) {
+func NewApp(tracker *adsb.Tracker, feeds *adsb.Feeds, features map[geo.FeatureType][]*geo.Feature, opts Options) (*App, error) {
 	// Initialize tcell screen
 	screen, err := tcell.NewScreen()
 	if err != nil {
@@ -44,38 +159,125 @@ func NewApp(tracker *adsb.Tracker, dump1090 *adsb.Dump1090Client, features map[g
 		return nil, fmt.Errorf("failed to initialize screen: %w", err)
 	}
 
+	if opts.ASCII {
+		screen = render.NewASCIIScreen(screen)
+	}
+
+	colorDepth := opts.ColorDepth
+	if colorDepth == render.ColorDepthAuto {
+		colorDepth = render.DetectColorDepth(screen)
+	}
+	if opts.Monochrome {
+		render.SetMonochrome(true)
+	} else {
+		render.ApplyColorDepth(colorDepth)
+		render.ApplyTheme(opts.Theme)
+	}
+
+	if opts.Symbols != nil {
+		render.SetSymbolSet(*opts.Symbols)
+	}
+
 	screen.SetStyle(tcell.StyleDefault)
+	screen.EnableMouse(tcell.MouseMotionEvents)
 	screen.Clear()
 
 	width, height := screen.Size()
 
-	mapView := NewMapView(width, height, features, radiusMiles, aspectRatio)
+	mapView := NewMapView(width, height, features, opts)
+	coverage := newCoverageRecorder()
+	mapView.SetCoverage(coverage.grid)
 
 	// List view in lower-left corner
-	listWidth := 30
+	listWidth := listWidthFor(opts.Receiver)
 	listHeight := 12
 	listView := NewListView(0, height-listHeight, listWidth, listHeight)
+	listView.SetReceiver(opts.Receiver)
+	listView.SetUnits(opts.Units)
+	if opts.PositionsOnly {
+		listView.TogglePositionsOnly()
+	}
 
 	// Detail view in lower-left corner
 	detailWidth := 50
-	detailHeight := 15
+	detailHeight := 22
 	detailView := NewDetailView(0, height-detailHeight, detailWidth, detailHeight)
+	detailView.SetMaxHeight(height - 1)
+	detailView.SetMagneticModel(opts.Magnetic)
+	detailView.SetCoordFormat(opts.CoordFormat)
+	detailView.SetUnits(opts.Units)
+	detailView.SetRoutes(opts.Routes, mapView.Airports())
+
+	// Legend on the right edge, below the status bar
+	legendView := NewLegendView(width-LegendWidth, 1, LegendWidth, height-1)
+	feedsX, feedsWidth := feedsPlacement(width)
+	feedsView := NewFeedsView(feedsX, 1, feedsWidth, height-1)
+	statsView := NewStatsView(feedsX, 1, feedsWidth, height-1, opts.Units)
+
+	// Aircraft table filling the screen below the status bar
+	tableView := NewTableView(0, 1, width, height-1)
+	tableView.SetUnits(opts.Units)
+
+	// Status bar across the top row
+	statusBar := NewStatusBar(0, 0, width, opts.CoordFormat)
+	statusBar.SetLocalTime(opts.LocalTime)
+	statusBar.SetUnits(opts.Units)
+	prompt := NewPrompt(0, 0, width)
+
+	var fetcher *weather.Fetcher
+	if opts.METAR {
+		fetcher = weather.NewFetcher()
+		mapView.SetWeather(fetcher)
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 
 	app := &App{
 		screen:      screen,
 		tracker:     tracker,
-		dump1090:    dump1090,
+		feeds:       feeds,
 		mapView:     mapView,
+		tabs:        []*tab{{mapView: mapView}},
 		listView:    listView,
 		detailView:  detailView,
+		legendView:  legendView,
+		feedsView:   feedsView,
+		statsView:   statsView,
+		airportView: NewAirportView(opts.Units),
+		movements:   newAirportMovements(),
+		coverage:    coverage,
+		peaks:       make(map[string]Peak),
+		stats:       opts.Stats,
+		statsSaved:  time.Now(),
+		tableView:   tableView,
+		receiver:    opts.Receiver,
+		units:       opts.Units,
+		shotDir:     opts.ScreenshotDir,
+		keyMap:      opts.KeyMap,
+		bookmarks:   opts.Bookmarks,
+		configPath:  opts.ConfigPath,
+		statusBar:   statusBar,
+		prompt:      prompt,
 		currentView: ViewModeMap,
+		weather:     fetcher,
+		alerts:      alert.NewManager(opts.Watchlist),
+		sounder:     alert.NewSounder(opts.SoundKinds, opts.SoundCommand, screen.Beep),
+		confirmQuit: opts.ConfirmQuit,
+		themesDir:   opts.ThemesDir,
+		cache:       opts.Cache,
+		startup:     opts.Startup,
+		started:     time.Now(),
 		quit:        make(chan struct{}),
 		ctx:         ctx,
 		cancel:      cancel,
 	}
 
+	if opts.Proximity != nil {
+		app.alerts.SetProximity(*opts.Proximity)
+	}
+
+	app.updateCellPixels()
+
 	return app, nil
 }
 
@@ -83,11 +285,16 @@ func NewApp(tracker *adsb.Tracker, dump1090 *adsb.Dump1090Client, features map[g
 func (a *App) Run() error {
 	defer a.cleanup()
 
-	a.dump1090.Start()
+	a.feeds.Start()
 
 	a.tracker.StartPruning(a.ctx, 10*time.Second)
 
+	if a.weather != nil {
+		a.weather.Start(a.ctx)
+	}
+
 	go a.readMessages()
+	a.startLoading(a.startup)
 
 	ticker := time.NewTicker(100 * time.Millisecond) // 10 FPS
 	defer ticker.Stop()
@@ -101,6 +308,12 @@ func (a *App) Run() error {
 			a.update()
 			a.render()
 
+		case err := <-a.feeds.Errors():
+			if err != nil {
+				log.Warnf("Feed: %v", err)
+				a.statusBar.SetMessage("%v", err)
+			}
+
 		default:
 			if a.screen.HasPendingEvent() {
 				ev := a.screen.PollEvent()
@@ -112,13 +325,13 @@ func (a *App) Run() error {
 	}
 }
 
-// readMessages reads aircraft updates from dump1090
+// readMessages reads aircraft updates from the feeds
 func (a *App) readMessages() {
 	for {
 		select {
 		case <-a.ctx.Done():
 			return
-		case ac := <-a.dump1090.ReadMessages():
+		case ac := <-a.feeds.ReadMessages():
 			if ac != nil {
 				a.tracker.Update(ac)
 			}
@@ -128,93 +341,403 @@ func (a *App) readMessages() {
 
 // update updates the application state
 func (a *App) update() {
-	aircraft := a.tracker.GetAll()
+	aircraft := a.aircraft()
 
 	a.listView.Update(aircraft)
 
+	// Alerts and follow keep to the frozen picture while paused
+	if !a.paused {
+		raised := a.alerts.Evaluate(a.tracker.GetAll()) // Filtered-out aircraft still alert
+		for _, raisedAlert := range raised {
+			log.Event(debug.LevelInfo, "alert", raisedAlert.ICAO, debug.Fields{
+				"kind":    raisedAlert.Kind.String(),
+				"message": raisedAlert.Message,
+			})
+		}
+		if err := a.sounder.Play(raised); err != nil {
+			log.Warnf("Alert sound failed: %v", err)
+		}
+		a.mapView.SetAlerts(a.alerts.ActiveKinds())
+		a.updateFollow()
+		a.movements.observe(a.tracker.GetAll(), a.mapView.Airports())
+	}
+
+	if a.currentView == ViewModeTable {
+		a.tableView.Update(aircraft, a.reference(), a.alerts.ActiveKinds())
+	}
+
+	a.updateRecords()
+	a.coverage.observe(a.tracker.GetAll())
+	a.updateStats()
+	a.checkStartup()
+	a.updateTiles()
+	a.checkDownload()
+
 	a.mapView.SetCenterFromFirstAircraft(aircraft)
 
 	if a.currentView == ViewModeDetail {
-		selected := a.listView.GetSelected()
-		a.detailView.SetAircraft(selected)
+		a.detailView.SetAircraft(a.detailAircraft(aircraft))
 	}
 }
 
+// showDetail opens the detail panel on an aircraft, returning to from
+// when it closes
+func (a *App) showDetail(ac *adsb.Aircraft, from ViewMode) {
+	a.detailICAO = ""
+	if ac != nil {
+		a.detailICAO = ac.ICAO
+	}
+	a.detailView.SetAircraft(ac)
+	a.detailFrom = from
+	a.setView(ViewModeDetail)
+}
+
+// detailAircraft returns the latest state of the aircraft the detail
+// panel was opened on, nil once it is no longer shown
+func (a *App) detailAircraft(aircraft []*adsb.Aircraft) *adsb.Aircraft {
+	for _, ac := range aircraft {
+		if ac.ICAO == a.detailICAO {
+			return ac
+		}
+	}
+	return nil
+}
+
 // render renders the current view to the screen
+// The screen isn't cleared between frames: the map only resends changed
+// cells, and panels drawn over it are reported back so the map repaints
+// those cells next frame (for when the panel moves or closes)
 func (a *App) render() {
-	a.screen.Clear()
-
-	aircraft := a.tracker.GetAll()
+	aircraft := a.aircraft()
 	selectedICAO := ""
 	if selected := a.listView.GetSelected(); selected != nil {
 		selectedICAO = selected.ICAO
 	}
 
-	// Always draw map
-	a.mapView.Draw(a.screen, aircraft, selectedICAO)
+	// The table covers the whole map, so skip drawing it underneath
+	if a.currentView != ViewModeTable {
+		a.mapView.Draw(a.screen, aircraft, selectedICAO)
+	}
+
+	located := 0
+	for _, ac := range aircraft {
+		if ac.PositionLocked() {
+			located++
+		}
+	}
+	a.statusBar.SetIndicators(a.indicators())
+	a.statusBar.SetFeedHealth(summarizeFeeds(a.feeds.Status()))
+	a.statusBar.Draw(a.screen, a.mapView.GetProjection(), len(aircraft), located)
+	a.mapView.InvalidateRegion(a.statusBar.Bounds())
+	if a.prompt.Active() {
+		a.prompt.Draw(a.screen)
+	}
 
 	// Draw list or detail view depending on mode
 	switch a.currentView {
 	case ViewModeMap:
 		a.listView.Draw(a.screen)
+		a.mapView.InvalidateRegion(a.listView.Bounds())
 	case ViewModeDetail:
 		a.detailView.Draw(a.screen)
+		a.mapView.InvalidateRegion(a.detailView.Bounds())
+	case ViewModeTable:
+		a.tableView.Draw(a.screen)
+		a.mapView.InvalidateRegion(a.tableView.Bounds())
+	}
+
+	if a.showLegend && a.currentView != ViewModeTable {
+		a.legendView.SetSections(a.legend())
+		a.legendView.Draw(a.screen)
+		a.mapView.InvalidateRegion(a.legendView.Bounds())
+	}
+
+	if a.showFeeds {
+		a.feedsView.SetFeeds(a.feeds.Status())
+		a.feedsView.Draw(a.screen)
+		a.mapView.InvalidateRegion(a.feedsView.Bounds())
+	}
+
+	if a.showStats {
+		a.statsView.SetDays(a.stats.Days())
+		a.statsView.Draw(a.screen)
+		a.mapView.InvalidateRegion(a.statsView.Bounds())
+	}
+
+	if a.showLoad {
+		a.loadView.Draw(a.screen)
+		a.mapView.InvalidateRegion(a.loadView.Bounds())
+	}
+
+	if a.showFetch {
+		a.fetchView.Draw(a.screen)
+		a.mapView.InvalidateRegion(a.fetchView.Bounds())
+	}
+
+	if a.showAirport && a.currentView == ViewModeMap {
+		a.airportView.SetMovements(a.movements.counts(a.airportView.Ident()))
+		a.airportView.Draw(a.screen)
+		a.mapView.InvalidateRegion(a.airportView.Bounds())
 	}
 
 	a.screen.Show()
+
+	if a.currentView != ViewModeTable {
+		a.drawGraphics()
+	}
+}
+
+// graphicsRefreshInterval is how often a sixel map image is redrawn even
+// when unchanged, to repair cells that text updates have painted over
+const graphicsRefreshInterval = 2 * time.Second
+
+// drawGraphics writes the map image after the text frame in sixel/kitty mode
+func (a *App) drawGraphics() {
+	force := a.graphicsDue
+	if a.mapView.RenderMode() == render.RenderModeSixel && time.Since(a.graphicsAt) > graphicsRefreshInterval {
+		force = true
+	}
+
+	seq := a.mapView.GraphicsSequence(force)
+	if seq == "" {
+		return
+	}
+
+	io.WriteString(a.terminal(), seq)
+	a.graphicsAt = time.Now()
+	a.graphicsDue = false
+}
+
+// terminal returns the writer for raw escape sequences
+func (a *App) terminal() io.Writer {
+	if tty, ok := a.screen.Tty(); ok {
+		return tty
+	}
+	return os.Stdout
+}
+
+// updateCellPixels reads the terminal's cell size for graphics modes
+func (a *App) updateCellPixels() {
+	width, height := 0, 0
+	if tty, ok := a.screen.Tty(); ok {
+		if ws, err := tty.WindowSize(); err == nil {
+			width, height = ws.CellDimensions()
+		}
+	}
+	a.mapView.SetCellPixels(width, height)
+	a.graphicsDue = true
+}
+
+// layerKeys maps the uppercase toggle keys to map layers
+var layerKeys = map[rune]render.Layer{
+	'C': render.LayerCoastlines,
+	'W': render.LayerRivers,
+	'B': render.LayerBorders,
+	'H': render.LayerHighways,
+	'Y': render.LayerCities,
+	'A': render.LayerAirports,
+	'S': render.LayerAirspace,
+	'V': render.LayerNavaids,
+	'Z': render.LayerTimeZones,
+	'O': render.LayerOverlays,
+	'P': render.LayerWaypoints,
+	'T': render.LayerTrails,
+	'G': render.LayerRings,
+	'D': render.LayerCoverage,
+}
+
+// keyActions names the single-key commands by their built-in key, so the
+// [keys] table of the config file can bind other keys to them
+var keyActions = map[string]rune{
+	"quit":            'q',
+	"refresh":         'r',
+	"zoom_in":         '+',
+	"zoom_out":        '-',
+	"coastlines":      'C',
+	"rivers":          'W',
+	"borders":         'B',
+	"highways":        'H',
+	"fewer_roads":     '(',
+	"more_roads":      ')',
+	"cities":          'Y',
+	"airports":        'A',
+	"airspace":        'S',
+	"navaids":         'V',
+	"timezones":       'Z',
+	"overlays":        'O',
+	"waypoints":       'P',
+	"trails":          'T',
+	"rings":           'G',
+	"coverage":        'D',
+	"airport_labels":  'i',
+	"metar":           'M',
+	"wind":            'w',
+	"night":           'n',
+	"aircraft_labels": 'l',
+	"legend":          'k',
+	"feeds":           'F',
+	"stats":           's',
+	"low_traffic":     'L',
+	"high_traffic":    'E',
+	"select_nearest":  'c',
+	"airport":         'a',
+	"screenshot":      'x',
+	"table":           't',
+	"reverse_sort":    'o',
+	"positions_only":  'p',
+	"follow":          'f',
+	"save_bookmark":   'm',
+	"pause":           ' ',
+	"goto":            'g',
+	"command":         ':',
+	"prev_tab":        '[',
+	"next_tab":        ']',
+}
+
+// ParseKeyBindings turns action = key pairs into a map from each new key
+// to the built-in key of its action
+func ParseKeyBindings(bindings map[string]string) (map[rune]rune, error) {
+	keyMap := make(map[rune]rune, len(bindings))
+	for action, key := range bindings {
+		builtin, ok := keyActions[action]
+		if !ok {
+			return nil, fmt.Errorf("unknown key action %q", action)
+		}
+		runes := []rune(key)
+		if len(runes) != 1 {
+			return nil, fmt.Errorf("key for %s must be a single character, got %q", action, key)
+		}
+		keyMap[runes[0]] = builtin
+	}
+	return keyMap, nil
+}
+
+// bindKey returns the built-in key a pressed key acts as
+func (a *App) bindKey(key rune) rune {
+	if builtin, ok := a.keyMap[key]; ok {
+		return builtin
+	}
+	return key
 }
 
 // handleEvent processes keyboard events
 func (a *App) handleEvent(ev tcell.Event) bool {
 	switch ev := ev.(type) {
 	case *tcell.EventKey:
+		if a.prompt.Active() {
+			a.prompt.HandleKey(ev)
+			return true
+		}
+
 		switch ev.Key() {
 		case tcell.KeyEscape:
-			if a.currentView == ViewModeDetail {
-				a.currentView = ViewModeMap
-			} else {
-				close(a.quit)
-				return false
+			switch {
+			case a.showLoad:
+				a.showLoad = false
+			case a.showFetch:
+				a.showFetch = false
+			case a.showAirport && a.currentView == ViewModeMap:
+				a.showAirport = false
+			case a.currentView == ViewModeDetail:
+				a.setView(a.detailFrom)
+			case a.currentView == ViewModeTable:
+				a.setView(ViewModeMap)
+			default:
+				return !a.requestQuit()
 			}
 
 		case tcell.KeyEnter:
-			if a.currentView == ViewModeMap {
-				a.currentView = ViewModeDetail
-				selected := a.listView.GetSelected()
-				a.detailView.SetAircraft(selected)
+			switch a.currentView {
+			case ViewModeMap:
+				a.showDetail(a.listView.GetSelected(), ViewModeMap)
+			case ViewModeTable:
+				a.showDetail(a.tableView.GetSelected(), ViewModeTable)
 			}
 
 		case tcell.KeyUp:
-			if a.currentView == ViewModeMap {
+			switch a.currentView {
+			case ViewModeMap:
 				a.listView.SelectPrev()
 				selected := a.listView.GetSelected()
 				a.mapView.CenterOnAircraft(selected)
+				a.retargetFollow(selected)
+			case ViewModeTable:
+				a.tableView.Move(-1)
 			}
 
 		case tcell.KeyDown:
-			if a.currentView == ViewModeMap {
+			switch a.currentView {
+			case ViewModeMap:
 				a.listView.SelectNext()
 				selected := a.listView.GetSelected()
 				a.mapView.CenterOnAircraft(selected)
+				a.retargetFollow(selected)
+			case ViewModeTable:
+				a.tableView.Move(1)
 			}
 
-		case tcell.KeyRune:
-			switch ev.Rune() {
-			case 'q', 'Q':
-				close(a.quit)
-				return false
+		case tcell.KeyPgUp:
+			if a.currentView == ViewModeTable {
+				a.tableView.Move(-a.tableView.PageSize())
+			}
+
+		case tcell.KeyPgDn:
+			if a.currentView == ViewModeTable {
+				a.tableView.Move(a.tableView.PageSize())
+			}
+
+		case tcell.KeyLeft:
+			if a.currentView == ViewModeTable {
+				a.tableView.SortPrev()
+			}
 
-			case 'r', 'R':
-				a.render()
+		case tcell.KeyRight:
+			if a.currentView == ViewModeTable {
+				a.tableView.SortNext()
+			}
 
-			case '+', '=':
-				a.mapView.ZoomIn()
+		case tcell.KeyTab:
+			if a.currentView == ViewModeMap {
+				a.cycleSelection(1)
+			}
 
-			case '-', '_':
-				a.mapView.ZoomOut()
+		case tcell.KeyBacktab:
+			if a.currentView == ViewModeMap {
+				a.cycleSelection(-1)
 			}
+
+		case tcell.KeyCtrlT:
+			a.newTab()
+
+		case tcell.KeyCtrlW:
+			a.closeTab()
+
+		case tcell.KeyRune:
+			return a.handleRune(a.bindKey(ev.Rune()))
+		}
+
+	case *tcell.EventMouse:
+		x, y := ev.Position()
+		a.cursor, a.cursorOnMap = geo.Point{X: x, Y: y}, y > 0
+		if a.overMap() {
+			a.statusBar.SetCursor(x, y)
+		} else {
+			a.statusBar.ClearCursor()
 		}
 
+		switch {
+		case ev.Buttons()&tcell.WheelUp != 0 && a.currentView == ViewModeMap:
+			a.mapView.ZoomIn(a.zoomAnchor(true))
+		case ev.Buttons()&tcell.WheelDown != 0 && a.currentView == ViewModeMap:
+			a.mapView.ZoomOut(a.zoomAnchor(true))
+		}
+
+		pressed := ev.Buttons()&tcell.Button1 != 0
+		if pressed && !a.mouseDown && a.currentView == ViewModeMap {
+			a.clickMap(x, y)
+		}
+		a.mouseDown = pressed
+
 	case *tcell.EventResize:
 		a.handleResize()
 	}
@@ -222,20 +745,627 @@ func (a *App) handleEvent(ev tcell.Event) bool {
 	return true
 }
 
+// handleRune runs the command for a character key, given as the built-in
+// key it is bound to; it returns false when the app should quit
+func (a *App) handleRune(key rune) bool {
+	switch key {
+	case 'q', 'Q':
+		return !a.requestQuit()
+
+	case 'r', 'R':
+		a.screen.Sync()
+		a.mapView.InvalidateAll()
+		a.graphicsDue = true
+		a.render()
+
+	case '+', '=':
+		a.mapView.ZoomIn(a.zoomAnchor(false))
+
+	case '-', '_':
+		a.mapView.ZoomOut(a.zoomAnchor(false))
+
+	case '(':
+		a.changeHighwayDetail(-1)
+
+	case ')':
+		a.changeHighwayDetail(1)
+
+	case 'S', 'V', 'Z', 'C', 'W', 'B', 'H', 'Y', 'A', 'T', 'G', 'O', 'P', 'D':
+		a.mapView.ToggleLayer(layerKeys[key])
+
+	case 'i':
+		a.mapView.CycleAirportLabels()
+
+	case 'M':
+		a.mapView.ToggleWeather()
+
+	case 'w':
+		a.mapView.ToggleWindBarbs()
+
+	case 'n':
+		a.toggleNightMode()
+
+	case 'l':
+		a.mapView.ToggleAircraftLabels()
+
+	case 'k':
+		a.showLegend = !a.showLegend
+
+	case 'F':
+		a.showFeeds = !a.showFeeds
+		a.showStats = false
+
+	case 's':
+		a.showStats = !a.showStats
+		a.showFeeds = false
+
+	case 'L':
+		a.toggleBand(bandLow)
+
+	case 'E':
+		a.toggleBand(bandHigh)
+
+	case 'x':
+		a.screenshot()
+
+	case 't':
+		if a.currentView == ViewModeTable {
+			a.setView(ViewModeMap)
+		} else {
+			a.setView(ViewModeTable)
+		}
+
+	case 'o':
+		if a.currentView == ViewModeTable {
+			a.tableView.ReverseSort()
+		}
+
+	case 'f':
+		a.toggleFollow()
+
+	case 'c':
+		a.selectNearest()
+
+	case 'a':
+		if a.currentView == ViewModeMap {
+			a.inspectAirport()
+		}
+
+	case '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		a.jumpToBookmark(int(key - '1'))
+
+	case 'm':
+		a.saveBookmark()
+
+	case ' ':
+		a.togglePause()
+
+	case ':':
+		a.prompt.Open(":", a.runCommand)
+
+	case 'g':
+		a.prompt.Open("Go to (lat, lon or airport):", a.goTo)
+
+	case '[':
+		a.cycleTab(-1)
+
+	case ']':
+		a.cycleTab(1)
+
+	case 'p':
+		hidden := a.listView.TogglePositionsOnly()
+		a.listView.Update(a.aircraft())
+		log.Infof("Aircraft without positions hidden from list: %v", hidden)
+	}
+	return true
+}
+
+// quitConfirmWindow is how long a second quit key press has to exit
+const quitConfirmWindow = 3 * time.Second
+
+// requestQuit quits, or with quit confirmation on, asks for the key to be
+// pressed again; it reports whether the app is quitting
+func (a *App) requestQuit() bool {
+	if a.confirmQuit && time.Now().After(a.quitArmed) {
+		a.quitArmed = time.Now().Add(quitConfirmWindow)
+		a.statusBar.SetMessage("Press again to quit")
+		return false
+	}
+	close(a.quit)
+	return true
+}
+
+// aircraft returns the aircraft to display: the live set, or the snapshot
+// taken when the display was paused
+func (a *App) aircraft() []*adsb.Aircraft {
+	if a.paused {
+		return a.band.apply(a.filter.apply(a.frozen))
+	}
+	return a.band.apply(a.filter.apply(a.tracker.GetAll()))
+}
+
+// toggleBand shows only the aircraft in band, or everything again if band
+// is already showing
+func (a *App) toggleBand(band altitudeBand) {
+	if a.band == band {
+		a.band = bandAll
+	} else {
+		a.band = band
+	}
+	a.listView.Update(a.aircraft())
+	a.statusBar.SetMessage("Showing %s", strings.ToLower(a.band.String()))
+	log.Infof("Altitude band: %s", a.band)
+}
+
+// togglePause freezes or resumes the display; messages keep being read
+// into the tracker while paused
+func (a *App) togglePause() {
+	a.paused = !a.paused
+	if a.paused {
+		a.frozen = a.tracker.Snapshot()
+		log.Infof("Display paused with %d aircraft", len(a.frozen))
+	} else {
+		a.frozen = nil
+		log.Infof("Display resumed")
+	}
+	a.update()
+}
+
+// toggleFollow starts or stops keeping the map centered on the selected
+// aircraft
+func (a *App) toggleFollow() {
+	if a.followICAO != "" {
+		log.Event(debug.LevelInfo, "follow_stopped", a.followICAO, nil)
+		a.followICAO = ""
+		return
+	}
+
+	selected := a.listView.GetSelected()
+	if selected == nil || !selected.PositionLocked() {
+		a.statusBar.SetMessage("Select an aircraft with a position to follow")
+		return
+	}
+	a.followICAO = selected.ICAO
+	a.mapView.FollowAircraft(selected)
+	log.Event(debug.LevelInfo, "follow_started", a.followICAO, nil)
+}
+
+// clickMap opens the airport popup for an airport clicked on the map, or
+// closes it when the click lands anywhere else
+func (a *App) clickMap(x, y int) {
+	airport, ok := a.mapView.AirportAt(x, y)
+	if !ok || y == 0 {
+		a.showAirport = false
+		return
+	}
+
+	a.openAirport(airport, x, y)
+}
+
+// overMap reports whether the mouse cursor is over the map itself, not the
+// status bar or a panel drawn over the map. Panels are checked now rather
+// than when the mouse last moved, since one may have opened under it.
+func (a *App) overMap() bool {
+	if !a.cursorOnMap {
+		return false
+	}
+
+	var panels []func() (x, y, width, height int)
+	switch a.currentView {
+	case ViewModeMap:
+		panels = append(panels, a.listView.Bounds)
+	case ViewModeDetail:
+		panels = append(panels, a.detailView.Bounds)
+	case ViewModeTable:
+		return false
+	}
+	if a.showLegend {
+		panels = append(panels, a.legendView.Bounds)
+	}
+	if a.showFeeds {
+		panels = append(panels, a.feedsView.Bounds)
+	}
+	if a.showStats {
+		panels = append(panels, a.statsView.Bounds)
+	}
+	if a.showLoad {
+		panels = append(panels, a.loadView.Bounds)
+	}
+	if a.showFetch {
+		panels = append(panels, a.fetchView.Bounds)
+	}
+	if a.showAirport && a.currentView == ViewModeMap {
+		panels = append(panels, a.airportView.Bounds)
+	}
+
+	for _, bounds := range panels {
+		x, y, width, height := bounds()
+		if a.cursor.X >= x && a.cursor.X < x+width && a.cursor.Y >= y && a.cursor.Y < y+height {
+			return false
+		}
+	}
+	return true
+}
+
+// zoomAnchor returns the map position a zoom keeps fixed on screen: the
+// selected aircraft, else the spot under the mouse cursor, or with
+// preferCursor (for the scroll wheel) the cursor first. It returns nil to
+// zoom about the center, as when following an aircraft.
+func (a *App) zoomAnchor(preferCursor bool) *geo.LatLon {
+	if a.followICAO != "" || a.currentView != ViewModeMap {
+		return nil
+	}
+
+	var cursor, selected *geo.LatLon
+	if a.overMap() {
+		lat, lon := a.mapView.GetProjection().Unproject(a.cursor.X, a.cursor.Y)
+		cursor = &geo.LatLon{Lat: lat, Lon: lon}
+	}
+	if ac := a.listView.GetSelected(); ac != nil && ac.PositionLocked() {
+		selected = &geo.LatLon{Lat: *ac.Latitude, Lon: *ac.Longitude}
+	}
+
+	if preferCursor && cursor != nil {
+		return cursor
+	}
+	if selected != nil {
+		return selected
+	}
+	return cursor
+}
+
+// inspectRange is how far, in miles, inspectAirport looks for an airport
+const inspectRange = 100
+
+// inspectAirport opens the airport popup for the airport nearest the mouse
+// cursor, or the selected aircraft, or the map center, in that order;
+// pressed again it closes the popup
+func (a *App) inspectAirport() {
+	if a.showAirport {
+		a.showAirport = false
+		return
+	}
+
+	projection := a.mapView.GetProjection()
+	lat, lon := projection.GetCenter()
+	from := geo.LatLon{Lat: lat, Lon: lon}
+	if selected := a.listView.GetSelected(); selected != nil && selected.PositionLocked() {
+		from = geo.LatLon{Lat: *selected.Latitude, Lon: *selected.Longitude}
+	}
+	if a.overMap() {
+		from.Lat, from.Lon = projection.Unproject(a.cursor.X, a.cursor.Y)
+	}
+
+	airport, _, ok := a.mapView.Airports().Nearest(from, inspectRange)
+	if !ok {
+		a.statusBar.SetMessage("No airport within %s", a.units.FormatDistance(inspectRange))
+		return
+	}
+	point := projection.Project(airport.Point.Lat, airport.Point.Lon)
+	a.openAirport(airport, point.X, point.Y)
+}
+
+// openAirport shows the airport popup beside screen cell x, y
+func (a *App) openAirport(airport *geo.Feature, x, y int) {
+	ident, _ := airport.Properties["ident"].(string)
+	a.airportView.SetAirport(airport, a.mapView.Runways(ident))
+	width, height := a.screen.Size()
+	a.airportView.Place(x, y, width, height)
+	a.showAirport = true
+	log.Infof("Opened airport %s", ident)
+}
+
+// selectNearest selects the positioned aircraft closest to the receiver,
+// or to the map center without one: usually the one just heard overhead
+func (a *App) selectNearest() {
+	from := a.reference()
+	var nearest *adsb.Aircraft
+	best := math.Inf(1)
+	for _, ac := range a.aircraft() {
+		if !ac.PositionLocked() {
+			continue
+		}
+		if d := geo.Distance(from, geo.LatLon{Lat: *ac.Latitude, Lon: *ac.Longitude}); d < best {
+			nearest, best = ac, d
+		}
+	}
+
+	if nearest == nil || !a.listView.Select(nearest.ICAO) {
+		a.statusBar.SetMessage("No aircraft with a position")
+		return
+	}
+	if a.currentView == ViewModeTable {
+		a.tableView.Select(nearest.ICAO)
+	}
+	a.retargetFollow(nearest)
+	a.statusBar.SetMessage("Nearest: %s, %s away", nearest.DisplayName(), a.units.FormatDistance(best))
+}
+
+// cycleSelection moves the selection step aircraft along the map from left
+// to right, wrapping around, independent of the list order; an aircraft
+// that isn't on screen starts from the leftmost or rightmost one
+func (a *App) cycleSelection(step int) {
+	order := a.mapView.ScreenOrder(a.aircraft())
+	if len(order) == 0 {
+		return
+	}
+
+	next := 0
+	if step < 0 {
+		next = len(order) - 1
+	}
+	if selected := a.listView.GetSelected(); selected != nil {
+		for i, ac := range order {
+			if ac.ICAO == selected.ICAO {
+				next = (i + step + len(order)) % len(order)
+				break
+			}
+		}
+	}
+
+	a.listView.Select(order[next].ICAO)
+	a.retargetFollow(order[next])
+}
+
+// retargetFollow follows a newly selected aircraft while follow is on
+func (a *App) retargetFollow(selected *adsb.Aircraft) {
+	if a.followICAO != "" && selected != nil {
+		a.followICAO = selected.ICAO
+	}
+}
+
+// updateFollow recenters the map on the followed aircraft, and stops
+// following once it has dropped out of the tracker
+func (a *App) updateFollow() {
+	if a.followICAO == "" {
+		return
+	}
+
+	ac, ok := a.tracker.Get(a.followICAO)
+	if !ok {
+		a.statusBar.SetMessage("Lost %s, follow off", a.followICAO)
+		log.Event(debug.LevelInfo, "follow_lost", a.followICAO, nil)
+		a.followICAO = ""
+		return
+	}
+	a.mapView.FollowAircraft(ac)
+}
+
+// jumpToBookmark moves the map to the bookmark at index, counting from 0
+func (a *App) jumpToBookmark(index int) {
+	if index >= len(a.bookmarks) {
+		a.statusBar.SetMessage("No bookmark %d", index+1)
+		return
+	}
+
+	bookmark := a.bookmarks[index]
+	a.followICAO = ""
+	a.mapView.GoTo(bookmark.Lat, bookmark.Lon, bookmark.Radius)
+	a.statusBar.SetMessage("%d: %s", index+1, bookmark.Name)
+}
+
+// goTo recenters the map on coordinates or an airport code typed into
+// the go-to prompt, keeping the zoom
+func (a *App) goTo(text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+
+	target, err := geo.ParseLatLon(text)
+	if err != nil {
+		airport, ok := a.mapView.Airports().Lookup(text)
+		if !ok || airport.Point == nil {
+			a.statusBar.SetMessage("Not coordinates or a known airport: %s", text)
+			return
+		}
+		target = *airport.Point
+	}
+
+	a.followICAO = ""
+	a.mapView.GoTo(target.Lat, target.Lon, 0)
+}
+
+// saveBookmark appends the current view to the config file as a new
+// bookmark
+func (a *App) saveBookmark() {
+	if a.configPath == "" {
+		a.statusBar.SetMessage("No config file to save bookmarks to")
+		return
+	}
+
+	lat, lon := a.mapView.GetProjection().GetCenter()
+	bookmark := config.Bookmark{
+		Name:   fmt.Sprintf("view %d", len(a.bookmarks)+1),
+		Lat:    lat,
+		Lon:    lon,
+		Radius: a.mapView.GetRadius(),
+	}
+	if err := config.AppendBookmark(a.configPath, bookmark); err != nil {
+		log.Errorf("Failed to save bookmark: %v", err)
+		a.statusBar.SetMessage("Bookmark not saved: %v", err)
+		return
+	}
+
+	a.bookmarks = append(a.bookmarks, bookmark)
+	if len(a.bookmarks) <= 9 {
+		a.statusBar.SetMessage("Saved %q as bookmark %d", bookmark.Name, len(a.bookmarks))
+	} else {
+		a.statusBar.SetMessage("Saved %q (only the first 9 have keys)", bookmark.Name)
+	}
+}
+
+// changeHighwayDetail shows delta more or fewer road classes in every tab
+// and saves the level to the config file as highway_detail, the setting
+// for -H
+func (a *App) changeHighwayDetail(delta int) {
+	level := a.mapView.HighwayDetail() + delta
+	if level < geo.MinHighwayDetail || level > geo.MaxHighwayDetail {
+		a.statusBar.SetMessage("Highway detail is already %d (range %d-%d)",
+			a.mapView.HighwayDetail(), geo.MinHighwayDetail, geo.MaxHighwayDetail)
+		return
+	}
+
+	for _, t := range a.tabs {
+		t.mapView.SetHighwayDetail(level)
+	}
+	a.mapView.SetHighwayDetail(level)
+	log.Infof("Highway detail %d", level)
+
+	if a.configPath == "" {
+		a.statusBar.SetMessage("Highway detail %d", level)
+		return
+	}
+	if err := config.SaveSetting(a.configPath, "highway_detail", strconv.Itoa(level)); err != nil {
+		log.Errorf("Failed to save highway detail: %v", err)
+		a.statusBar.SetMessage("Highway detail %d (not saved: %v)", level, err)
+		return
+	}
+	a.statusBar.SetMessage("Highway detail %d, saved", level)
+}
+
+// legend returns the map legend, noting the altitude band when only part
+// of the traffic is shown
+func (a *App) legend() []render.LegendSection {
+	sections := a.mapView.Legend()
+	if a.band != bandAll {
+		sample := "↓"
+		if a.band == bandHigh {
+			sample = "↑"
+		}
+		sections = append(sections, render.LegendSection{
+			Title:   "Showing",
+			Entries: []render.LegendEntry{{Sample: sample, Style: render.StyleLabel, Label: a.band.String()}},
+		})
+	}
+	return sections
+}
+
+// indicators returns the status bar tags for the modes in effect
+func (a *App) indicators() []string {
+	var tags []string
+	if a.followICAO != "" {
+		name := a.followICAO
+		if ac, ok := a.tracker.Get(a.followICAO); ok {
+			name = ac.DisplayName()
+		}
+		tags = append(tags, "Follow "+name)
+	}
+	if a.paused {
+		tags = append(tags, "Paused")
+	}
+	if a.filter != nil {
+		tags = append(tags, "Filter "+a.filter.text)
+	}
+	if a.band != bandAll {
+		tags = append(tags, a.band.String())
+	}
+	if len(a.tabs) > 1 {
+		tags = append(tags, fmt.Sprintf("Tab %d/%d", a.tabIndex+1, len(a.tabs)))
+	}
+	return tags
+}
+
+// setView switches views, taking the map's graphics image down while the
+// table covers it and restoring it afterwards
+func (a *App) setView(view ViewMode) {
+	if view == a.currentView {
+		return
+	}
+
+	if view == ViewModeTable {
+		if seq := a.mapView.ClearGraphicsSequence(); seq != "" {
+			io.WriteString(a.terminal(), seq)
+		}
+		a.tableView.Update(a.aircraft(), a.reference(), a.alerts.ActiveKinds())
+	} else if a.currentView == ViewModeTable {
+		a.graphicsDue = true
+	}
+	a.currentView = view
+}
+
+// listWidthFor returns the list panel width, wider when there is a
+// receiver to show range and bearing from
+func listWidthFor(receiver *geo.LatLon) int {
+	if receiver != nil {
+		return ListWidthWithRange
+	}
+	return ListWidth
+}
+
+// reference returns the point distances are measured from: the receiver,
+// or the map center if its location isn't known
+func (a *App) reference() geo.LatLon {
+	if a.receiver != nil {
+		return *a.receiver
+	}
+	lat, lon := a.mapView.GetProjection().GetCenter()
+	return geo.LatLon{Lat: lat, Lon: lon}
+}
+
+// screenshot saves the current screen as ANSI text and HTML
+func (a *App) screenshot() {
+	path, err := saveScreenshot(a.screen, a.shotDir)
+	if err != nil {
+		log.Errorf("Screenshot failed: %v", err)
+		a.statusBar.SetMessage("Screenshot failed: %v", err)
+		return
+	}
+	log.Infof("Screenshot saved to %s", path)
+	a.statusBar.SetMessage("Screenshot saved: %s", strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+}
+
+// toggleNightMode switches between the night theme and the styles in use
+// before it was turned on
+func (a *App) toggleNightMode() {
+	if render.IsMonochrome() {
+		return
+	}
+
+	if a.dayTheme != nil {
+		render.ApplyTheme(a.dayTheme)
+		a.dayTheme = nil
+	} else {
+		a.dayTheme = render.CurrentTheme()
+		render.ApplyTheme(render.BundledTheme("night"))
+	}
+	a.graphicsDue = true
+}
+
 // handleResize handles terminal resize events
 func (a *App) handleResize() {
+	a.screen.Clear()
 	a.screen.Sync()
 	width, height := a.screen.Size()
+	a.showAirport = false // Placed for the old size; click again to reopen
 
-	a.mapView.UpdateDimensions(width, height)
+	for _, t := range a.tabs {
+		t.mapView.UpdateDimensions(width, height)
+	}
+	a.statusBar.UpdateDimensions(0, 0, width)
+	a.prompt.UpdateDimensions(0, 0, width)
+	a.updateCellPixels()
 
-	listWidth := 30
+	listWidth := listWidthFor(a.receiver)
 	listHeight := 12
 	a.listView.UpdateDimensions(0, height-listHeight, listWidth, listHeight)
 
 	detailWidth := 50
-	detailHeight := 15
+	detailHeight := 22
 	a.detailView.UpdateDimensions(0, height-detailHeight, detailWidth, detailHeight)
+	a.detailView.SetMaxHeight(height - 1)
+
+	a.legendView.UpdateDimensions(width-LegendWidth, 1, LegendWidth, height-1)
+	feedsX, feedsWidth := feedsPlacement(width)
+	a.feedsView.UpdateDimensions(feedsX, 1, feedsWidth, height-1)
+	a.statsView.UpdateDimensions(feedsX, 1, feedsWidth, height-1)
+	a.tableView.UpdateDimensions(0, 1, width, height-1)
+}
+
+// feedsPlacement centers the feed panel, narrowing it on small screens
+func feedsPlacement(screenWidth int) (x, width int) {
+	width = min(FeedsWidth, screenWidth)
+	return (screenWidth - width) / 2, width
 }
 
 // cleanup performs cleanup before exit
@@ -244,11 +1374,21 @@ func (a *App) cleanup() {
 		a.cancel()
 	}
 
-	if a.dump1090 != nil {
-		a.dump1090.Close()
+	if a.feeds != nil {
+		a.feeds.Close()
 	}
 
 	if a.screen != nil {
+		if seq := a.mapView.ClearGraphicsSequence(); seq != "" {
+			io.WriteString(a.terminal(), seq)
+		}
+		a.screen.DisableMouse()
 		a.screen.Fini()
 	}
+
+	if a.stats != nil {
+		if err := a.stats.Save(); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+	}
 }
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"ascii1090/internal/adsb"
+	"ascii1090/internal/geo"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// coverageRecorder adds every position received into the coverage grid,
+// once each, by following the aircraft trails
+type coverageRecorder struct {
+	grid *geo.CoverageGrid
+	last map[string]time.Time // Newest trail point counted, by ICAO
+}
+
+// newCoverageRecorder creates a recorder with an empty grid
+func newCoverageRecorder() *coverageRecorder {
+	return &coverageRecorder{
+		grid: geo.NewCoverageGrid(),
+		last: make(map[string]time.Time),
+	}
+}
+
+// observe counts the trail points added since the last call
+func (c *coverageRecorder) observe(aircraft []*adsb.Aircraft) {
+	for _, ac := range aircraft {
+		trail := ac.Trail
+		if len(trail) == 0 {
+			continue
+		}
+
+		last := c.last[ac.ICAO]
+		start := len(trail)
+		for start > 0 && trail[start-1].Time.After(last) {
+			start--
+		}
+		for _, point := range trail[start:] {
+			c.grid.Add(point.Lat, point.Lon, point.Altitude)
+		}
+		c.last[ac.ICAO] = trail[len(trail)-1].Time
+	}
+}
+
+// ExportCoverage writes the session's coverage grid to path, as CSV for a
+// .csv file and GeoJSON otherwise
+func (a *App) ExportCoverage(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create coverage file: %w", err)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		err = a.coverage.grid.WriteCSV(file)
+	} else {
+		err = a.coverage.grid.WriteGeoJSON(file)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write coverage file: %w", err)
+	}
+	return nil
+}
+
+// commandCoverage exports the coverage grid now
+func (a *App) commandCoverage(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("coverage needs a file name")
+	}
+	if err := a.ExportCoverage(args[0]); err != nil {
+		return err
+	}
+	a.statusBar.SetMessage("Saved coverage of %d cells to %s", a.coverage.grid.Len(), args[0])
+	return nil
+}
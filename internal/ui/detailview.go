@@ -2,29 +2,95 @@ package ui
 
 import (
 	"ascii1090/internal/adsb"
+	"ascii1090/internal/geo"
 	"ascii1090/internal/render"
 	"fmt"
+	"math"
+	"strings"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 )
 
+// detailLine is one line of the panel in the style it is drawn with
+type detailLine struct {
+	text  string
+	style tcell.Style
+}
+
 // DetailView displays detailed information about a selected aircraft
 type DetailView struct {
 	aircraft      *adsb.Aircraft
+	aircraftDB    *adsb.AircraftDB
+	planeAlert    *adsb.PlaneAlertDB
+	routes        *geo.RouteTable
+	airports      *geo.AirportIndex
+	magnetic      *geo.MagneticModel
+	coordFormat   geo.CoordFormat
+	units         geo.Units
 	x, y          int
 	width, height int
+	minHeight     int // Height given by the layout; the panel grows upward from it
+	maxHeight     int
 }
 
-// NewDetailView creates a new detail view
+// NewDetailView creates a new detail view, which grows upward past height
+// when there is more to show, up to SetMaxHeight
 func NewDetailView(x, y, width, height int) *DetailView {
 	return &DetailView{
-		x:      x,
-		y:      y,
-		width:  width,
-		height: height,
+		x:         x,
+		y:         y,
+		width:     width,
+		height:    height,
+		minHeight: height,
+		maxHeight: height,
 	}
 }
 
+// SetMaxHeight sets how tall the panel may grow
+func (d *DetailView) SetMaxHeight(maxHeight int) {
+	d.maxHeight = maxHeight
+}
+
+// fit resizes the panel for wanted rows, keeping its bottom edge in place
+func (d *DetailView) fit(wanted int) {
+	height := max(d.minHeight, min(wanted, d.maxHeight))
+	d.y += d.height - height
+	d.height = height
+}
+
+// SetMagneticModel sets the model used to show magnetic heading and track
+func (d *DetailView) SetMagneticModel(model *geo.MagneticModel) {
+	d.magnetic = model
+}
+
+// SetAircraftDB sets the database registration, type and operator are
+// looked up in
+func (d *DetailView) SetAircraftDB(db *adsb.AircraftDB) {
+	d.aircraftDB = db
+}
+
+// SetPlaneAlertDB sets the database of interesting aircraft to tag
+func (d *DetailView) SetPlaneAlertDB(db *adsb.PlaneAlertDB) {
+	d.planeAlert = db
+}
+
+// SetRoutes sets the route table and the airports its codes resolve to
+func (d *DetailView) SetRoutes(routes *geo.RouteTable, airports *geo.AirportIndex) {
+	d.routes = routes
+	d.airports = airports
+}
+
+// SetCoordFormat sets how the aircraft position is displayed
+func (d *DetailView) SetCoordFormat(format geo.CoordFormat) {
+	d.coordFormat = format
+}
+
+// SetUnits sets the units distances, speeds and altitudes are shown in
+func (d *DetailView) SetUnits(units geo.Units) {
+	d.units = units
+}
+
 // SetAircraft sets the aircraft to display
 func (d *DetailView) SetAircraft(ac *adsb.Aircraft) {
 	d.aircraft = ac
@@ -33,10 +99,56 @@ func (d *DetailView) SetAircraft(ac *adsb.Aircraft) {
 // Draw renders the detail view to the screen
 func (d *DetailView) Draw(screen tcell.Screen) {
 	if d.aircraft == nil {
+		d.fit(0)
 		d.drawEmpty(screen)
 		return
 	}
 
+	// Collect aircraft information
+	ac := d.aircraft
+	statusStyle := render.StyleLabel
+	if ac.EmergencyKind() != "" {
+		statusStyle = render.StyleEmergency
+	}
+	lines := []detailLine{
+		{fmt.Sprintf("ICAO:          %s", ac.ICAO), render.StyleLabel},
+		{fmt.Sprintf("Flight:        %s", ac.DisplayName()), render.StyleLabel},
+	}
+	if info, ok := d.aircraftDB.Lookup(ac.ICAO); ok {
+		lines = append(lines,
+			detailLine{fmt.Sprintf("Registration:  %s", orDash(info.Registration)), render.StyleLabel},
+			detailLine{fmt.Sprintf("Type:          %s", d.typeString(info)), render.StyleLabel},
+			detailLine{fmt.Sprintf("Operator:      %s", orDash(info.Operator)), render.StyleLabel},
+		)
+	}
+	if interest, ok := d.planeAlert.Lookup(ac.ICAO); ok {
+		lines = append(lines, detailLine{fmt.Sprintf("Interest:      %s", interestString(interest)), render.StyleWatch})
+	}
+	if ac.Category != "" {
+		lines = append(lines, detailLine{fmt.Sprintf("Category:      %s", categoryString(ac.Category)), render.StyleLabel})
+	}
+	lines = append(lines, d.routeLines(ac)...)
+	lines = append(lines, []detailLine{
+		{fmt.Sprintf("Squawk:        %s", squawkString(ac)), statusStyle},
+		{fmt.Sprintf("Flags:         %s", flagsString(ac)), statusStyle},
+		{fmt.Sprintf("Status:        %s", groundString(ac)), render.StyleLabel},
+		{fmt.Sprintf("Position:      %s", positionString(ac, d.coordFormat)), render.StyleLabel},
+		{fmt.Sprintf("Near:          %s", d.nearString(ac)), render.StyleLabel},
+		{fmt.Sprintf("Altitude:      %d %s (FL%d)", d.units.Altitude(ac.Altitude), d.units.AltitudeUnit(), ac.FlightLevel()), render.StyleLabel},
+		{fmt.Sprintf("Last %.0f min:    %s", adsb.TrailDuration.Minutes(), altitudeTrend(ac.AltitudeHistory, time.Now(), d.units)), render.StyleLabel},
+		{fmt.Sprintf("Speed:         %d %s", d.units.Speed(ac.Speed), d.units.SpeedUnit()), render.StyleLabel},
+		{fmt.Sprintf("Heading:       %s", d.bearingString(ac, ac.Heading)), render.StyleLabel},
+		{fmt.Sprintf("Track:         %s", d.bearingString(ac, ac.Track)), render.StyleLabel},
+		{fmt.Sprintf("Vertical Rate: %+d %s", d.units.VerticalRate(ac.VerticalRate), d.units.VerticalRateUnit()), render.StyleLabel},
+		{fmt.Sprintf("Last Seen:     %d seconds ago", ac.SecondsSinceLastSeen()), render.StyleLabel},
+		{fmt.Sprintf("Source:        %s", sourcesString(ac)), render.StyleLabel},
+	}...)
+	lines = append(lines, callsignLines(ac)...)
+	lines = append(lines, squawkLines(ac)...)
+	lines = append(lines, receiverLines(ac, time.Now())...)
+
+	d.fit(len(lines) + 2)
+
 	// Clear the entire panel area first (make it opaque)
 	defaultStyle := tcell.StyleDefault
 	for row := d.y + 1; row < d.y+d.height-1; row++ {
@@ -55,26 +167,12 @@ func (d *DetailView) Draw(screen tcell.Screen) {
 		screen.SetContent(titleX+i, d.y, ch, nil, render.StyleLabel)
 	}
 
-	// Draw aircraft information
-	ac := d.aircraft
-	lines := []string{
-		fmt.Sprintf("ICAO:          %s", ac.ICAO),
-		fmt.Sprintf("Flight:        %s", ac.DisplayName()),
-		fmt.Sprintf("Position:      %s", ac.PositionString()),
-		fmt.Sprintf("Altitude:      %d ft (FL%d)", ac.Altitude, ac.FlightLevel()),
-		fmt.Sprintf("Speed:         %d kts", ac.Speed),
-		fmt.Sprintf("Heading:       %d*", ac.Heading),
-		fmt.Sprintf("Track:         %d*", ac.Track),
-		fmt.Sprintf("Vertical Rate: %+d ft/min", ac.VerticalRate),
-		fmt.Sprintf("Last Seen:     %d seconds ago", ac.SecondsSinceLastSeen()),
-	}
-
 	y := d.y + 1
 	for i, line := range lines {
 		if y+i >= d.y+d.height-1 {
 			break
 		}
-		d.drawLine(screen, d.x+2, y+i, line)
+		d.drawLine(screen, d.x+2, y+i, line.text, line.style)
 	}
 
 	// Add instructions at bottom
@@ -86,6 +184,327 @@ func (d *DetailView) Draw(screen tcell.Screen) {
 	}
 }
 
+// bearingString formats a true bearing, adding the magnetic equivalent when
+// a magnetic model is available and the aircraft position is known
+func (d *DetailView) bearingString(ac *adsb.Aircraft, trueBearing int) string {
+	if d.magnetic == nil || !ac.PositionLocked() {
+		return fmt.Sprintf("%d*", trueBearing)
+	}
+
+	declination := d.magnetic.Declination(*ac.Latitude, *ac.Longitude, float64(ac.Altitude), time.Now())
+	return fmt.Sprintf("%03d*T  %03d*M", trueBearing, geo.MagneticBearing(trueBearing, declination))
+}
+
+// routeLines describes the flight's route from its callsign: the airport
+// codes and distance left to fly, then the airport names when known
+func (d *DetailView) routeLines(ac *adsb.Aircraft) []detailLine {
+	if ac.FlightNumber == "" {
+		return nil
+	}
+	route, ok := d.routes.Lookup(ac.FlightNumber)
+	if !ok {
+		return nil
+	}
+
+	origin, _ := d.airports.Lookup(route.Origin)
+	destination, _ := d.airports.Lookup(route.Destination)
+
+	summary := route.Origin + " → " + route.Destination
+	if destination != nil && destination.Point != nil && ac.PositionLocked() {
+		position := geo.LatLon{Lat: *ac.Latitude, Lon: *ac.Longitude}
+		remaining := geo.Distance(position, *destination.Point)
+		summary += fmt.Sprintf("  %s to go", d.units.FormatDistance(remaining))
+	}
+
+	lines := []detailLine{{fmt.Sprintf("Route:         %s", summary), render.StyleLabel}}
+	if name := airportName(origin); name != "" {
+		lines = append(lines, detailLine{fmt.Sprintf("From:          %s", name), render.StyleLabel})
+	}
+	if name := airportName(destination); name != "" {
+		lines = append(lines, detailLine{fmt.Sprintf("To:            %s", name), render.StyleLabel})
+	}
+	return lines
+}
+
+// nearRange is how far, in miles, the detail view looks for an airport to
+// place the aircraft by
+const nearRange = 100
+
+// nearString places the aircraft relative to the nearest airport, e.g.
+// "14 nm NE of KDAL", or "-" without a position or an airport in range
+func (d *DetailView) nearString(ac *adsb.Aircraft) string {
+	if !ac.PositionLocked() {
+		return "-"
+	}
+	position := geo.LatLon{Lat: *ac.Latitude, Lon: *ac.Longitude}
+	airport, miles, ok := d.airports.Nearest(position, nearRange)
+	if !ok {
+		return "-"
+	}
+
+	code, _ := airport.Properties["ident"].(string)
+	if code == "" {
+		code = airport.Name
+	}
+	if d.units.FromMiles(miles) < 1 {
+		return "Over " + code
+	}
+	direction := geo.CompassPoint(geo.Bearing(*airport.Point, position))
+	return fmt.Sprintf("%s %s of %s", d.units.FormatDistance(miles), direction, code)
+}
+
+// interestString describes a plane-alert entry with its tags, e.g.
+// "USAF (Mil), Tanker, Stratotanker"
+func interestString(interest adsb.InterestInfo) string {
+	return strings.Join(append([]string{interest.String()}, interest.Tags...), ", ")
+}
+
+// airportName returns an airport's full name, or "" if it isn't known
+func airportName(airport *geo.Feature) string {
+	if airport == nil {
+		return ""
+	}
+	if name, ok := airport.Properties["full_name"].(string); ok && name != "" {
+		return name
+	}
+	return airport.Name
+}
+
+// typeString formats the type designator with its make and model,
+// preferring the generic type name ("Boeing 737-800") over the database's
+// model variant
+func (d *DetailView) typeString(info adsb.AircraftInfo) string {
+	description := d.aircraftDB.TypeDescription(info.TypeCode)
+	if description == "" {
+		description = info.Description
+	}
+	switch {
+	case info.TypeCode == "":
+		return orDash(description)
+	case description == "":
+		return info.TypeCode
+	}
+	return info.TypeCode + "  " + description
+}
+
+// orDash returns value, or "-" if it is empty
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// trendColumns is how many bars the altitude sparkline has, few enough
+// that the label, bars and a "10000-35000 ft" range fit the panel
+const trendColumns = 16
+
+// altitudeTrend draws the altitude history as a sparkline spanning
+// TrailDuration up to now, followed by the altitude range it covers in
+// units
+func altitudeTrend(history []adsb.AltitudeSample, now time.Time, units geo.Units) string {
+	if len(history) == 0 {
+		return "-"
+	}
+
+	low, high := history[0].Altitude, history[0].Altitude
+	for _, sample := range history {
+		low = min(low, sample.Altitude)
+		high = max(high, sample.Altitude)
+	}
+
+	// Each column shows the last altitude reported by its end, leaving
+	// columns before the first report blank
+	columns := make([]float64, trendColumns)
+	start := now.Add(-adsb.TrailDuration)
+	next := 0
+	for c := range columns {
+		end := start.Add(adsb.TrailDuration * time.Duration(c+1) / trendColumns)
+		for next < len(history) && !history[next].Time.After(end) {
+			next++
+		}
+		if next == 0 {
+			columns[c] = math.NaN()
+		} else {
+			columns[c] = float64(history[next-1].Altitude)
+		}
+	}
+
+	spark := render.Sparkline(columns, float64(low), float64(high))
+	if low == high {
+		return fmt.Sprintf("%s %d %s", spark, units.Altitude(low), units.AltitudeUnit())
+	}
+	return fmt.Sprintf("%s %d-%d %s", spark, units.Altitude(low), units.Altitude(high), units.AltitudeUnit())
+}
+
+// squawkString formats the squawk code with the emergency it signals
+func squawkString(ac *adsb.Aircraft) string {
+	squawk := ac.Squawk
+	if squawk == "" {
+		squawk = "----"
+	}
+	if kind := ac.EmergencyKind(); kind != "" {
+		squawk += "  " + kind
+	}
+	return squawk
+}
+
+// flagsString lists the status flags that are set
+func flagsString(ac *adsb.Aircraft) string {
+	var flags []string
+	if ac.Emergency {
+		flags = append(flags, "Emergency")
+	}
+	if ac.Alert {
+		flags = append(flags, "Alert (squawk changed)")
+	}
+	if ac.SPI {
+		flags = append(flags, "Ident")
+	}
+	if len(flags) == 0 {
+		return "None"
+	}
+	return strings.Join(flags, ", ")
+}
+
+// categoryString shows an emitter category code with its description,
+// e.g. "A7 Rotorcraft"
+func categoryString(code string) string {
+	if description := adsb.CategoryDescription(code); description != "" {
+		return code + " " + description
+	}
+	return code
+}
+
+// sourcesString names the source of the aircraft's data; when feeds
+// disagree it lists which fields each source last set, e.g. "Local SDR
+// (callsign, altitude) · MLAT (position)"
+func sourcesString(ac *adsb.Aircraft) string {
+	var sources []adsb.Source
+	fields := make(map[adsb.Source][]string)
+	for field, fs := range ac.Sources {
+		if fs.Time.IsZero() {
+			continue
+		}
+		if _, ok := fields[fs.Source]; !ok {
+			sources = append(sources, fs.Source)
+		}
+		fields[fs.Source] = append(fields[fs.Source], adsb.Field(field).String())
+	}
+
+	switch len(sources) {
+	case 0:
+		return ac.Source.Label()
+	case 1:
+		return sources[0].Label()
+	}
+	parts := make([]string, len(sources))
+	for i, source := range sources {
+		parts[i] = fmt.Sprintf("%s (%s)", source.Label(), strings.Join(fields[source], ", "))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// positionString formats the position followed by how it is sent, e.g.
+// "(MLAT)", once known
+func positionString(ac *adsb.Aircraft, format geo.CoordFormat) string {
+	position := ac.PositionStringFormat(format)
+	if ac.PositionLocked() && ac.PositionSource != adsb.PositionUnknown {
+		position += "  (" + ac.PositionSource.String() + ")"
+	}
+	return position
+}
+
+// receiverLines lists each feed hearing a merged aircraft with its message
+// rate, so receivers can be compared; a single feed gets no breakdown. The
+// rate comes first so a long feed name is what gets clipped.
+func receiverLines(ac *adsb.Aircraft, now time.Time) []detailLine {
+	if len(ac.Receivers) < 2 {
+		return nil
+	}
+
+	var lines []detailLine
+	for _, r := range ac.Receivers {
+		label := "               "
+		if len(lines) == 0 {
+			label = "Receivers:     "
+		}
+		style := render.StyleLabel
+		heard := fmt.Sprintf("%.1f msg/s", r.Rate(now))
+		if !r.Hearing(now) {
+			style = style.Dim(true)
+			heard = sinceString(r.LastSeen, now) + " ago"
+		}
+		lines = append(lines, detailLine{fmt.Sprintf("%s%-10s %s (%s)", label, heard, r.Feed, r.Source.Label()), style})
+	}
+	return lines
+}
+
+// callsignLines lists the callsigns the aircraft has used this session,
+// when it has used more than one, highlighting changes made in flight
+func callsignLines(ac *adsb.Aircraft) []detailLine {
+	if len(ac.Callsigns) < 2 {
+		return nil
+	}
+
+	var lines []detailLine
+	for _, use := range ac.Callsigns {
+		label := "               "
+		if len(lines) == 0 {
+			label = "Callsigns:     "
+		}
+		text := fmt.Sprintf("%s%-8s from %sZ", label, use.Callsign, use.Since.UTC().Format("15:04"))
+		style := render.StyleLabel
+		if use.InFlight {
+			text += " (airborne)" // Short enough for the panel width
+			style = render.StyleWatch
+		}
+		lines = append(lines, detailLine{text, style})
+	}
+	return lines
+}
+
+// maxSquawkLines is how many recent squawk codes the detail view lists
+const maxSquawkLines = 5
+
+// squawkLines lists the aircraft's recent squawk codes, newest last, when
+// it has changed squawk while tracked; emergency codes are highlighted
+func squawkLines(ac *adsb.Aircraft) []detailLine {
+	history := ac.SquawkHistory
+	if len(history) < 2 {
+		return nil
+	}
+	if len(history) > maxSquawkLines {
+		history = history[len(history)-maxSquawkLines:]
+	}
+
+	var lines []detailLine
+	for _, change := range history {
+		label := "               "
+		if len(lines) == 0 {
+			label = "Squawks:       "
+		}
+		style := render.StyleLabel
+		if adsb.IsEmergencySquawk(change.Squawk) {
+			style = render.StyleEmergency
+		}
+		lines = append(lines, detailLine{fmt.Sprintf("%s%s from %sZ", label, change.Squawk, change.Since.UTC().Format("15:04:05")), style})
+	}
+	return lines
+}
+
+// groundString describes whether the aircraft is airborne
+func groundString(ac *adsb.Aircraft) string {
+	switch {
+	case !ac.GroundKnown():
+		return "Unknown"
+	case ac.OnGround:
+		return "On ground"
+	default:
+		return "Airborne"
+	}
+}
+
 // drawEmpty draws an empty detail view
 func (d *DetailView) drawEmpty(screen tcell.Screen) {
 	// Clear the entire panel area first (make it opaque)
@@ -106,10 +525,8 @@ func (d *DetailView) drawEmpty(screen tcell.Screen) {
 }
 
 // drawLine draws a single line of text
-func (d *DetailView) drawLine(screen tcell.Screen, x, y int, text string) {
-	for i := 0; i < min(len(text), d.width-4); i++ {
-		screen.SetContent(x+i, y, rune(text[i]), nil, render.StyleLabel)
-	}
+func (d *DetailView) drawLine(screen tcell.Screen, x, y int, text string, style tcell.Style) {
+	drawClipped(screen, x, y, d.width-4, text, style)
 }
 
 // drawBorder draws the detail view border
@@ -132,10 +549,16 @@ func (d *DetailView) drawBorder(screen tcell.Screen) {
 	}
 }
 
+// Bounds returns the panel's screen rectangle
+func (d *DetailView) Bounds() (x, y, width, height int) {
+	return d.x, d.y, d.width, d.height
+}
+
 // UpdateDimensions updates the view dimensions
 func (d *DetailView) UpdateDimensions(x, y, width, height int) {
 	d.x = x
 	d.y = y
 	d.width = width
 	d.height = height
+	d.minHeight = height
 }
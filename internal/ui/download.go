@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"ascii1090/internal/adsb"
+	"ascii1090/internal/cache"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// commandDownload re-downloads a dataset in the background, following it
+// in a popup
+func (a *App) commandDownload(args []string) error {
+	if a.cache == nil {
+		return fmt.Errorf("no data cache to download into")
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("download needs a dataset: %s", strings.Join(cache.Datasets, ", "))
+	}
+	dataset := strings.ToLower(args[0])
+	if !slices.Contains(cache.Datasets, dataset) {
+		return fmt.Errorf("unknown dataset %q", args[0])
+	}
+	if a.loadView != nil && a.loadView.Running() {
+		a.showLoad = true
+		return fmt.Errorf("map data is still loading")
+	}
+	if a.fetchView != nil && a.fetchView.Running() {
+		a.showFetch = true
+		return fmt.Errorf("%s is still downloading", a.fetchName)
+	}
+
+	view := NewProgressView("Downloading "+dataset, "Downloaded "+dataset, "Failed to download "+dataset)
+	a.fetchView = view
+	a.fetchName = dataset
+	a.showFetch = true
+	a.cache.SetOutput(view)
+	log.Infof("Re-downloading %s", dataset)
+	go func() {
+		view.finish(a.cache.Redownload(dataset))
+	}()
+	return nil
+}
+
+// checkDownload reloads what a finished :download replaced. The aircraft
+// and plane-alert databases are swapped in place; map data is parsed at
+// startup, so it is picked up on the next run.
+func (a *App) checkDownload() {
+	if a.fetchView == nil {
+		return
+	}
+	finished, err := a.fetchView.result()
+	if !finished {
+		return
+	}
+	// The TUI still owns the terminal, so anything printed later is dropped
+	a.cache.SetOutput(io.Discard)
+
+	dataset := a.fetchName
+	if err != nil {
+		log.Warnf("Download of %s failed: %v", dataset, err)
+		a.fetchView.note("Error: %v", err)
+		a.statusBar.SetMessage("Failed to download %s", dataset)
+		return
+	}
+
+	switch dataset {
+	case "aircraft-db":
+		db, err := adsb.LoadAircraftDB(a.cache.GetAircraftDBPath())
+		if err != nil {
+			a.fetchView.note("Error: %v", err)
+			return
+		}
+		a.detailView.SetAircraftDB(db)
+		a.alerts.SetAircraftDB(db)
+		a.fetchView.note("Loaded %d aircraft", db.Len())
+	case "plane-alert":
+		db, err := adsb.LoadPlaneAlertDB(a.cache.GetPlaneAlertPath())
+		if err != nil {
+			a.fetchView.note("Error: %v", err)
+			return
+		}
+		a.detailView.SetPlaneAlertDB(db)
+		a.alerts.SetPlaneAlertDB(db)
+		a.fetchView.note("Loaded %d interesting aircraft", db.Len())
+	default:
+		a.fetchView.note("Restart ascii1090 to load the new data")
+	}
+	a.statusBar.SetMessage("Downloaded %s", dataset)
+}
@@ -0,0 +1,177 @@
+package ui
+
+import (
+	"ascii1090/internal/adsb"
+	"ascii1090/internal/render"
+	"fmt"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// FeedsWidth is the width of the feed health panel including its border
+const FeedsWidth = 72
+
+// FeedsView shows each connected feed's state, message rate, last message
+// and error counts in a panel at the top of the screen
+type FeedsView struct {
+	feeds         []adsb.FeedStatus
+	x, y          int
+	width, height int
+	maxHeight     int
+}
+
+// NewFeedsView creates a new feed panel no taller than maxHeight
+func NewFeedsView(x, y, width, maxHeight int) *FeedsView {
+	return &FeedsView{
+		x:         x,
+		y:         y,
+		width:     width,
+		height:    maxHeight,
+		maxHeight: maxHeight,
+	}
+}
+
+// SetFeeds sets the feeds shown and shrinks the panel to fit them
+func (f *FeedsView) SetFeeds(feeds []adsb.FeedStatus) {
+	f.feeds = feeds
+
+	lines := 1 // Column headings
+	for _, feed := range feeds {
+		lines++
+		if feed.LastError != "" {
+			lines++
+		}
+	}
+	f.height = min(lines+2, f.maxHeight)
+}
+
+// Draw renders the feed panel to the screen
+func (f *FeedsView) Draw(screen tcell.Screen) {
+	// Clear the panel area first (make it opaque)
+	for row := f.y + 1; row < f.y+f.height-1; row++ {
+		for col := f.x + 1; col < f.x+f.width-1; col++ {
+			screen.SetContent(col, row, ' ', nil, tcell.StyleDefault)
+		}
+	}
+
+	f.drawBorder(screen)
+
+	title := "Feeds"
+	titleX := f.x + (f.width-len(title))/2
+	for i, ch := range title {
+		screen.SetContent(titleX+i, f.y, ch, nil, render.StyleLabel)
+	}
+
+	row := f.y + 1
+	bottom := f.y + f.height - 1
+	heading := fmt.Sprintf("%-22s %-6s %-12s %7s %6s %5s %5s", "Feed", "Kind", "State", "Msg/s", "Last", "Bad", "Err")
+	f.drawText(screen, f.x+2, row, heading, render.StyleLabel.Bold(true))
+	row++
+
+	now := time.Now()
+	for _, feed := range f.feeds {
+		if row >= bottom {
+			break
+		}
+		style := render.StyleLabel
+		if feed.State != adsb.FeedConnected {
+			style = render.StyleEmergency
+		}
+		name := feed.Name
+		if len(name) > 22 {
+			name = name[:22]
+		}
+		line := fmt.Sprintf("%-22s %-6s %-12s %7.1f %6s %5d %5d",
+			name, feed.Source, feed.State, feed.Rate, sinceString(feed.LastMessage, now), feed.Malformed, feed.Errors)
+		f.drawText(screen, f.x+2, row, line, style)
+		row++
+
+		if feed.LastError != "" && row < bottom {
+			f.drawText(screen, f.x+4, row, feed.LastError, render.StyleLabel.Dim(true))
+			row++
+		}
+	}
+}
+
+// summarizeFeeds rates the feeds as a whole for the status bar: healthy
+// when all are connected, down when none are, degraded otherwise, with a
+// word or two saying what is wrong
+func summarizeFeeds(feeds []adsb.FeedStatus) (FeedHealth, string) {
+	connected, reconnecting := 0, 0
+	for _, feed := range feeds {
+		switch feed.State {
+		case adsb.FeedConnected:
+			connected++
+		case adsb.FeedReconnecting:
+			reconnecting++
+		}
+	}
+
+	switch {
+	case connected == len(feeds):
+		return FeedsHealthy, ""
+	case connected > 0:
+		return FeedsDegraded, fmt.Sprintf("%d/%d feeds", connected, len(feeds))
+	case reconnecting > 0:
+		return FeedsDown, "Reconnecting"
+	default:
+		return FeedsDown, "No data"
+	}
+}
+
+// sinceString formats how long ago t was, e.g. "3s" or "4m", or "-" if
+// it is zero
+func sinceString(t, now time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	ago := now.Sub(t)
+	switch {
+	case ago < time.Minute:
+		return fmt.Sprintf("%ds", int(ago.Seconds()))
+	case ago < time.Hour:
+		return fmt.Sprintf("%dm", int(ago.Minutes()))
+	default:
+		return fmt.Sprintf("%dh", int(ago.Hours()))
+	}
+}
+
+// drawText draws a string clipped to the panel interior
+func (f *FeedsView) drawText(screen tcell.Screen, x, y int, text string, style tcell.Style) {
+	drawClipped(screen, x, y, f.x+f.width-1-x, text, style)
+}
+
+// drawBorder draws the feed panel border
+func (f *FeedsView) drawBorder(screen tcell.Screen) {
+	style := render.StyleLabel
+
+	screen.SetContent(f.x, f.y, '┌', nil, style)
+	screen.SetContent(f.x+f.width-1, f.y, '┐', nil, style)
+	screen.SetContent(f.x, f.y+f.height-1, '└', nil, style)
+	screen.SetContent(f.x+f.width-1, f.y+f.height-1, '┘', nil, style)
+
+	for i := 1; i < f.width-1; i++ {
+		screen.SetContent(f.x+i, f.y, '─', nil, style)
+		screen.SetContent(f.x+i, f.y+f.height-1, '─', nil, style)
+	}
+
+	for i := 1; i < f.height-1; i++ {
+		screen.SetContent(f.x, f.y+i, '│', nil, style)
+		screen.SetContent(f.x+f.width-1, f.y+i, '│', nil, style)
+	}
+}
+
+// Bounds returns the panel's screen rectangle
+func (f *FeedsView) Bounds() (x, y, width, height int) {
+	return f.x, f.y, f.width, f.height
+}
+
+// UpdateDimensions updates the view position and size limit
+func (f *FeedsView) UpdateDimensions(x, y, width, maxHeight int) {
+	f.x = x
+	f.y = y
+	f.width = width
+	f.maxHeight = maxHeight
+	f.SetFeeds(f.feeds)
+}
@@ -0,0 +1,195 @@
+package ui
+
+import (
+	"ascii1090/internal/adsb"
+	"ascii1090/internal/geo"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// aircraftFilter is a parsed filter expression such as "alt>10000 spd<300";
+// an aircraft is shown when it meets every condition
+type aircraftFilter struct {
+	text       string
+	conditions []func(ac *adsb.Aircraft) bool
+}
+
+// filterNumbers are the numeric fields a filter can compare, in the
+// display units
+var filterNumbers = map[string]func(ac *adsb.Aircraft, units geo.Units) int{
+	"alt": func(ac *adsb.Aircraft, units geo.Units) int { return units.Altitude(ac.Altitude) },
+	"spd": func(ac *adsb.Aircraft, units geo.Units) int { return units.Speed(ac.Speed) },
+	"trk": func(ac *adsb.Aircraft, units geo.Units) int { return ac.Track },
+	"vs":  func(ac *adsb.Aircraft, units geo.Units) int { return units.VerticalRate(ac.VerticalRate) },
+}
+
+// filterStrings are the text fields a filter can match
+var filterStrings = map[string]func(ac *adsb.Aircraft) string{
+	"callsign": func(ac *adsb.Aircraft) string { return ac.FlightNumber },
+	"cat":      func(ac *adsb.Aircraft) string { return ac.Category },
+	"icao":     func(ac *adsb.Aircraft) string { return ac.ICAO },
+	"squawk":   func(ac *adsb.Aircraft) string { return ac.Squawk },
+}
+
+// filterCondition splits "field op value"
+var filterCondition = regexp.MustCompile(`^([a-z]+)(>=|<=|!=|=|>|<)(.+)$`)
+
+// parseFilter parses space-separated conditions: alt, spd, trk and vs
+// compare with = != < <= > >=, and callsign, cat, icao and squawk match
+// with = or != (case-insensitive, a trailing * matches a prefix). cat also
+// takes a category name such as heli or heavy. Numbers are in units, as
+// shown on screen.
+func parseFilter(text string, units geo.Units) (*aircraftFilter, error) {
+	filter := &aircraftFilter{text: strings.Join(strings.Fields(text), " ")}
+
+	for _, term := range strings.Fields(text) {
+		match := filterCondition.FindStringSubmatch(strings.ToLower(term))
+		if match == nil {
+			return nil, fmt.Errorf("expected field, operator and value, got %q", term)
+		}
+		field, op, value := match[1], match[2], match[3]
+
+		if number, ok := filterNumbers[field]; ok {
+			limit, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s needs a number, got %q", field, value)
+			}
+			filter.conditions = append(filter.conditions, func(ac *adsb.Aircraft) bool {
+				return compare(number(ac, units), op, limit)
+			})
+			continue
+		}
+
+		if get, ok := filterStrings[field]; ok {
+			if op != "=" && op != "!=" {
+				return nil, fmt.Errorf("%s can only be matched with = or !=", field)
+			}
+			pattern := strings.ToUpper(value)
+			if codes, ok := adsb.CategoryGroup(value); ok && field == "cat" {
+				filter.conditions = append(filter.conditions, func(ac *adsb.Aircraft) bool {
+					return slices.Contains(codes, strings.ToUpper(ac.Category)) == (op == "=")
+				})
+				continue
+			}
+			filter.conditions = append(filter.conditions, func(ac *adsb.Aircraft) bool {
+				return matchText(strings.ToUpper(strings.TrimSpace(get(ac))), pattern) == (op == "=")
+			})
+			continue
+		}
+
+		return nil, fmt.Errorf("unknown filter field %q (alt, spd, trk, vs, callsign, cat, icao, squawk)", field)
+	}
+
+	return filter, nil
+}
+
+// compare applies a comparison operator
+func compare(value int, op string, limit int) bool {
+	switch op {
+	case "=":
+		return value == limit
+	case "!=":
+		return value != limit
+	case "<":
+		return value < limit
+	case "<=":
+		return value <= limit
+	case ">":
+		return value > limit
+	default:
+		return value >= limit
+	}
+}
+
+// matchText matches a value against a pattern with an optional trailing *
+func matchText(value, pattern string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(value, prefix)
+	}
+	return value == pattern
+}
+
+// apply returns the aircraft meeting every condition; a nil filter keeps
+// them all
+func (f *aircraftFilter) apply(aircraft []*adsb.Aircraft) []*adsb.Aircraft {
+	if f == nil {
+		return aircraft
+	}
+
+	kept := make([]*adsb.Aircraft, 0, len(aircraft))
+	for _, ac := range aircraft {
+		if f.matches(ac) {
+			kept = append(kept, ac)
+		}
+	}
+	return kept
+}
+
+// matches reports whether an aircraft meets every condition
+func (f *aircraftFilter) matches(ac *adsb.Aircraft) bool {
+	for _, condition := range f.conditions {
+		if !condition(ac) {
+			return false
+		}
+	}
+	return true
+}
+
+// altitudeBand is a quick altitude filter applied on top of :filter
+type altitudeBand int
+
+const (
+	bandAll  altitudeBand = iota
+	bandLow               // Below 10,000 ft: pattern work and GA traffic
+	bandHigh              // At or above FL180: enroute traffic
+)
+
+// Altitude band limits in feet: the 10,000 ft speed limit and the bottom
+// of Class A airspace
+const (
+	lowBandCeiling = 10000
+	highBandFloor  = 18000
+)
+
+// String describes the band for the status bar and legend
+func (b altitudeBand) String() string {
+	switch b {
+	case bandLow:
+		return "Below 10000 ft"
+	case bandHigh:
+		return "FL180 and above"
+	default:
+		return "All altitudes"
+	}
+}
+
+// contains reports whether an aircraft is in the band; below 10,000 ft
+// counts aircraft on the ground but not ones with no altitude yet
+func (b altitudeBand) contains(ac *adsb.Aircraft) bool {
+	switch b {
+	case bandLow:
+		return ac.Altitude < lowBandCeiling && (ac.Altitude != 0 || ac.OnGround)
+	case bandHigh:
+		return ac.Altitude >= highBandFloor
+	default:
+		return true
+	}
+}
+
+// apply returns the aircraft in the band
+func (b altitudeBand) apply(aircraft []*adsb.Aircraft) []*adsb.Aircraft {
+	if b == bandAll {
+		return aircraft
+	}
+
+	kept := make([]*adsb.Aircraft, 0, len(aircraft))
+	for _, ac := range aircraft {
+		if b.contains(ac) {
+			kept = append(kept, ac)
+		}
+	}
+	return kept
+}
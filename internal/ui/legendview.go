@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"ascii1090/internal/render"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// LegendWidth is the width of the legend panel including its border
+const LegendWidth = 22
+
+// LegendView explains the map's symbols and colors in a panel on the
+// right edge of the screen
+type LegendView struct {
+	sections      []render.LegendSection
+	x, y          int
+	width, height int
+	maxHeight     int
+}
+
+// NewLegendView creates a new legend panel no taller than maxHeight
+func NewLegendView(x, y, width, maxHeight int) *LegendView {
+	return &LegendView{
+		x:         x,
+		y:         y,
+		width:     width,
+		height:    maxHeight,
+		maxHeight: maxHeight,
+	}
+}
+
+// SetSections sets the legend contents and shrinks the panel to fit them
+func (l *LegendView) SetSections(sections []render.LegendSection) {
+	l.sections = sections
+
+	lines := 0
+	for _, section := range sections {
+		if len(section.Entries) > 0 {
+			lines += 1 + len(section.Entries)
+		}
+	}
+	l.height = min(lines+2, l.maxHeight)
+}
+
+// Draw renders the legend to the screen
+func (l *LegendView) Draw(screen tcell.Screen) {
+	// Clear the panel area first (make it opaque)
+	for row := l.y + 1; row < l.y+l.height-1; row++ {
+		for col := l.x + 1; col < l.x+l.width-1; col++ {
+			screen.SetContent(col, row, ' ', nil, tcell.StyleDefault)
+		}
+	}
+
+	l.drawBorder(screen)
+
+	title := "Legend"
+	titleX := l.x + (l.width-len(title))/2
+	for i, ch := range title {
+		screen.SetContent(titleX+i, l.y, ch, nil, render.StyleLabel)
+	}
+
+	row := l.y + 1
+	bottom := l.y + l.height - 1
+	for _, section := range l.sections {
+		if len(section.Entries) == 0 {
+			continue
+		}
+		if row >= bottom {
+			break
+		}
+		l.drawText(screen, l.x+2, row, section.Title, render.StyleLabel.Bold(true))
+		row++
+
+		for _, entry := range section.Entries {
+			if row >= bottom {
+				break
+			}
+			l.drawText(screen, l.x+2, row, entry.Sample, entry.Style)
+			l.drawText(screen, l.x+6, row, entry.Label, render.StyleLabel)
+			row++
+		}
+	}
+}
+
+// drawText draws a string clipped to the panel interior
+func (l *LegendView) drawText(screen tcell.Screen, x, y int, text string, style tcell.Style) {
+	drawClipped(screen, x, y, l.x+l.width-1-x, text, style)
+}
+
+// drawBorder draws the legend border
+func (l *LegendView) drawBorder(screen tcell.Screen) {
+	style := render.StyleLabel
+
+	screen.SetContent(l.x, l.y, '┌', nil, style)
+	screen.SetContent(l.x+l.width-1, l.y, '┐', nil, style)
+	screen.SetContent(l.x, l.y+l.height-1, '└', nil, style)
+	screen.SetContent(l.x+l.width-1, l.y+l.height-1, '┘', nil, style)
+
+	for i := 1; i < l.width-1; i++ {
+		screen.SetContent(l.x+i, l.y, '─', nil, style)
+		screen.SetContent(l.x+i, l.y+l.height-1, '─', nil, style)
+	}
+
+	for i := 1; i < l.height-1; i++ {
+		screen.SetContent(l.x, l.y+i, '│', nil, style)
+		screen.SetContent(l.x+l.width-1, l.y+i, '│', nil, style)
+	}
+}
+
+// Bounds returns the panel's screen rectangle
+func (l *LegendView) Bounds() (x, y, width, height int) {
+	return l.x, l.y, l.width, l.height
+}
+
+// UpdateDimensions updates the view position and size limit
+func (l *LegendView) UpdateDimensions(x, y, width, maxHeight int) {
+	l.x = x
+	l.y = y
+	l.width = width
+	l.maxHeight = maxHeight
+	l.SetSections(l.sections)
+}
@@ -2,16 +2,28 @@ package ui
 
 import (
 	"ascii1090/internal/adsb"
+	"ascii1090/internal/geo"
 	"ascii1090/internal/render"
+
 	"github.com/gdamore/tcell/v2"
 )
 
+// ListWidth is the width of the aircraft list panel
+const ListWidth = 30
+
+// ListWidthWithRange is the list panel width when it also shows range and
+// bearing from the receiver
+const ListWidthWithRange = 41
+
 // ListView displays a scrollable list of aircraft
 type ListView struct {
 	aircraft      []*adsb.Aircraft
 	selectedIndex int
 	scrollOffset  int
 	maxVisible    int
+	receiver      *geo.LatLon
+	units         geo.Units
+	positionsOnly bool // Hide aircraft without a position lock
 	x, y          int
 	width, height int
 }
@@ -37,6 +49,25 @@ func NewListView(x, y, width, height int) *ListView {
 
 // Update refreshes the aircraft list
 func (l *ListView) Update(aircraft []*adsb.Aircraft) {
+	if l.positionsOnly {
+		located := make([]*adsb.Aircraft, 0, len(aircraft))
+		for _, ac := range aircraft {
+			if ac.PositionLocked() {
+				located = append(located, ac)
+			}
+		}
+		aircraft = located
+	}
+
+	// Keep the same aircraft selected as others come and go
+	if selected := l.GetSelected(); selected != nil {
+		for i, ac := range aircraft {
+			if ac.ICAO == selected.ICAO {
+				l.selectedIndex = i
+				break
+			}
+		}
+	}
 	l.aircraft = aircraft
 
 	if l.selectedIndex >= len(l.aircraft) {
@@ -49,6 +80,47 @@ func (l *ListView) Update(aircraft []*adsb.Aircraft) {
 	l.adjustScroll()
 }
 
+// rowStyle colors a list row with the aircraft's altitude band from the
+// map palette; rows without an altitude yet keep the plain list style
+func rowStyle(ac *adsb.Aircraft) tcell.Style {
+	if ac.Altitude == 0 {
+		return render.StyleListItem
+	}
+	if color := render.AltitudeColor(ac.Altitude); color != tcell.ColorDefault {
+		return render.StyleListItem.Foreground(color)
+	}
+	return render.StyleListItem
+}
+
+// TogglePositionsOnly hides or shows aircraft without a position lock and
+// returns whether they are now hidden; takes effect on the next Update
+func (l *ListView) TogglePositionsOnly() bool {
+	l.positionsOnly = !l.positionsOnly
+	return l.positionsOnly
+}
+
+// PositionsOnly reports whether aircraft without a position lock are hidden
+func (l *ListView) PositionsOnly() bool {
+	return l.positionsOnly
+}
+
+// SetPositionsOnly sets whether aircraft without a position lock are
+// hidden; takes effect on the next Update
+func (l *ListView) SetPositionsOnly(hidden bool) {
+	l.positionsOnly = hidden
+}
+
+// SetReceiver sets the receiver location; when set, each row shows the
+// aircraft's range and bearing from it
+func (l *ListView) SetReceiver(receiver *geo.LatLon) {
+	l.receiver = receiver
+}
+
+// SetUnits sets the units speeds, altitudes and ranges are shown in
+func (l *ListView) SetUnits(units geo.Units) {
+	l.units = units
+}
+
 // SelectNext moves selection down
 func (l *ListView) SelectNext() {
 	if l.selectedIndex < len(l.aircraft)-1 {
@@ -65,6 +137,19 @@ func (l *ListView) SelectPrev() {
 	}
 }
 
+// Select selects the aircraft with the given ICAO, reporting whether it is
+// in the list
+func (l *ListView) Select(icao string) bool {
+	for i, ac := range l.aircraft {
+		if ac.ICAO == icao {
+			l.selectedIndex = i
+			l.adjustScroll()
+			return true
+		}
+	}
+	return false
+}
+
 // adjustScroll adjusts scroll offset to keep selected item visible
 func (l *ListView) adjustScroll() {
 	if l.selectedIndex >= l.scrollOffset+l.maxVisible {
@@ -101,6 +186,9 @@ func (l *ListView) Draw(screen tcell.Screen) {
 	l.drawBorder(screen)
 
 	title := "Aircraft"
+	if l.positionsOnly {
+		title = "Aircraft (located)"
+	}
 	titleX := l.x + (l.width-len(title))/2
 	for i, ch := range title {
 		screen.SetContent(titleX+i, l.y, ch, nil, render.StyleLabel)
@@ -114,29 +202,26 @@ func (l *ListView) Draw(screen tcell.Screen) {
 		}
 
 		ac := l.aircraft[acIndex]
-		text := ac.ListDisplay()
+		text := ac.ListDisplay(l.units)
+		if l.receiver != nil {
+			text = ac.ListDisplayFrom(*l.receiver, l.units)
+		}
 
-		style := render.StyleListItem
+		style := rowStyle(ac)
 		if acIndex == l.selectedIndex {
 			style = render.StyleListSelected
 		}
 
 		x := l.x + 1
 		y := l.y + i + 1
-		for j := 0; j < min(len(text), l.width-2); j++ {
-			if j < len(text) {
-				screen.SetContent(x+j, y, rune(text[j]), nil, style)
-			}
-		}
-
-		for j := len(text); j < l.width-2; j++ {
+		used := drawClipped(screen, x, y, l.width-2, text, style)
+		for j := used; j < l.width-2; j++ {
 			screen.SetContent(x+j, y, ' ', nil, style)
 		}
 	}
 
 	if len(l.aircraft) > l.maxVisible {
-		scrollInfo := "↕"
-		screen.SetContent(l.x+l.width-2, l.y, rune(scrollInfo[0]), nil, render.StyleLabel)
+		screen.SetContent(l.x+l.width-2, l.y, '↕', nil, render.StyleLabel)
 	}
 }
 
@@ -160,6 +245,11 @@ func (l *ListView) drawBorder(screen tcell.Screen) {
 	}
 }
 
+// Bounds returns the panel's screen rectangle
+func (l *ListView) Bounds() (x, y, width, height int) {
+	return l.x, l.y, l.width, l.height
+}
+
 // UpdateDimensions updates the view dimensions
 func (l *ListView) UpdateDimensions(x, y, width, height int) {
 	l.x = x
@@ -2,9 +2,14 @@ package ui
 
 import (
 	"ascii1090/internal/adsb"
+	"ascii1090/internal/alert"
 	"ascii1090/internal/debug"
 	"ascii1090/internal/geo"
 	"ascii1090/internal/render"
+	"ascii1090/internal/weather"
+	"fmt"
+	"math"
+	"sort"
 
 	"github.com/gdamore/tcell/v2"
 )
@@ -19,44 +24,145 @@ type MapView struct {
 	height      int
 	radiusMiles float64
 	aspectRatio float64
+
+	routes   *geo.RouteTable
+	airports *geo.AirportIndex
+
+	weather     *weather.Fetcher
+	showWeather bool
+	windBarbs   bool
+
+	coverage *geo.CoverageGrid
+
+	// Kept so the view can be cloned into a new tab
+	features map[geo.FeatureType][]*geo.Feature
+	opts     Options
 }
 
 // NewMapView creates a new map view
-func NewMapView(width, height int, features map[geo.FeatureType][]*geo.Feature, radiusMiles float64, aspectRatio float64) *MapView {
+func NewMapView(width, height int, features map[geo.FeatureType][]*geo.Feature, opts Options) *MapView {
 	centerLat := 39.8283
 	centerLon := -98.5795
+	if opts.Receiver != nil {
+		centerLat, centerLon = opts.Receiver.Lat, opts.Receiver.Lon
+	}
+	radiusMiles := opts.RadiusMiles
+	aspectRatio := opts.AspectRatio
+	if opts.ZoomStep <= 1 {
+		opts.ZoomStep = DefaultZoomStep
+	}
 
 	projection := geo.NewProjection(centerLat, centerLon, radiusMiles, width, height, aspectRatio)
 	canvas := render.NewCanvas(width, height)
 	renderer := render.NewMapRenderer(projection, features, canvas)
+	renderer.SetAirportLabelMode(opts.AirportLabels)
+	renderer.SetRenderMode(opts.RenderMode)
+	renderer.SetASCII(opts.ASCII)
+	renderer.SetReceiver(opts.Receiver)
+	renderer.SetUnits(opts.Units)
+	if opts.HighwayDetail > 0 {
+		renderer.SetHighwayDetail(opts.HighwayDetail)
+	}
+	for _, layer := range opts.ShowLayers {
+		renderer.SetLayer(layer, true)
+	}
+	for _, layer := range opts.HideLayers {
+		renderer.SetLayer(layer, false)
+	}
 
 	return &MapView{
 		renderer:    renderer,
 		projection:  projection,
 		canvas:      canvas,
-		centerSet:   false,
+		centerSet:   opts.Receiver != nil,
 		width:       width,
 		height:      height,
 		radiusMiles: radiusMiles,
 		aspectRatio: aspectRatio,
+		routes:      opts.Routes,
+		airports:    geo.NewAirportIndex(features[geo.FeatureAirport]),
+		features:    features,
+		opts:        opts,
 	}
 }
 
+// Clone returns an independent map view showing the same place, zoom,
+// layers and toggles, for opening a new tab
+func (m *MapView) Clone() *MapView {
+	clone := NewMapView(m.width, m.height, m.features, m.opts)
+	lat, lon := m.projection.GetCenter()
+	clone.GoTo(lat, lon, m.radiusMiles)
+	clone.centerSet = m.centerSet
+
+	clone.renderer.SetLayers(m.renderer.Layers())
+	clone.renderer.SetAirportLabelMode(m.renderer.AirportLabelMode())
+	clone.renderer.SetHighwayDetail(m.renderer.HighwayDetail())
+	if clone.renderer.AircraftLabelsVisible() != m.renderer.AircraftLabelsVisible() {
+		clone.renderer.ToggleAircraftLabels()
+	}
+
+	clone.weather = m.weather
+	clone.showWeather = m.showWeather
+	clone.windBarbs = m.windBarbs
+	clone.coverage = m.coverage
+	return clone
+}
+
 // Draw renders the map view to the screen
 func (m *MapView) Draw(screen tcell.Screen, aircraft []*adsb.Aircraft, selectedICAO string) {
-	m.canvas.Clear()
+	m.renderer.Tick()
 
 	m.renderer.RenderMap()
+	m.renderer.RenderCoverage(m.coverage)
+
+	if m.weather != nil && m.showWeather {
+		m.weather.SetArea(m.projection.GetBounds())
+		m.renderer.RenderWeather(m.weather.Stations(), m.windBarbs)
+	}
+
+	m.drawSelectedRoute(aircraft, selectedICAO)
 
 	m.renderer.RenderAircraft(aircraft, selectedICAO)
 
 	m.canvas.Blit(screen, 0, 0)
 }
 
+// InvalidateRegion reports a rectangle drawn over the map by another
+// panel, so the map is resent there on the next frame
+func (m *MapView) InvalidateRegion(x, y, width, height int) {
+	m.canvas.InvalidateRegion(x, y, width, height)
+}
+
+// InvalidateAll makes the next frame resend the whole map to the screen
+func (m *MapView) InvalidateAll() {
+	m.canvas.InvalidateAll()
+}
+
+// SetCellPixels passes the terminal cell size in pixels to the renderer
+func (m *MapView) SetCellPixels(width, height int) {
+	m.renderer.SetCellPixels(width, height)
+}
+
+// RenderMode returns how map lines are being rendered
+func (m *MapView) RenderMode() render.RenderMode {
+	return m.renderer.RenderMode()
+}
+
+// GraphicsSequence returns the escape sequence for the map image in sixel
+// or kitty mode, or "" when there is nothing new to send
+func (m *MapView) GraphicsSequence(force bool) string {
+	return m.renderer.GraphicsSequence(force)
+}
+
+// ClearGraphicsSequence returns the escape sequence that removes the map image
+func (m *MapView) ClearGraphicsSequence() string {
+	return m.renderer.ClearGraphicsSequence()
+}
+
 // SetCenterFromFirstAircraft sets the map center to the first aircraft with coordinates
 func (m *MapView) SetCenterFromFirstAircraft(aircraft []*adsb.Aircraft) bool {
 	if m.centerSet {
-		return false 
+		return false
 	}
 
 	for _, ac := range aircraft {
@@ -66,9 +172,16 @@ func (m *MapView) SetCenterFromFirstAircraft(aircraft []*adsb.Aircraft) bool {
 
 			// Debug logging
 			bounds := m.projection.GetBounds()
-			debug.Log("Map centered on aircraft %s at %.4f, %.4f", ac.ICAO, *ac.Latitude, *ac.Longitude)
-			debug.Log("Visible bounds: lat[%.2f to %.2f] lon[%.2f to %.2f]",
-				bounds.MinLat, bounds.MaxLat, bounds.MinLon, bounds.MaxLon)
+			log.Event(debug.LevelInfo, "map_centered", ac.ICAO, debug.Fields{
+				"lat": *ac.Latitude,
+				"lon": *ac.Longitude,
+			})
+			log.Event(debug.LevelTrace, "visible_bounds", "", debug.Fields{
+				"min_lat": bounds.MinLat,
+				"max_lat": bounds.MaxLat,
+				"min_lon": bounds.MinLon,
+				"max_lon": bounds.MaxLon,
+			})
 
 			return true
 		}
@@ -93,6 +206,42 @@ func (m *MapView) GetProjection() *geo.Projection {
 	return m.projection
 }
 
+// ScreenOrder returns the positioned aircraft on screen ordered left to
+// right, then top to bottom
+func (m *MapView) ScreenOrder(aircraft []*adsb.Aircraft) []*adsb.Aircraft {
+	type placed struct {
+		ac    *adsb.Aircraft
+		point geo.Point
+	}
+	var visible []placed
+	for _, ac := range aircraft {
+		if !ac.PositionLocked() {
+			continue
+		}
+		point := m.projection.Project(*ac.Latitude, *ac.Longitude)
+		if point.X >= 0 && point.X < m.width && point.Y >= 0 && point.Y < m.height {
+			visible = append(visible, placed{ac, point})
+		}
+	}
+
+	sort.Slice(visible, func(i, j int) bool {
+		a, b := visible[i], visible[j]
+		if a.point.X != b.point.X {
+			return a.point.X < b.point.X
+		}
+		if a.point.Y != b.point.Y {
+			return a.point.Y < b.point.Y
+		}
+		return a.ac.ICAO < b.ac.ICAO
+	})
+
+	ordered := make([]*adsb.Aircraft, len(visible))
+	for i, v := range visible {
+		ordered[i] = v.ac
+	}
+	return ordered
+}
+
 // CenterOnAircraft centers the map on a specific aircraft
 func (m *MapView) CenterOnAircraft(ac *adsb.Aircraft) {
 	if ac == nil || !ac.PositionLocked() {
@@ -102,25 +251,68 @@ func (m *MapView) CenterOnAircraft(ac *adsb.Aircraft) {
 	m.projection.UpdateCenter(*ac.Latitude, *ac.Longitude)
 	m.centerSet = true
 
-	debug.Log("Map re-centered on aircraft %s at %.4f, %.4f", ac.ICAO, *ac.Latitude, *ac.Longitude)
+	log.Event(debug.LevelInfo, "map_recentered", ac.ICAO, debug.Fields{
+		"lat": *ac.Latitude,
+		"lon": *ac.Longitude,
+	})
+}
+
+// FollowAircraft keeps the map centered on a moving aircraft; unlike
+// CenterOnAircraft it is meant to be called every frame
+func (m *MapView) FollowAircraft(ac *adsb.Aircraft) {
+	if ac == nil || !ac.PositionLocked() {
+		return
+	}
+
+	if lat, lon := m.projection.GetCenter(); lat != *ac.Latitude || lon != geo.NormalizeLon(*ac.Longitude) {
+		m.projection.UpdateCenter(*ac.Latitude, *ac.Longitude)
+	}
+	m.centerSet = true
 }
 
-// ZoomIn decreases the radius (zooms in)
-func (m *MapView) ZoomIn() {
-	newRadius := m.radiusMiles * 0.75 
-	if newRadius < 10 {
-		newRadius = 10 
+// Zoom limits for the map radius
+const (
+	MinRadiusMiles = 1
+	MaxRadiusMiles = 1000
+)
+
+// CheckRadius fails for a radius in miles outside the zoom limits, giving
+// the limits in units
+func CheckRadius(miles float64, units geo.Units) error {
+	if miles < MinRadiusMiles*0.99 || miles > MaxRadiusMiles*1.01 {
+		return fmt.Errorf("radius must be %s-%s", units.FormatDistance(MinRadiusMiles), units.FormatDistance(MaxRadiusMiles))
 	}
-	m.SetRadius(newRadius)
+	return nil
 }
 
-// ZoomOut increases the radius (zooms out)
-func (m *MapView) ZoomOut() {
-	newRadius := m.radiusMiles * 1.33 
-	if newRadius > 1000 {
-		newRadius = 1000 
+// DefaultZoomStep is the factor + and - divide and multiply the radius by
+// unless -zoom-step says otherwise
+const DefaultZoomStep = 1.2
+
+// ZoomIn divides the radius by the zoom step (zooms in), keeping anchor
+// where it is on screen, or zooming about the center if anchor is nil
+func (m *MapView) ZoomIn(anchor *geo.LatLon) {
+	m.zoomAbout(math.Max(m.radiusMiles/m.opts.ZoomStep, MinRadiusMiles), anchor)
+}
+
+// ZoomOut multiplies the radius by the zoom step (zooms out), keeping
+// anchor where it is on screen, or zooming about the center if anchor is
+// nil
+func (m *MapView) ZoomOut(anchor *geo.LatLon) {
+	m.zoomAbout(math.Min(m.radiusMiles*m.opts.ZoomStep, MaxRadiusMiles), anchor)
+}
+
+// zoomAbout sets the radius, moving the center toward or away from anchor
+// in proportion so the anchor stays on the same screen cell
+func (m *MapView) zoomAbout(radiusMiles float64, anchor *geo.LatLon) {
+	if anchor != nil && m.projection.IsInBounds(anchor.Lat, anchor.Lon) {
+		scale := radiusMiles / m.radiusMiles
+		lat, lon := m.projection.GetCenter()
+		lat = anchor.Lat + (lat-anchor.Lat)*scale
+		lon = anchor.Lon + geo.NormalizeLon(lon-anchor.Lon)*scale
+		m.projection.UpdateCenter(lat, lon)
 	}
-	m.SetRadius(newRadius)
+	m.SetRadius(radiusMiles)
 }
 
 // SetRadius updates the map radius and recalculates the projection
@@ -129,10 +321,183 @@ func (m *MapView) SetRadius(radiusMiles float64) {
 	centerLat, centerLon := m.projection.GetCenter()
 	m.projection = geo.NewProjection(centerLat, centerLon, radiusMiles, m.width, m.height, m.aspectRatio)
 	m.renderer.UpdateProjection(m.projection)
-	debug.Log("Map radius changed to %.0f miles", radiusMiles)
+	log.Infof("Map radius changed to %.1f miles", radiusMiles)
+}
+
+// GoTo centers the map on a location, also zooming when radiusMiles is
+// positive
+func (m *MapView) GoTo(lat, lon, radiusMiles float64) {
+	m.projection.UpdateCenter(lat, lon)
+	m.centerSet = true
+	if radiusMiles > 0 {
+		m.SetRadius(radiusMiles)
+	}
+	log.Infof("Map moved to %.4f, %.4f", lat, lon)
 }
 
 // GetRadius returns the current map radius
 func (m *MapView) GetRadius() float64 {
 	return m.radiusMiles
 }
+
+// ToggleLayer shows or hides a map layer
+func (m *MapView) ToggleLayer(layer render.Layer) {
+	shown := m.renderer.ToggleLayer(layer)
+	log.Infof("Layer %s shown: %v", layer, shown)
+}
+
+// SetLayer shows or hides a map layer
+func (m *MapView) SetLayer(layer render.Layer, visible bool) {
+	m.renderer.SetLayer(layer, visible)
+	log.Infof("Layer %s shown: %v", layer, visible)
+}
+
+// SetHighwayDetail sets the highest road scalerank drawn
+func (m *MapView) SetHighwayDetail(level int) {
+	m.renderer.SetHighwayDetail(level)
+}
+
+// HighwayDetail returns the highest road scalerank drawn
+func (m *MapView) HighwayDetail() int {
+	return m.renderer.HighwayDetail()
+}
+
+// Legend describes the map symbols and colors currently in use
+func (m *MapView) Legend() []render.LegendSection {
+	return render.Legend(m.renderer.Layers(), m.weather != nil && m.showWeather, m.opts.Units)
+}
+
+// CycleAirportLabels switches airport labels between IATA, ICAO and name
+func (m *MapView) CycleAirportLabels() {
+	mode := m.renderer.AirportLabelMode().Next()
+	m.renderer.SetAirportLabelMode(mode)
+	log.Infof("Airport label mode: %s", mode)
+}
+
+// SetAirportLabels sets the airport label style
+func (m *MapView) SetAirportLabels(mode render.AirportLabelMode) {
+	m.renderer.SetAirportLabelMode(mode)
+	log.Infof("Airport label mode: %s", mode)
+}
+
+// SetWeather attaches a METAR fetcher whose stations are drawn on the map
+func (m *MapView) SetWeather(fetcher *weather.Fetcher) {
+	m.weather = fetcher
+	m.showWeather = true
+}
+
+// SetCoverage sets the grid the coverage heat layer shades the map from
+func (m *MapView) SetCoverage(grid *geo.CoverageGrid) {
+	m.coverage = grid
+}
+
+// ToggleWeather shows or hides METAR flight category dots
+func (m *MapView) ToggleWeather() {
+	m.showWeather = !m.showWeather
+	log.Infof("Weather layer shown: %v", m.showWeather)
+}
+
+// SetAlerts sets which aircraft to emphasize, keyed by ICAO
+func (m *MapView) SetAlerts(alerts map[string]alert.Kind) {
+	m.renderer.SetAlerts(alerts)
+}
+
+// ToggleAircraftLabels toggles callsign/flight level tags next to aircraft
+func (m *MapView) ToggleAircraftLabels() {
+	m.renderer.ToggleAircraftLabels()
+}
+
+// ToggleWindBarbs shows or hides wind arrows next to METAR dots
+func (m *MapView) ToggleWindBarbs() {
+	m.windBarbs = !m.windBarbs
+	log.Infof("Wind barbs shown: %v", m.windBarbs)
+}
+
+// SetFeatures replaces the geographic features shown and the airports
+// looked up by identifier
+func (m *MapView) SetFeatures(features map[geo.FeatureType][]*geo.Feature) {
+	m.features = features
+	m.airports = geo.NewAirportIndex(features[geo.FeatureAirport])
+	m.renderer.SetFeatures(features)
+}
+
+// Airports returns the index route airport codes are resolved with
+func (m *MapView) Airports() *geo.AirportIndex {
+	return m.airports
+}
+
+// AirportAt returns the airport drawn at or just left of screen cell x, y,
+// where its symbol and the start of its label are, when airports are shown
+func (m *MapView) AirportAt(x, y int) (*geo.Feature, bool) {
+	layers := m.renderer.Layers()
+	if !layers.Visible(render.LayerAirports) {
+		return nil, false
+	}
+
+	var found *geo.Feature
+	best := 0
+	for _, airport := range m.features[geo.FeatureAirport] {
+		if airport.Point == nil {
+			continue
+		}
+		point := m.projection.Project(airport.Point.Lat, airport.Point.Lon)
+		offset := x - point.X
+		if point.Y != y || offset < -1 || offset > 4 {
+			continue
+		}
+		if offset < 0 {
+			offset = -offset
+		}
+		if found == nil || offset < best {
+			found, best = airport, offset
+		}
+	}
+	return found, found != nil
+}
+
+// Runways returns the loaded runways of the airport with the given ident
+func (m *MapView) Runways(ident string) []*geo.Feature {
+	var runways []*geo.Feature
+	for _, runway := range m.features[geo.FeatureRunway] {
+		if airport, _ := runway.Properties["airport"].(string); airport == ident {
+			runways = append(runways, runway)
+		}
+	}
+	return runways
+}
+
+// drawSelectedRoute draws the great-circle route of the selected aircraft
+// when its callsign has a known origin and destination
+func (m *MapView) drawSelectedRoute(aircraft []*adsb.Aircraft, selectedICAO string) {
+	if m.routes == nil || selectedICAO == "" {
+		return
+	}
+
+	for _, ac := range aircraft {
+		if ac.ICAO != selectedICAO {
+			continue
+		}
+
+		origin, destination, ok := m.lookupRoute(ac)
+		if ok {
+			m.renderer.RenderRoute(origin, destination)
+		}
+		return
+	}
+}
+
+// lookupRoute resolves an aircraft's callsign to origin and destination airports
+func (m *MapView) lookupRoute(ac *adsb.Aircraft) (origin, destination *geo.Feature, ok bool) {
+	if ac == nil || ac.FlightNumber == "" {
+		return nil, nil, false
+	}
+
+	route, ok := m.routes.Lookup(ac.FlightNumber)
+	if !ok {
+		return nil, nil, false
+	}
+
+	origin, okOrigin := m.airports.Lookup(route.Origin)
+	destination, okDestination := m.airports.Lookup(route.Destination)
+	return origin, destination, okOrigin && okDestination
+}
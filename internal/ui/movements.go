@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"ascii1090/internal/adsb"
+	"ascii1090/internal/geo"
+	"math"
+)
+
+// An aircraft counts as arriving at or departing from an airport while it
+// is within movementRadius miles of it and movementCeiling feet above the
+// field, descending with its track within movementCone degrees of the
+// airport or climbing with its track within that of straight away
+const (
+	movementRadius  = 8.0
+	movementCeiling = 4000
+	movementCone    = 45.0
+)
+
+// airportMovements counts the aircraft seen arriving at and departing from
+// each airport this session, each aircraft once per airport and direction
+type airportMovements struct {
+	arrivals   map[string]map[string]bool // Airport ident to ICAOs
+	departures map[string]map[string]bool
+}
+
+// newAirportMovements creates empty movement counts
+func newAirportMovements() *airportMovements {
+	return &airportMovements{
+		arrivals:   make(map[string]map[string]bool),
+		departures: make(map[string]map[string]bool),
+	}
+}
+
+// observe checks every climbing or descending aircraft against its nearest
+// airport
+func (m *airportMovements) observe(aircraft []*adsb.Aircraft, airports *geo.AirportIndex) {
+	for _, ac := range aircraft {
+		if !ac.PositionLocked() || ac.Trend == adsb.TrendLevel {
+			continue
+		}
+
+		position := geo.LatLon{Lat: *ac.Latitude, Lon: *ac.Longitude}
+		airport, _, ok := airports.Nearest(position, movementRadius)
+		if !ok {
+			continue
+		}
+		elevation, _ := airport.Properties["elevation_ft"].(float64)
+		if float64(ac.Altitude) > elevation+movementCeiling {
+			continue
+		}
+		ident, _ := airport.Properties["ident"].(string)
+
+		toward := geo.Bearing(position, *airport.Point)
+		switch {
+		case ac.Trend == adsb.TrendDescending && angleBetween(float64(ac.Track), toward) <= movementCone:
+			addMovement(m.arrivals, ident, ac.ICAO)
+		case ac.Trend == adsb.TrendClimbing && angleBetween(float64(ac.Track), toward+180) <= movementCone:
+			addMovement(m.departures, ident, ac.ICAO)
+		}
+	}
+}
+
+// counts returns how many aircraft have arrived at and departed from the
+// airport with the given ident this session
+func (m *airportMovements) counts(ident string) (arrivals, departures int) {
+	return len(m.arrivals[ident]), len(m.departures[ident])
+}
+
+// addMovement adds an aircraft to an airport's set
+func addMovement(movements map[string]map[string]bool, ident, icao string) {
+	if movements[ident] == nil {
+		movements[ident] = make(map[string]bool)
+	}
+	movements[ident][icao] = true
+}
+
+// angleBetween returns the difference between two bearings, 0-180 degrees
+func angleBetween(a, b float64) float64 {
+	diff := math.Mod(math.Abs(a-b), 360)
+	if diff > 180 {
+		diff = 360 - diff
+	}
+	return diff
+}
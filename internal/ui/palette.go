@@ -0,0 +1,192 @@
+package ui
+
+import (
+	"ascii1090/internal/render"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// paletteCommand is a command typed at the : prompt; run gets the words
+// after the command name
+type paletteCommand struct {
+	usage string
+	run   func(a *App, args []string) error
+}
+
+// paletteCommands are the : commands; key action names from the [keys]
+// config table also work, doing what their key does
+var paletteCommands map[string]paletteCommand
+
+func init() {
+	paletteCommands = map[string]paletteCommand{
+		"radius":   {"radius <distance>[nm|km|mi]", (*App).commandRadius},
+		"center":   {"center <lat, lon | airport>", (*App).commandCenter},
+		"filter":   {"filter <alt>10000 callsign=UAL* ...> | off", (*App).commandFilter},
+		"layer":    {"layer <name> [on|off]", (*App).commandLayer},
+		"labels":   {"labels iata|icao|name", (*App).commandLabels},
+		"theme":    {"theme <name|path>", (*App).commandTheme},
+		"tab":      {"tab new|close|next|prev", (*App).commandTab},
+		"coverage": {"coverage <file.geojson|file.csv>", (*App).commandCoverage},
+		"download": {"download <dataset>", (*App).commandDownload},
+		"help":     {"help", (*App).commandHelp},
+	}
+}
+
+// runCommand runs a line typed at the : prompt
+func (a *App) runCommand(line string) {
+	fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(line), ":"))
+	if len(fields) == 0 {
+		return
+	}
+	name, args := strings.ToLower(fields[0]), fields[1:]
+
+	if command, ok := paletteCommands[name]; ok {
+		if err := command.run(a, args); err != nil {
+			a.statusBar.SetMessage("%s (usage: %s)", err, command.usage)
+		}
+		log.Infof("Command: %s", line)
+		return
+	}
+
+	if key, ok := keyActions[name]; ok && len(args) == 0 {
+		a.handleRune(key)
+		return
+	}
+
+	a.statusBar.SetMessage("Unknown command %q, try :help", name)
+}
+
+// commandRadius sets the map radius, given in the distance unit or with a
+// unit suffix
+func (a *App) commandRadius(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("radius needs a value")
+	}
+	miles, err := a.units.ParseDistance(strings.Join(args, ""))
+	if err != nil {
+		return err
+	}
+	if err := CheckRadius(miles, a.units); err != nil {
+		return err
+	}
+	// Rounding in the unit may land just outside the limits
+	miles = math.Max(MinRadiusMiles, math.Min(MaxRadiusMiles, miles))
+	a.mapView.SetRadius(miles)
+	a.statusBar.SetMessage("Radius %s", a.units.FormatDistance(miles))
+	return nil
+}
+
+// commandCenter recenters the map, like the go-to prompt
+func (a *App) commandCenter(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("center needs a location")
+	}
+	a.goTo(strings.Join(args, " "))
+	return nil
+}
+
+// commandFilter sets or clears the current tab's aircraft filter
+func (a *App) commandFilter(args []string) error {
+	if len(args) == 0 || (len(args) == 1 && strings.EqualFold(args[0], "off")) {
+		a.filter = nil
+		return nil
+	}
+	filter, err := parseFilter(strings.Join(args, " "), a.units)
+	if err != nil {
+		return err
+	}
+	a.filter = filter
+	return nil
+}
+
+// commandLayer shows, hides or toggles a map layer
+func (a *App) commandLayer(args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return fmt.Errorf("layer needs a name")
+	}
+	layers, err := render.ParseLayers(args[0])
+	if err != nil {
+		return err
+	}
+	if len(layers) != 1 {
+		return fmt.Errorf("layer takes one layer name, got %q", args[0])
+	}
+
+	if len(args) == 1 {
+		a.mapView.ToggleLayer(layers[0])
+		return nil
+	}
+	switch strings.ToLower(args[1]) {
+	case "on":
+		a.mapView.SetLayer(layers[0], true)
+	case "off":
+		a.mapView.SetLayer(layers[0], false)
+	default:
+		return fmt.Errorf("expected on or off, got %q", args[1])
+	}
+	return nil
+}
+
+// commandLabels sets the airport label style
+func (a *App) commandLabels(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("labels needs a style")
+	}
+	mode, err := render.ParseAirportLabelMode(args[0])
+	if err != nil {
+		return err
+	}
+	a.mapView.SetAirportLabels(mode)
+	return nil
+}
+
+// commandTheme switches to a bundled or user theme
+func (a *App) commandTheme(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("theme needs a name")
+	}
+	if render.IsMonochrome() {
+		return fmt.Errorf("themes don't apply in monochrome")
+	}
+	theme, err := render.LoadTheme(args[0], a.themesDir)
+	if err != nil {
+		return err
+	}
+	render.ApplyTheme(theme)
+	a.dayTheme = nil
+	a.graphicsDue = true
+	return nil
+}
+
+// commandTab opens, closes or switches tabs
+func (a *App) commandTab(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("tab needs an action")
+	}
+	switch strings.ToLower(args[0]) {
+	case "new":
+		a.newTab()
+	case "close":
+		a.closeTab()
+	case "next":
+		a.cycleTab(1)
+	case "prev":
+		a.cycleTab(-1)
+	default:
+		return fmt.Errorf("unknown tab action %q", args[0])
+	}
+	return nil
+}
+
+// commandHelp lists the commands in the status bar
+func (a *App) commandHelp(args []string) error {
+	names := make([]string, 0, len(paletteCommands))
+	for name := range paletteCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	a.statusBar.SetMessage("Commands: %s, or any key action", strings.Join(names, ", "))
+	return nil
+}
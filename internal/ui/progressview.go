@@ -0,0 +1,173 @@
+package ui
+
+import (
+	"ascii1090/internal/render"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// progressWidth is the width of the progress popup including its border
+const progressWidth = 64
+
+// progressLines is how many of the latest message lines the popup shows
+const progressLines = 8
+
+// ProgressView is a popup following work done in the background, such as
+// loading map data at startup or a :download. The work writes its messages
+// and carriage-return redrawn progress line to it as it would to a
+// terminal.
+type ProgressView struct {
+	mu      sync.Mutex
+	titles  [3]string // While running, when done, and when failed
+	lines   []string  // Finished lines, oldest first
+	current string    // Line being written, such as a redrawn progress line
+	done    bool
+	err     error
+	handled bool // Finish has been acted on by the UI
+	x, y    int
+	height  int
+}
+
+// NewProgressView creates a popup titled running until the work finishes,
+// then done or failed
+func NewProgressView(running, done, failed string) *ProgressView {
+	return &ProgressView{titles: [3]string{running, done, failed}}
+}
+
+// Write takes output meant for a terminal: \n ends a line, \r starts the
+// current one over
+func (d *ProgressView) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, segment := range strings.SplitAfter(string(p), "\n") {
+		if i := strings.LastIndex(segment, "\r"); i >= 0 {
+			d.current = ""
+			segment = segment[i+1:]
+		}
+		if line, ok := strings.CutSuffix(segment, "\n"); ok {
+			d.lines = append(d.lines, strings.TrimRight(d.current+line, " "))
+			d.current = ""
+		} else {
+			d.current += segment
+		}
+	}
+	return len(p), nil
+}
+
+// finish records how the work ended
+func (d *ProgressView) finish(err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.done = true
+	d.err = err
+}
+
+// result reports whether the work has ended and how, the first time it
+// is asked after the end
+func (d *ProgressView) result() (finished bool, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.done || d.handled {
+		return false, nil
+	}
+	d.handled = true
+	return true, d.err
+}
+
+// Running reports whether the work is still going
+func (d *ProgressView) Running() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return !d.done
+}
+
+// Warned reports whether any line is a warning or error, which the user
+// should get to read
+func (d *ProgressView) Warned() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.err != nil {
+		return true
+	}
+	for _, line := range d.lines {
+		if strings.HasPrefix(line, "Warning") || strings.HasPrefix(line, "Error") {
+			return true
+		}
+	}
+	return false
+}
+
+// note adds a line of the UI's own below the work's output
+func (d *ProgressView) note(format string, args ...any) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.lines = append(d.lines, fmt.Sprintf(format, args...))
+}
+
+// Draw renders the popup centered on the screen
+func (d *ProgressView) Draw(screen tcell.Screen) {
+	d.mu.Lock()
+	lines := d.lines[max(len(d.lines)-progressLines, 0):]
+	if current := strings.TrimSpace(d.current); current != "" {
+		lines = append(slices.Clip(lines), current)
+	}
+	title := d.titles[0]
+	if d.done {
+		title = d.titles[1]
+		if d.err != nil {
+			title = d.titles[2]
+		}
+	}
+	d.mu.Unlock()
+
+	screenWidth, screenHeight := screen.Size()
+	d.height = max(len(lines), 1) + 2
+	d.x = max((screenWidth-progressWidth)/2, 0)
+	d.y = max((screenHeight-d.height)/2, 1)
+
+	// Clear the panel area first (make it opaque)
+	for row := d.y + 1; row < d.y+d.height-1; row++ {
+		for col := d.x + 1; col < d.x+progressWidth-1; col++ {
+			screen.SetContent(col, row, ' ', nil, tcell.StyleDefault)
+		}
+	}
+
+	d.drawBorder(screen)
+
+	titleX := d.x + (progressWidth-len(title))/2
+	drawClipped(screen, titleX, d.y, d.x+progressWidth-1-titleX, title, render.StyleLabel)
+
+	for i, line := range lines {
+		drawClipped(screen, d.x+2, d.y+1+i, progressWidth-3, line, render.StyleLabel)
+	}
+}
+
+// drawBorder draws the popup border
+func (d *ProgressView) drawBorder(screen tcell.Screen) {
+	style := render.StyleLabel
+
+	screen.SetContent(d.x, d.y, '┌', nil, style)
+	screen.SetContent(d.x+progressWidth-1, d.y, '┐', nil, style)
+	screen.SetContent(d.x, d.y+d.height-1, '└', nil, style)
+	screen.SetContent(d.x+progressWidth-1, d.y+d.height-1, '┘', nil, style)
+
+	for i := 1; i < progressWidth-1; i++ {
+		screen.SetContent(d.x+i, d.y, '─', nil, style)
+		screen.SetContent(d.x+i, d.y+d.height-1, '─', nil, style)
+	}
+
+	for i := 1; i < d.height-1; i++ {
+		screen.SetContent(d.x, d.y+i, '│', nil, style)
+		screen.SetContent(d.x+progressWidth-1, d.y+i, '│', nil, style)
+	}
+}
+
+// Bounds returns the popup's screen rectangle
+func (d *ProgressView) Bounds() (x, y, width, height int) {
+	return d.x, d.y, progressWidth, d.height
+}
@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"ascii1090/internal/render"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Prompt is a one-line text input drawn over the status bar
+type Prompt struct {
+	label    string
+	input    []rune
+	onSubmit func(text string)
+	active   bool
+	x, y     int
+	width    int
+}
+
+// NewPrompt creates a new, closed prompt
+func NewPrompt(x, y, width int) *Prompt {
+	return &Prompt{
+		x:     x,
+		y:     y,
+		width: width,
+	}
+}
+
+// Open shows the prompt with an empty input; onSubmit is called with the
+// text when Enter is pressed
+func (p *Prompt) Open(label string, onSubmit func(text string)) {
+	p.label = label
+	p.input = p.input[:0]
+	p.onSubmit = onSubmit
+	p.active = true
+}
+
+// Active reports whether the prompt is open and taking keys
+func (p *Prompt) Active() bool {
+	return p.active
+}
+
+// HandleKey edits the input: Enter submits, Esc cancels
+func (p *Prompt) HandleKey(ev *tcell.EventKey) {
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		p.active = false
+
+	case tcell.KeyEnter:
+		p.active = false
+		if p.onSubmit != nil {
+			p.onSubmit(string(p.input))
+		}
+
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if len(p.input) > 0 {
+			p.input = p.input[:len(p.input)-1]
+		}
+
+	case tcell.KeyCtrlU:
+		p.input = p.input[:0]
+
+	case tcell.KeyRune:
+		p.input = append(p.input, ev.Rune())
+	}
+}
+
+// Draw renders the prompt, with a block cursor after the input
+func (p *Prompt) Draw(screen tcell.Screen) {
+	style := render.StyleStatusBar
+	for col := p.x; col < p.x+p.width; col++ {
+		screen.SetContent(col, p.y, ' ', nil, style)
+	}
+
+	used := drawClipped(screen, p.x, p.y, p.width, " "+p.label+" "+string(p.input), style)
+	if used < p.width {
+		screen.SetContent(p.x+used, p.y, ' ', nil, style.Reverse(true))
+	}
+}
+
+// Bounds returns the prompt's screen rectangle
+func (p *Prompt) Bounds() (x, y, width, height int) {
+	return p.x, p.y, p.width, 1
+}
+
+// UpdateDimensions updates the prompt position and width
+func (p *Prompt) UpdateDimensions(x, y, width int) {
+	p.x = x
+	p.y = y
+	p.width = width
+}
@@ -0,0 +1,210 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// saveScreenshot writes what is on screen to an ANSI-colored text file and
+// an HTML file in dir, returning the path of the ANSI file
+func saveScreenshot(screen tcell.Screen, dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create screenshot directory: %w", err)
+	}
+
+	base := filepath.Join(dir, "ascii1090-"+time.Now().Format("20060102-150405"))
+	ansiPath := base + ".ans"
+	if err := writeScreenFile(ansiPath, screen, writeANSI); err != nil {
+		return "", err
+	}
+	if err := writeScreenFile(base+".html", screen, writeHTML); err != nil {
+		return "", err
+	}
+	return ansiPath, nil
+}
+
+// writeScreenFile creates path and fills it using write
+func writeScreenFile(path string, screen tcell.Screen, write func(io.Writer, tcell.Screen)) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+
+	w := bufio.NewWriter(file)
+	write(w, screen)
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return file.Close()
+}
+
+// screenCells visits each screen cell row by row, skipping the second
+// column of wide characters; visit is called with x == -1 at each row end
+func screenCells(screen tcell.Screen, visit func(x int, char rune, style tcell.Style)) {
+	width, height := screen.Size()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			char, _, style, cellWidth := screen.GetContent(x, y)
+			if char == 0 {
+				char = ' '
+			}
+			visit(x, char, style)
+			if cellWidth > 1 {
+				x += cellWidth - 1
+			}
+		}
+		visit(-1, 0, tcell.StyleDefault)
+	}
+}
+
+// writeANSI writes the screen as text with SGR color escapes
+func writeANSI(w io.Writer, screen tcell.Screen) {
+	last := tcell.StyleDefault
+	screenCells(screen, func(x int, char rune, style tcell.Style) {
+		if x < 0 {
+			io.WriteString(w, "\x1b[0m\n")
+			last = tcell.StyleDefault
+			return
+		}
+		if style != last {
+			io.WriteString(w, sgr(style))
+			last = style
+		}
+		io.WriteString(w, string(char))
+	})
+}
+
+// sgr returns the escape sequence selecting a style from a reset state
+func sgr(style tcell.Style) string {
+	fg, bg, attrs := style.Decompose()
+
+	codes := []string{"0"}
+	if attrs&tcell.AttrBold != 0 {
+		codes = append(codes, "1")
+	}
+	if attrs&tcell.AttrDim != 0 {
+		codes = append(codes, "2")
+	}
+	if attrs&tcell.AttrItalic != 0 {
+		codes = append(codes, "3")
+	}
+	if attrs&tcell.AttrUnderline != 0 {
+		codes = append(codes, "4")
+	}
+	if attrs&tcell.AttrBlink != 0 {
+		codes = append(codes, "5")
+	}
+	if attrs&tcell.AttrReverse != 0 {
+		codes = append(codes, "7")
+	}
+	if code := sgrColor(fg, 38); code != "" {
+		codes = append(codes, code)
+	}
+	if code := sgrColor(bg, 48); code != "" {
+		codes = append(codes, code)
+	}
+	return "\x1b[" + strings.Join(codes, ";") + "m"
+}
+
+// sgrColor returns the SGR parameters for a foreground (38) or
+// background (48) color, or "" for the terminal default
+func sgrColor(color tcell.Color, base int) string {
+	switch {
+	case !color.Valid():
+		return ""
+	case color.IsRGB():
+		r, g, b := color.RGB()
+		return fmt.Sprintf("%d;2;%d;%d;%d", base, r, g, b)
+	default:
+		return fmt.Sprintf("%d;5;%d", base, color-tcell.ColorValid)
+	}
+}
+
+// HTML screenshots stand in these colors for the terminal defaults
+const (
+	htmlForeground = "#d0d0d0"
+	htmlBackground = "#000000"
+)
+
+// writeHTML writes the screen as a standalone HTML page of styled spans
+func writeHTML(w io.Writer, screen tcell.Screen) {
+	fmt.Fprintf(w, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>ascii1090 %s</title>\n</head>\n",
+		time.Now().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(w, "<body style=\"background:%s\">\n<pre style=\"color:%s;background:%s;font-family:monospace;line-height:1.1\">",
+		htmlBackground, htmlForeground, htmlBackground)
+
+	var run strings.Builder
+	var runStyle tcell.Style
+	flush := func() {
+		if run.Len() == 0 {
+			return
+		}
+		if css := htmlStyle(runStyle); css != "" {
+			fmt.Fprintf(w, "<span style=\"%s\">%s</span>", css, html.EscapeString(run.String()))
+		} else {
+			io.WriteString(w, html.EscapeString(run.String()))
+		}
+		run.Reset()
+	}
+
+	screenCells(screen, func(x int, char rune, style tcell.Style) {
+		if x < 0 {
+			flush()
+			io.WriteString(w, "\n")
+			return
+		}
+		if style != runStyle {
+			flush()
+			runStyle = style
+		}
+		run.WriteRune(char)
+	})
+
+	io.WriteString(w, "</pre>\n</body>\n</html>\n")
+}
+
+// htmlStyle returns inline CSS for a style, or "" for the defaults
+func htmlStyle(style tcell.Style) string {
+	fg, bg, attrs := style.Decompose()
+
+	fgCSS, bgCSS := fg.CSS(), bg.CSS()
+	if attrs&tcell.AttrReverse != 0 {
+		if fgCSS == "" {
+			fgCSS = htmlForeground
+		}
+		if bgCSS == "" {
+			bgCSS = htmlBackground
+		}
+		fgCSS, bgCSS = bgCSS, fgCSS
+	}
+
+	var css []string
+	if fgCSS != "" {
+		css = append(css, "color:"+fgCSS)
+	}
+	if bgCSS != "" {
+		css = append(css, "background:"+bgCSS)
+	}
+	if attrs&tcell.AttrBold != 0 {
+		css = append(css, "font-weight:bold")
+	}
+	if attrs&tcell.AttrDim != 0 {
+		css = append(css, "opacity:0.6")
+	}
+	if attrs&tcell.AttrItalic != 0 {
+		css = append(css, "font-style:italic")
+	}
+	if attrs&tcell.AttrUnderline != 0 {
+		css = append(css, "text-decoration:underline")
+	}
+	return strings.Join(css, ";")
+}
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"ascii1090/internal/adsb"
+	"ascii1090/internal/geo"
+	"io"
+	"maps"
+)
+
+// StartupData is what is loaded in the background after the UI starts
+type StartupData struct {
+	Features   map[geo.FeatureType][]*geo.Feature // Map features, merged over those given to NewApp
+	Tiles      *geo.FeatureTiles                  // Line features loaded around the map as it moves, may be nil
+	AircraftDB *adsb.AircraftDB                   // Registration, type and operator lookup, may be nil
+	PlaneAlert *adsb.PlaneAlertDB                 // Interesting aircraft to tag and alert on, may be nil
+}
+
+// StartupFunc loads map data and databases, writing its progress to out
+// as it would to a terminal
+type StartupFunc func(out io.Writer) (StartupData, error)
+
+// startLoading runs the startup loader in the background, following it in
+// a popup, so aircraft are shown while map data is still loading
+func (a *App) startLoading(load StartupFunc) {
+	if load == nil {
+		return
+	}
+	view := NewProgressView("Loading map data", "Loaded map data", "Failed to load map data")
+	a.loadView = view
+	a.showLoad = true
+	a.loaded = make(chan StartupData, 1)
+	go func() {
+		data, err := load(view)
+		view.finish(err)
+		a.loaded <- data
+	}()
+}
+
+// checkStartup hands what the startup loader produced to every tab, then
+// closes its popup unless there is a warning to read
+func (a *App) checkStartup() {
+	if a.loadView == nil {
+		return
+	}
+	finished, err := a.loadView.result()
+	if !finished {
+		return
+	}
+	data := <-a.loaded
+
+	if err != nil {
+		log.Warnf("Startup loading failed: %v", err)
+		a.loadView.note("Error: %v", err)
+		a.statusBar.SetMessage("Failed to load map data")
+	}
+	if len(data.Features) > 0 {
+		features := maps.Clone(a.mapView.features)
+		maps.Copy(features, data.Features)
+		for _, t := range a.tabs {
+			t.mapView.SetFeatures(features)
+		}
+		a.detailView.SetRoutes(a.mapView.routes, a.mapView.Airports())
+		log.Infof("Loaded %d feature types", len(features))
+	}
+	if data.Tiles != nil {
+		a.tiles = data.Tiles
+		a.tileLoads = make(chan tileLoad, 1)
+	}
+	if data.AircraftDB != nil {
+		a.detailView.SetAircraftDB(data.AircraftDB)
+		a.alerts.SetAircraftDB(data.AircraftDB)
+	}
+	if data.PlaneAlert != nil {
+		a.detailView.SetPlaneAlertDB(data.PlaneAlert)
+		a.alerts.SetPlaneAlertDB(data.PlaneAlert)
+	}
+	if !a.loadView.Warned() {
+		a.showLoad = false
+	}
+}
@@ -0,0 +1,148 @@
+package ui
+
+import (
+	"ascii1090/internal/geo"
+	"ascii1090/internal/render"
+	"ascii1090/internal/stats"
+	"fmt"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// statsSaveInterval is how often the daily statistics are written while
+// running, besides on exit
+const statsSaveInterval = 5 * time.Minute
+
+// StatsView shows the daily statistics, newest day first, in a panel at
+// the top of the screen
+type StatsView struct {
+	days          []stats.Day
+	units         geo.Units
+	x, y          int
+	width, height int
+	maxHeight     int
+}
+
+// NewStatsView creates a new statistics panel no taller than maxHeight
+func NewStatsView(x, y, width, maxHeight int, units geo.Units) *StatsView {
+	return &StatsView{
+		units:     units,
+		x:         x,
+		y:         y,
+		width:     width,
+		height:    maxHeight,
+		maxHeight: maxHeight,
+	}
+}
+
+// SetDays sets the days shown and shrinks the panel to fit them
+func (s *StatsView) SetDays(days []stats.Day) {
+	s.days = days
+	s.height = min(max(len(days), 1)+3, s.maxHeight) // Border and headings
+}
+
+// Draw renders the statistics panel to the screen
+func (s *StatsView) Draw(screen tcell.Screen) {
+	// Clear the panel area first (make it opaque)
+	for row := s.y + 1; row < s.y+s.height-1; row++ {
+		for col := s.x + 1; col < s.x+s.width-1; col++ {
+			screen.SetContent(col, row, ' ', nil, tcell.StyleDefault)
+		}
+	}
+
+	s.drawBorder(screen)
+
+	title := "Daily Statistics"
+	titleX := s.x + (s.width-len(title))/2
+	for i, ch := range title {
+		screen.SetContent(titleX+i, s.y, ch, nil, render.StyleLabel)
+	}
+
+	row := s.y + 1
+	bottom := s.y + s.height - 1
+	heading := fmt.Sprintf("%-10s %8s %10s  %-20s %s", "Date", "Aircraft", "Messages", "Max range", "Busiest hour")
+	s.drawText(screen, s.x+2, row, heading, render.StyleLabel.Bold(true))
+	row++
+
+	if len(s.days) == 0 {
+		s.drawText(screen, s.x+2, row, "No statistics yet", render.StyleLabel.Dim(true))
+		return
+	}
+
+	for _, day := range s.days {
+		if row >= bottom {
+			break
+		}
+		maxRange := "-"
+		if day.MaxRange > 0 {
+			maxRange = fmt.Sprintf("%s %s", s.units.FormatDistance(day.MaxRange), day.MaxRangeBy)
+		}
+		busiest := "-"
+		if hour, messages := day.BusiestHour(); messages > 0 {
+			busiest = fmt.Sprintf("%02d:00 (%d)", hour, messages)
+		}
+		line := fmt.Sprintf("%-10s %8d %10d  %-20s %s", day.Date, day.Aircraft, day.Messages, maxRange, busiest)
+		s.drawText(screen, s.x+2, row, line, render.StyleLabel)
+		row++
+	}
+}
+
+// drawText draws a string clipped to the panel interior
+func (s *StatsView) drawText(screen tcell.Screen, x, y int, text string, style tcell.Style) {
+	drawClipped(screen, x, y, s.x+s.width-1-x, text, style)
+}
+
+// drawBorder draws the statistics panel border
+func (s *StatsView) drawBorder(screen tcell.Screen) {
+	style := render.StyleLabel
+
+	screen.SetContent(s.x, s.y, '┌', nil, style)
+	screen.SetContent(s.x+s.width-1, s.y, '┐', nil, style)
+	screen.SetContent(s.x, s.y+s.height-1, '└', nil, style)
+	screen.SetContent(s.x+s.width-1, s.y+s.height-1, '┘', nil, style)
+
+	for i := 1; i < s.width-1; i++ {
+		screen.SetContent(s.x+i, s.y, '─', nil, style)
+		screen.SetContent(s.x+i, s.y+s.height-1, '─', nil, style)
+	}
+
+	for i := 1; i < s.height-1; i++ {
+		screen.SetContent(s.x, s.y+i, '│', nil, style)
+		screen.SetContent(s.x+s.width-1, s.y+i, '│', nil, style)
+	}
+}
+
+// Bounds returns the panel's screen rectangle
+func (s *StatsView) Bounds() (x, y, width, height int) {
+	return s.x, s.y, s.width, s.height
+}
+
+// UpdateDimensions updates the view position and size limit
+func (s *StatsView) UpdateDimensions(x, y, width, maxHeight int) {
+	s.x = x
+	s.y = y
+	s.width = width
+	s.maxHeight = maxHeight
+	s.SetDays(s.days)
+}
+
+// updateStats adds the messages and aircraft since the last update to the
+// daily statistics, saving them every statsSaveInterval
+func (a *App) updateStats() {
+	if a.stats == nil {
+		return
+	}
+
+	now := time.Now()
+	_, messages := a.tracker.Totals()
+	a.stats.Update(now, a.tracker.GetAll(), messages-a.statsTotal, a.receiver)
+	a.statsTotal = messages
+
+	if now.Sub(a.statsSaved) >= statsSaveInterval {
+		if err := a.stats.Save(); err != nil {
+			log.Warnf("Statistics not saved: %v", err)
+		}
+		a.statsSaved = now
+	}
+}
@@ -0,0 +1,186 @@
+package ui
+
+import (
+	"ascii1090/internal/geo"
+	"ascii1090/internal/render"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// StatusBar displays map and traffic summary information on the top row
+type StatusBar struct {
+	x, y        int
+	width       int
+	coordFormat geo.CoordFormat
+	units       geo.Units
+	cursorSet   bool
+	cursorX     int
+	cursorY     int
+	localTime   bool // Show local time next to the UTC clock
+
+	// Modes currently in effect, shown after the aircraft count
+	indicators []string
+
+	// Overall feed health, shown as a colored dot at the left end
+	feedHealth FeedHealth
+	feedDetail string
+
+	// A short notice shown in place of the cursor readout until it expires
+	message      string
+	messageUntil time.Time
+}
+
+// FeedHealth summarizes all feeds for the status bar's indicator dot
+type FeedHealth int
+
+const (
+	FeedsHealthy  FeedHealth = iota // Every feed is sending data
+	FeedsDegraded                   // Some feeds are stale or reconnecting
+	FeedsDown                       // No feed is sending data
+)
+
+// style returns the dot's style: green, yellow or red on the bar, or in
+// monochrome the bar's own style, bold when something is wrong
+func (h FeedHealth) style() tcell.Style {
+	style := render.StyleStatusBar
+	if render.IsMonochrome() {
+		return style.Bold(h != FeedsHealthy)
+	}
+	switch h {
+	case FeedsDegraded:
+		return style.Foreground(tcell.ColorOlive).Bold(true)
+	case FeedsDown:
+		return style.Foreground(tcell.ColorMaroon).Bold(true)
+	default:
+		return style.Foreground(tcell.ColorGreen).Bold(true)
+	}
+}
+
+// StatusMessageDuration is how long a status bar notice stays up
+const StatusMessageDuration = 4 * time.Second
+
+// NewStatusBar creates a new status bar
+func NewStatusBar(x, y, width int, coordFormat geo.CoordFormat) *StatusBar {
+	return &StatusBar{
+		x:           x,
+		y:           y,
+		width:       width,
+		coordFormat: coordFormat,
+	}
+}
+
+// SetCursor records the mouse position for the cursor coordinate readout
+func (s *StatusBar) SetCursor(x, y int) {
+	s.cursorX = x
+	s.cursorY = y
+	s.cursorSet = true
+}
+
+// ClearCursor hides the cursor coordinate readout
+func (s *StatusBar) ClearCursor() {
+	s.cursorSet = false
+}
+
+// SetLocalTime sets whether local time is shown after the UTC clock
+func (s *StatusBar) SetLocalTime(show bool) {
+	s.localTime = show
+}
+
+// SetUnits sets the unit the map radius is shown in
+func (s *StatusBar) SetUnits(units geo.Units) {
+	s.units = units
+}
+
+// clock returns the time readout at the right end of the bar
+func (s *StatusBar) clock(now time.Time) string {
+	clock := now.UTC().Format("15:04:05") + "Z"
+	if s.localTime {
+		clock += "  " + now.Local().Format("15:04 MST")
+	}
+	return clock + " "
+}
+
+// SetIndicators sets the active mode tags shown after the aircraft count
+func (s *StatusBar) SetIndicators(indicators []string) {
+	s.indicators = indicators
+}
+
+// SetFeedHealth sets the feed indicator; detail is shown next to the dot
+// when the feeds aren't healthy, so the state reads without color
+func (s *StatusBar) SetFeedHealth(health FeedHealth, detail string) {
+	s.feedHealth = health
+	s.feedDetail = detail
+}
+
+// SetMessage shows a notice on the right of the bar for a few seconds
+func (s *StatusBar) SetMessage(format string, args ...any) {
+	s.message = fmt.Sprintf(format, args...)
+	s.messageUntil = time.Now().Add(StatusMessageDuration)
+}
+
+// Draw renders the status bar
+// total is the number of tracked aircraft, located those with a position
+func (s *StatusBar) Draw(screen tcell.Screen, projection *geo.Projection, total, located int) {
+	style := render.StyleStatusBar
+
+	for col := s.x; col < s.x+s.width; col++ {
+		screen.SetContent(col, s.y, ' ', nil, style)
+	}
+
+	dot := "●"
+	if s.feedHealth != FeedsHealthy && s.feedDetail != "" {
+		dot += " " + s.feedDetail
+	}
+	indent := strings.Repeat(" ", render.TextWidth(dot)+2)
+
+	centerLat, centerLon := projection.GetCenter()
+	left := fmt.Sprintf("%s%s  R %s  %d/%d aircraft", indent,
+		geo.FormatLatLon(centerLat, centerLon, s.coordFormat),
+		s.units.FormatDistance(projection.GetRadius()),
+		located, total)
+	for _, indicator := range s.indicators {
+		left += "  [" + indicator + "]"
+	}
+	s.drawText(screen, s.x, left, style)
+	s.drawText(screen, s.x+1, dot, s.feedHealth.style())
+
+	now := time.Now()
+	clock := s.clock(now)
+	clockX := s.x + s.width - len(clock)
+	if clockX > s.x+len(left)+1 {
+		s.drawText(screen, clockX, clock, style)
+	} else {
+		clockX = s.x + s.width
+	}
+
+	right := ""
+	if s.message != "" && now.Before(s.messageUntil) {
+		right = s.message + " "
+	} else if s.cursorSet {
+		lat, lon := projection.Unproject(s.cursorX, s.cursorY)
+		right = fmt.Sprintf("Cursor %s ", geo.FormatLatLon(lat, lon, s.coordFormat))
+	}
+	if x := clockX - render.TextWidth(right) - 1; right != "" && x > s.x+len(left)+1 {
+		s.drawText(screen, x, right, style)
+	}
+}
+
+// drawText draws text clipped to the bar width
+func (s *StatusBar) drawText(screen tcell.Screen, x int, text string, style tcell.Style) {
+	drawClipped(screen, x, s.y, s.x+s.width-x, text, style)
+}
+
+// Bounds returns the bar's screen rectangle
+func (s *StatusBar) Bounds() (x, y, width, height int) {
+	return s.x, s.y, s.width, 1
+}
+
+// UpdateDimensions updates the status bar position and width
+func (s *StatusBar) UpdateDimensions(x, y, width int) {
+	s.x = x
+	s.y = y
+	s.width = width
+}
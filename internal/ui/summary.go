@@ -0,0 +1,153 @@
+package ui
+
+import (
+	"ascii1090/internal/geo"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"time"
+)
+
+// topAltitudes is how many of the highest aircraft the summary lists
+const topAltitudes = 5
+
+// Summary describes a session, printed after the terminal is restored
+type Summary struct {
+	Started    time.Time
+	Duration   time.Duration
+	Unique     int         // Different aircraft seen
+	Messages   int         // Messages processed
+	Feeds      []FeedTotal // Messages per feed
+	MaxRange   float64     // Farthest position from the receiver in miles, 0 without a receiver
+	MaxRangeBy string      // Aircraft that reached MaxRange
+	Highest    []Peak      // Highest aircraft, highest first
+	Units      geo.Units
+}
+
+// FeedTotal is how much one feed received over the session
+type FeedTotal struct {
+	Name      string `json:"name"`
+	Messages  int    `json:"messages"`
+	Malformed int    `json:"malformed"`
+}
+
+// Peak is the highest altitude one aircraft reached
+type Peak struct {
+	ICAO     string `json:"icao"`
+	Name     string `json:"name"`        // Callsign, or ICAO without one
+	Altitude int    `json:"altitude_ft"` // Feet
+}
+
+// Summary returns the totals for the session so far
+func (a *App) Summary() Summary {
+	unique, messages := a.tracker.Totals()
+	summary := Summary{
+		Started:    a.started,
+		Duration:   time.Since(a.started).Round(time.Second),
+		Unique:     unique,
+		Messages:   messages,
+		MaxRange:   a.maxRange,
+		MaxRangeBy: a.maxRangeBy,
+		Units:      a.units,
+	}
+
+	if a.feeds != nil {
+		for _, status := range a.feeds.Status() {
+			summary.Feeds = append(summary.Feeds, FeedTotal{Name: status.Name, Messages: status.Messages, Malformed: status.Malformed})
+		}
+	}
+
+	for _, peak := range a.peaks {
+		summary.Highest = append(summary.Highest, peak)
+	}
+	sort.Slice(summary.Highest, func(i, j int) bool {
+		if summary.Highest[i].Altitude != summary.Highest[j].Altitude {
+			return summary.Highest[i].Altitude > summary.Highest[j].Altitude
+		}
+		return summary.Highest[i].ICAO < summary.Highest[j].ICAO
+	})
+	if len(summary.Highest) > topAltitudes {
+		summary.Highest = summary.Highest[:topAltitudes]
+	}
+	return summary
+}
+
+// updateRecords notes the farthest aircraft position from the receiver
+// and each airborne aircraft's highest altitude
+func (a *App) updateRecords() {
+	for _, ac := range a.tracker.GetAll() {
+		if ac.Altitude > a.peaks[ac.ICAO].Altitude && !(ac.GroundKnown() && ac.OnGround) {
+			a.peaks[ac.ICAO] = Peak{ICAO: ac.ICAO, Name: ac.DisplayName(), Altitude: ac.Altitude}
+		}
+
+		if a.receiver == nil || !ac.PositionLocked() {
+			continue
+		}
+		distance := geo.Distance(*a.receiver, geo.LatLon{Lat: *ac.Latitude, Lon: *ac.Longitude})
+		if distance > a.maxRange {
+			a.maxRange = distance
+			a.maxRangeBy = ac.DisplayName()
+		}
+	}
+}
+
+// Print writes the summary as a few indented lines
+func (s Summary) Print(w io.Writer) {
+	fmt.Fprintln(w, "Session summary:")
+	fmt.Fprintf(w, "  Duration:  %s\n", s.Duration)
+	fmt.Fprintf(w, "  Aircraft:  %d\n", s.Unique)
+	fmt.Fprintf(w, "  Messages:  %d\n", s.Messages)
+	if len(s.Feeds) > 1 {
+		for _, feed := range s.Feeds {
+			fmt.Fprintf(w, "             %d from %s\n", feed.Messages, feed.Name)
+		}
+	}
+	if s.MaxRange > 0 {
+		fmt.Fprintf(w, "  Max range: %s (%s)\n", s.Units.FormatDistance(s.MaxRange), s.MaxRangeBy)
+	}
+	for i, peak := range s.Highest {
+		label := "           "
+		if i == 0 {
+			label = "  Highest: "
+		}
+		fmt.Fprintf(w, "%s%-8s %d %s\n", label, peak.Name, s.Units.Altitude(peak.Altitude), s.Units.AltitudeUnit())
+	}
+}
+
+// summaryFile is the JSON form of a Summary, in fixed units so files from
+// runs with different -units compare
+type summaryFile struct {
+	Started         time.Time   `json:"started"`
+	DurationSeconds int         `json:"duration_seconds"`
+	UniqueAircraft  int         `json:"unique_aircraft"`
+	Messages        int         `json:"messages"`
+	Feeds           []FeedTotal `json:"feeds"`
+	MaxRangeNM      float64     `json:"max_range_nm,omitempty"`
+	MaxRangeBy      string      `json:"max_range_by,omitempty"`
+	Highest         []Peak      `json:"highest"`
+}
+
+// WriteJSON saves the summary as JSON to path
+func (s Summary) WriteJSON(path string) error {
+	file := summaryFile{
+		Started:         s.Started.UTC(),
+		DurationSeconds: int(s.Duration.Seconds()),
+		UniqueAircraft:  s.Unique,
+		Messages:        s.Messages,
+		Feeds:           s.Feeds,
+		MaxRangeNM:      math.Round(geo.UnitsAviation.FromMiles(s.MaxRange)*10) / 10,
+		MaxRangeBy:      s.MaxRangeBy,
+		Highest:         s.Highest,
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session summary: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write session summary: %w", err)
+	}
+	return nil
+}
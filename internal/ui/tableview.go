@@ -0,0 +1,336 @@
+package ui
+
+import (
+	"ascii1090/internal/adsb"
+	"ascii1090/internal/alert"
+	"ascii1090/internal/geo"
+	"ascii1090/internal/render"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// tableRow is an aircraft with the derived values the table shows
+type tableRow struct {
+	ac       *adsb.Aircraft
+	distance float64   // Miles from the reference point, -1 without a position
+	units    geo.Units // Units values are shown in
+}
+
+// tableColumn describes one column of the aircraft table
+type tableColumn struct {
+	title string
+	width int
+	right bool // Right-align values
+	value func(r tableRow) string
+	less  func(a, b tableRow) bool
+}
+
+// tableColumns are the table's columns, left to right
+var tableColumns = []tableColumn{
+	{"Callsign", 8, false,
+		func(r tableRow) string { return r.ac.FlightNumber },
+		func(a, b tableRow) bool { return a.ac.FlightNumber < b.ac.FlightNumber }},
+	{"ICAO", 6, false,
+		func(r tableRow) string { return r.ac.ICAO },
+		func(a, b tableRow) bool { return a.ac.ICAO < b.ac.ICAO }},
+	{"Squawk", 6, false,
+		func(r tableRow) string { return r.ac.Squawk },
+		func(a, b tableRow) bool { return a.ac.Squawk < b.ac.Squawk }},
+	{"Alt", 6, true,
+		func(r tableRow) string { return intOrBlank(r.units.Altitude(r.ac.Altitude)) },
+		func(a, b tableRow) bool { return a.ac.Altitude < b.ac.Altitude }},
+	{"Spd", 4, true,
+		func(r tableRow) string { return intOrBlank(r.units.Speed(r.ac.Speed)) },
+		func(a, b tableRow) bool { return a.ac.Speed < b.ac.Speed }},
+	{"Trk", 4, true,
+		func(r tableRow) string { return intOrBlank(r.ac.Track) },
+		func(a, b tableRow) bool { return a.ac.Track < b.ac.Track }},
+	{"V/S", 6, true,
+		func(r tableRow) string {
+			if r.ac.VerticalRate == 0 {
+				return ""
+			}
+			return fmt.Sprintf("%+d", r.units.VerticalRate(r.ac.VerticalRate))
+		},
+		func(a, b tableRow) bool { return a.ac.VerticalRate < b.ac.VerticalRate }},
+	{"Dist", 6, true,
+		func(r tableRow) string {
+			if r.distance < 0 {
+				return ""
+			}
+			return fmt.Sprintf("%.1f", r.units.FromMiles(r.distance))
+		},
+		func(a, b tableRow) bool {
+			// Aircraft without a position sort last
+			if (a.distance < 0) != (b.distance < 0) {
+				return b.distance < 0
+			}
+			return a.distance < b.distance
+		}},
+	{"Age", 4, true,
+		func(r tableRow) string { return fmt.Sprintf("%ds", r.ac.SecondsSinceLastSeen()) },
+		func(a, b tableRow) bool { return a.ac.LastSeen.After(b.ac.LastSeen) }},
+	{"Msgs", 6, true,
+		func(r tableRow) string { return fmt.Sprintf("%d", r.ac.Messages) },
+		func(a, b tableRow) bool { return a.ac.Messages < b.ac.Messages }},
+}
+
+// intOrBlank formats a value, leaving zero (not yet received) blank
+func intOrBlank(value int) string {
+	if value == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d", value)
+}
+
+// TableView shows every tracked aircraft in a sortable full-screen table
+type TableView struct {
+	rows          []tableRow
+	alerts        map[string]alert.Kind
+	selectedICAO  string
+	selectedIndex int
+	scrollOffset  int
+	sortColumn    int
+	sortDesc      bool
+	units         geo.Units
+	x, y          int
+	width, height int
+}
+
+// NewTableView creates a new aircraft table
+func NewTableView(x, y, width, height int) *TableView {
+	return &TableView{
+		sortColumn: 1, // ICAO, matching the list
+		x:          x,
+		y:          y,
+		width:      width,
+		height:     height,
+	}
+}
+
+// SetUnits sets the units altitudes, speeds and distances are shown in
+func (t *TableView) SetUnits(units geo.Units) {
+	t.units = units
+}
+
+// Update refreshes the rows, measuring distances from reference, and keeps
+// the selected aircraft selected across re-sorting
+func (t *TableView) Update(aircraft []*adsb.Aircraft, reference geo.LatLon, alerts map[string]alert.Kind) {
+	t.alerts = alerts
+	t.rows = t.rows[:0]
+	for _, ac := range aircraft {
+		row := tableRow{ac: ac, distance: -1, units: t.units}
+		if ac.PositionLocked() {
+			row.distance = geo.Distance(reference, geo.LatLon{Lat: *ac.Latitude, Lon: *ac.Longitude})
+		}
+		t.rows = append(t.rows, row)
+	}
+	t.sort()
+}
+
+// sort orders the rows by the sort column and restores the selection
+func (t *TableView) sort() {
+	column := tableColumns[t.sortColumn]
+	sort.SliceStable(t.rows, func(i, j int) bool {
+		if t.sortDesc {
+			return column.less(t.rows[j], t.rows[i])
+		}
+		return column.less(t.rows[i], t.rows[j])
+	})
+
+	t.selectedIndex = min(t.selectedIndex, len(t.rows)-1)
+	for i, row := range t.rows {
+		if row.ac.ICAO == t.selectedICAO {
+			t.selectedIndex = i
+			break
+		}
+	}
+	t.selectedIndex = max(t.selectedIndex, 0)
+	t.syncSelection()
+}
+
+// SortNext sorts by the column to the right of the current one
+func (t *TableView) SortNext() {
+	t.sortColumn = (t.sortColumn + 1) % len(tableColumns)
+	t.sort()
+}
+
+// SortPrev sorts by the column to the left of the current one
+func (t *TableView) SortPrev() {
+	t.sortColumn = (t.sortColumn + len(tableColumns) - 1) % len(tableColumns)
+	t.sort()
+}
+
+// ReverseSort flips between ascending and descending order
+func (t *TableView) ReverseSort() {
+	t.sortDesc = !t.sortDesc
+	t.sort()
+}
+
+// Move moves the selection by delta rows
+func (t *TableView) Move(delta int) {
+	t.selectedIndex = max(min(t.selectedIndex+delta, len(t.rows)-1), 0)
+	t.syncSelection()
+}
+
+// Select selects the row of the aircraft with the given ICAO, if shown
+func (t *TableView) Select(icao string) {
+	for i, row := range t.rows {
+		if row.ac.ICAO == icao {
+			t.selectedIndex = i
+			t.syncSelection()
+			return
+		}
+	}
+}
+
+// PageSize returns how many rows fit on screen
+func (t *TableView) PageSize() int {
+	return max(t.height-3, 1) // Border and header
+}
+
+// GetSelected returns the selected aircraft, or nil if the table is empty
+func (t *TableView) GetSelected() *adsb.Aircraft {
+	if t.selectedIndex < len(t.rows) {
+		return t.rows[t.selectedIndex].ac
+	}
+	return nil
+}
+
+// syncSelection remembers the selected ICAO and scrolls it into view
+func (t *TableView) syncSelection() {
+	if ac := t.GetSelected(); ac != nil {
+		t.selectedICAO = ac.ICAO
+	}
+
+	page := t.PageSize()
+	if t.selectedIndex >= t.scrollOffset+page {
+		t.scrollOffset = t.selectedIndex - page + 1
+	}
+	if t.selectedIndex < t.scrollOffset {
+		t.scrollOffset = t.selectedIndex
+	}
+	t.scrollOffset = max(t.scrollOffset, 0)
+}
+
+// Draw renders the table to the screen
+func (t *TableView) Draw(screen tcell.Screen) {
+	for row := t.y; row < t.y+t.height; row++ {
+		for col := t.x; col < t.x+t.width; col++ {
+			screen.SetContent(col, row, ' ', nil, tcell.StyleDefault)
+		}
+	}
+
+	t.drawBorder(screen)
+
+	title := fmt.Sprintf(" Aircraft (%d) ", len(t.rows))
+	drawClipped(screen, t.x+(t.width-len(title))/2, t.y, t.width-2, title, render.StyleLabel)
+
+	// Header, marking the sort column and direction
+	headers := make([]string, len(tableColumns))
+	for i, column := range tableColumns {
+		header := column.title
+		if i == t.sortColumn {
+			if t.sortDesc {
+				header += "▼"
+			} else {
+				header += "▲"
+			}
+		}
+		headers[i] = header
+	}
+	t.drawRow(screen, t.y+1, headers, render.StyleLabel.Bold(true))
+
+	page := t.PageSize()
+	for i := 0; i < page; i++ {
+		index := t.scrollOffset + i
+		if index >= len(t.rows) {
+			break
+		}
+		row := t.rows[index]
+
+		style := render.StyleListItem
+		switch t.alerts[row.ac.ICAO] {
+		case alert.KindEmergency:
+			style = render.StyleEmergency
+		case alert.KindWatch, alert.KindProximity, alert.KindInteresting:
+			style = render.StyleWatch
+		}
+		if index == t.selectedIndex {
+			style = render.StyleListSelected
+		}
+
+		values := make([]string, len(tableColumns))
+		for c, column := range tableColumns {
+			values[c] = column.value(row)
+		}
+		t.drawRow(screen, t.y+2+i, values, style)
+	}
+
+	hint := " ←/→ sort  o reverse  Enter details  Esc map "
+	if len(t.rows) > page {
+		hint = fmt.Sprintf(" %d-%d of %d ", t.scrollOffset+1, min(t.scrollOffset+page, len(t.rows)), len(t.rows)) + hint[1:]
+	}
+	drawClipped(screen, t.x+2, t.y+t.height-1, t.width-4, hint, render.StyleLabel.Dim(true))
+}
+
+// drawRow draws one line of cells padded to the column widths
+func (t *TableView) drawRow(screen tcell.Screen, y int, cells []string, style tcell.Style) {
+	var line strings.Builder
+	for i, column := range tableColumns {
+		cell := cells[i]
+		if render.TextWidth(cell) > column.width {
+			cell = string([]rune(cell)[:column.width])
+		}
+		pad := strings.Repeat(" ", max(column.width-render.TextWidth(cell), 0))
+		if column.right {
+			line.WriteString(pad + cell)
+		} else {
+			line.WriteString(cell + pad)
+		}
+		line.WriteString("  ")
+	}
+
+	inner := t.width - 2
+	used := drawClipped(screen, t.x+1, y, inner, " "+line.String(), style)
+	for col := used; col < inner; col++ {
+		screen.SetContent(t.x+1+col, y, ' ', nil, style)
+	}
+}
+
+// drawBorder draws the table border
+func (t *TableView) drawBorder(screen tcell.Screen) {
+	style := render.StyleLabel
+
+	screen.SetContent(t.x, t.y, '┌', nil, style)
+	screen.SetContent(t.x+t.width-1, t.y, '┐', nil, style)
+	screen.SetContent(t.x, t.y+t.height-1, '└', nil, style)
+	screen.SetContent(t.x+t.width-1, t.y+t.height-1, '┘', nil, style)
+
+	for i := 1; i < t.width-1; i++ {
+		screen.SetContent(t.x+i, t.y, '─', nil, style)
+		screen.SetContent(t.x+i, t.y+t.height-1, '─', nil, style)
+	}
+
+	for i := 1; i < t.height-1; i++ {
+		screen.SetContent(t.x, t.y+i, '│', nil, style)
+		screen.SetContent(t.x+t.width-1, t.y+i, '│', nil, style)
+	}
+}
+
+// Bounds returns the table's screen rectangle
+func (t *TableView) Bounds() (x, y, width, height int) {
+	return t.x, t.y, t.width, t.height
+}
+
+// UpdateDimensions updates the view dimensions
+func (t *TableView) UpdateDimensions(x, y, width, height int) {
+	t.x = x
+	t.y = y
+	t.width = width
+	t.height = height
+	t.syncSelection()
+}
@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"io"
+)
+
+// MaxTabs is the most map tabs that can be open at once
+const MaxTabs = 9
+
+// tab is one independent map view with its own center, zoom, layers and
+// list filter; the app's mapView is always the current tab's
+type tab struct {
+	mapView       *MapView
+	followICAO    string
+	positionsOnly bool
+	filter        *aircraftFilter
+	band          altitudeBand
+}
+
+// saveTab records the per-tab state held by the app into the current tab
+func (a *App) saveTab() {
+	current := a.tabs[a.tabIndex]
+	current.mapView = a.mapView
+	current.followICAO = a.followICAO
+	current.positionsOnly = a.listView.PositionsOnly()
+	current.filter = a.filter
+	current.band = a.band
+}
+
+// switchTab makes tab index current, restoring its state into the app
+func (a *App) switchTab(index int) {
+	if index == a.tabIndex || index < 0 || index >= len(a.tabs) {
+		return
+	}
+
+	a.saveTab()
+	if seq := a.mapView.ClearGraphicsSequence(); seq != "" {
+		io.WriteString(a.terminal(), seq)
+	}
+
+	a.tabIndex = index
+	next := a.tabs[index]
+	a.mapView = next.mapView
+	a.followICAO = next.followICAO
+	a.listView.SetPositionsOnly(next.positionsOnly)
+	a.filter = next.filter
+	a.band = next.band
+	a.listView.Update(a.aircraft())
+
+	// The screen still shows the previous tab, so repaint everything
+	a.mapView.SetAlerts(a.alerts.ActiveKinds())
+	a.mapView.InvalidateAll()
+	a.updateCellPixels()
+	log.Infof("Switched to tab %d of %d", index+1, len(a.tabs))
+}
+
+// newTab opens a tab copying the current view and switches to it
+func (a *App) newTab() {
+	if len(a.tabs) >= MaxTabs {
+		a.statusBar.SetMessage("At most %d tabs can be open", MaxTabs)
+		return
+	}
+
+	a.saveTab()
+	a.tabs = append(a.tabs, &tab{
+		mapView:       a.mapView.Clone(),
+		positionsOnly: a.listView.PositionsOnly(),
+		filter:        a.filter,
+		band:          a.band,
+	})
+	a.switchTab(len(a.tabs) - 1)
+}
+
+// closeTab closes the current tab, keeping at least one open
+func (a *App) closeTab() {
+	if len(a.tabs) == 1 {
+		a.statusBar.SetMessage("Can't close the last tab")
+		return
+	}
+
+	closing := a.tabIndex
+	next := closing - 1
+	if next < 0 {
+		next = 1
+	}
+	a.switchTab(next)
+
+	a.tabs = append(a.tabs[:closing], a.tabs[closing+1:]...)
+	if a.tabIndex > closing {
+		a.tabIndex--
+	}
+}
+
+// cycleTab moves delta tabs along, wrapping around
+func (a *App) cycleTab(delta int) {
+	a.switchTab((a.tabIndex + delta + len(a.tabs)) % len(a.tabs))
+}
@@ -0,0 +1,22 @@
+package ui
+
+import (
+	"ascii1090/internal/render"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// drawClipped draws text at (x, y) in at most maxWidth cells, stepping two
+// cells for double-width characters, and returns the cells used
+func drawClipped(screen tcell.Screen, x, y, maxWidth int, text string, style tcell.Style) int {
+	used := 0
+	for _, ch := range text {
+		width := render.RuneWidth(ch)
+		if used+width > maxWidth {
+			break
+		}
+		screen.SetContent(x+used, y, ch, nil, style)
+		used += width
+	}
+	return used
+}
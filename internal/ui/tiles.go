@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"ascii1090/internal/geo"
+	"maps"
+	"time"
+)
+
+// Failed tile loads are retried after tileRetryDelay, doubling with each
+// failure in a row up to tileRetryMax
+const (
+	tileRetryDelay = 5 * time.Second
+	tileRetryMax   = 5 * time.Minute
+)
+
+// tileLoad is the line features loaded around the map in the background
+type tileLoad struct {
+	features map[geo.FeatureType][]*geo.Feature
+	err      error
+}
+
+// updateTiles hands finished tile loads to every tab, then starts loading
+// the tiled line features around the map in the background once it pans
+// or zooms out past those loaded
+func (a *App) updateTiles() {
+	if a.tiles == nil {
+		return
+	}
+
+	select {
+	case load := <-a.tileLoads:
+		a.tileBusy = false
+		if load.err != nil {
+			delay := tileRetryDelay << a.tileFails
+			if delay > tileRetryMax {
+				delay = tileRetryMax
+			} else {
+				a.tileFails++
+			}
+			a.tileRetry = time.Now().Add(delay)
+			log.Warnf("Map tiles not loaded, retrying in %s: %v", delay, load.err)
+			a.statusBar.SetMessage("Failed to load map tiles: %v", load.err)
+			return
+		}
+		a.tileFails = 0
+		if load.features == nil {
+			break // The same tiles as before
+		}
+		features := maps.Clone(a.mapView.features)
+		maps.Copy(features, load.features)
+		for _, t := range a.tabs {
+			t.mapView.SetFeatures(features)
+		}
+	default:
+	}
+
+	view := a.mapView.GetProjection().GetBounds()
+	if a.tileBusy || time.Now().Before(a.tileRetry) || a.tiles.Covers(view) {
+		return
+	}
+	a.tileBusy = true
+	tiles := a.tiles
+	go func() {
+		features, err := tiles.Load(view)
+		a.tileLoads <- tileLoad{features, err}
+	}()
+}
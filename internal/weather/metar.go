@@ -0,0 +1,348 @@
+package weather
+
+import (
+	"ascii1090/internal/debug"
+	"ascii1090/internal/geo"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// log is the weather package's debug scope
+var log = debug.NewScope("weather")
+
+// FlightCategory is the FAA flight category derived from ceiling and visibility
+type FlightCategory int
+
+const (
+	CategoryUnknown FlightCategory = iota
+	CategoryVFR                    // Ceiling > 3000 ft and visibility > 5 sm
+	CategoryMVFR                   // Ceiling 1000-3000 ft or visibility 3-5 sm
+	CategoryIFR                    // Ceiling 500-999 ft or visibility 1-3 sm
+	CategoryLIFR                   // Ceiling < 500 ft or visibility < 1 sm
+)
+
+// String returns the conventional abbreviation for the category
+func (c FlightCategory) String() string {
+	switch c {
+	case CategoryVFR:
+		return "VFR"
+	case CategoryMVFR:
+		return "MVFR"
+	case CategoryIFR:
+		return "IFR"
+	case CategoryLIFR:
+		return "LIFR"
+	default:
+		return "UNK"
+	}
+}
+
+// Station is a decoded METAR observation for a single reporting station
+type Station struct {
+	ICAO      string
+	Lat       float64
+	Lon       float64
+	Category  FlightCategory
+	WindDir   int  // Degrees true the wind is blowing from
+	WindSpeed int  // Knots
+	Variable  bool // Wind direction reported as VRB
+	Raw       string
+}
+
+// RefreshInterval is how often METARs are re-fetched; stations report
+// hourly with occasional specials, so more often just loads the server
+const RefreshInterval = 10 * time.Minute
+
+const metarURL = "https://aviationweather.gov/api/data/metar"
+
+// Failed fetches are retried after retryDelay, doubling with each failure
+// in a row up to RefreshInterval, so an offline or rate-limiting server
+// isn't asked again every frame
+const retryDelay = 30 * time.Second
+
+// Fetcher periodically downloads METARs for the area in view from the
+// NOAA Aviation Weather Center and caches the decoded stations
+type Fetcher struct {
+	client   *http.Client
+	mu       sync.RWMutex
+	stations []Station
+	area     *geo.Bounds
+	fetched  *geo.Bounds
+	retryAt  time.Time // No fetch is woken before this after a failure
+	failures int       // Failed fetches in a row
+	wake     chan struct{}
+}
+
+// NewFetcher creates a new METAR fetcher
+func NewFetcher() *Fetcher {
+	return &Fetcher{
+		client: &http.Client{Timeout: 30 * time.Second},
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// SetArea updates the region METARs should cover. A fetch is triggered
+// only when the new area isn't already inside the last fetched one, and
+// not while backing off after a failure.
+func (f *Fetcher) SetArea(bounds *geo.Bounds) {
+	f.mu.Lock()
+	f.area = bounds
+	covered := f.fetched != nil && within(bounds, f.fetched)
+	backingOff := time.Now().Before(f.retryAt)
+	f.mu.Unlock()
+
+	if !covered && !backingOff {
+		select {
+		case f.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Stations returns the most recently fetched observations
+func (f *Fetcher) Stations() []Station {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.stations
+}
+
+// Start runs the fetch loop in a background goroutine until ctx is cancelled
+func (f *Fetcher) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(RefreshInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-f.wake:
+			}
+
+			f.mu.RLock()
+			area := f.area
+			f.mu.RUnlock()
+			if area == nil {
+				continue
+			}
+
+			// Fetch a margin around the view so small pans don't refetch
+			padded := padBounds(area, 1)
+			stations, err := f.fetch(ctx, padded)
+			if err != nil {
+				f.mu.Lock()
+				delay := min(retryDelay<<f.failures, RefreshInterval)
+				f.failures = min(f.failures+1, 8)
+				f.retryAt = time.Now().Add(delay)
+				f.mu.Unlock()
+				log.Warnf("METAR fetch failed, retrying in %s: %v", delay, err)
+				continue
+			}
+
+			f.mu.Lock()
+			f.stations = stations
+			f.fetched = padded
+			f.failures = 0
+			f.retryAt = time.Time{}
+			f.mu.Unlock()
+			log.Infof("Fetched %d METARs", len(stations))
+		}
+	}()
+}
+
+// padBounds grows bounds by margin degrees on every side, clamped at the
+// poles. Longitudes may still run past ±180° like the view's; bboxes splits
+// them for the API.
+func padBounds(b *geo.Bounds, margin float64) *geo.Bounds {
+	return &geo.Bounds{
+		MinLat: math.Max(b.MinLat-margin, -90), MaxLat: math.Min(b.MaxLat+margin, 90),
+		MinLon: b.MinLon - margin, MaxLon: b.MaxLon + margin,
+	}
+}
+
+// within reports whether area lies inside fetched, either of which may
+// extend past ±180° longitude
+func within(area, fetched *geo.Bounds) bool {
+	if area.MinLat < fetched.MinLat || area.MaxLat > fetched.MaxLat {
+		return false
+	}
+	if fetched.MaxLon-fetched.MinLon >= 360 {
+		return true
+	}
+	for _, shift := range []float64{0, -360, 360} {
+		if area.MinLon+shift >= fetched.MinLon && area.MaxLon+shift <= fetched.MaxLon {
+			return true
+		}
+	}
+	return false
+}
+
+// bboxes splits bounds into the boxes within ±180° longitude the API
+// accepts: one normally, or two when the bounds cross the antimeridian
+func bboxes(b *geo.Bounds) []geo.Bounds {
+	if b.MaxLon-b.MinLon >= 360 {
+		return []geo.Bounds{{MinLat: b.MinLat, MaxLat: b.MaxLat, MinLon: -180, MaxLon: 180}}
+	}
+
+	minLon := geo.NormalizeLon(b.MinLon)
+	maxLon := minLon + (b.MaxLon - b.MinLon)
+	if maxLon <= 180 {
+		return []geo.Bounds{{MinLat: b.MinLat, MaxLat: b.MaxLat, MinLon: minLon, MaxLon: maxLon}}
+	}
+	return []geo.Bounds{
+		{MinLat: b.MinLat, MaxLat: b.MaxLat, MinLon: minLon, MaxLon: 180},
+		{MinLat: b.MinLat, MaxLat: b.MaxLat, MinLon: -180, MaxLon: maxLon - 360},
+	}
+}
+
+// metarJSON mirrors the fields used from the AWC JSON API. Wind direction
+// and visibility are numbers or strings ("VRB", "10+"), so they're decoded
+// loosely.
+type metarJSON struct {
+	ICAOID string      `json:"icaoId"`
+	Lat    float64     `json:"lat"`
+	Lon    float64     `json:"lon"`
+	WDir   interface{} `json:"wdir"`
+	WSpd   int         `json:"wspd"`
+	Visib  interface{} `json:"visib"`
+	FltCat string      `json:"fltCat"`
+	RawOb  string      `json:"rawOb"`
+	Clouds []struct {
+		Cover string `json:"cover"`
+		Base  *int   `json:"base"`
+	} `json:"clouds"`
+}
+
+// fetch downloads and decodes METARs within bounds, with one request for
+// each side of the antimeridian when they cross it
+func (f *Fetcher) fetch(ctx context.Context, b *geo.Bounds) ([]Station, error) {
+	var stations []Station
+	for _, box := range bboxes(b) {
+		found, err := f.fetchBox(ctx, &box)
+		if err != nil {
+			return nil, err
+		}
+		stations = append(stations, found...)
+	}
+	return stations, nil
+}
+
+// fetchBox downloads and decodes METARs within one bbox the API accepts
+func (f *Fetcher) fetchBox(ctx context.Context, b *geo.Bounds) ([]Station, error) {
+	url := fmt.Sprintf("%s?format=json&bbox=%.2f,%.2f,%.2f,%.2f", metarURL, b.MinLat, b.MinLon, b.MaxLat, b.MaxLon)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; ascii1090/1.0)")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download METARs: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download failed with status: %s", resp.Status)
+	}
+
+	var reports []metarJSON
+	if err := json.NewDecoder(resp.Body).Decode(&reports); err != nil {
+		return nil, fmt.Errorf("failed to parse METARs: %w", err)
+	}
+
+	stations := make([]Station, 0, len(reports))
+	for _, r := range reports {
+		station := Station{
+			ICAO:      r.ICAOID,
+			Lat:       r.Lat,
+			Lon:       r.Lon,
+			WindSpeed: r.WSpd,
+			Raw:       r.RawOb,
+		}
+
+		switch dir := r.WDir.(type) {
+		case float64:
+			station.WindDir = int(dir)
+		case string:
+			station.Variable = dir == "VRB"
+		}
+
+		station.Category = parseCategory(r.FltCat)
+		if station.Category == CategoryUnknown {
+			ceiling := -1
+			for _, cloud := range r.Clouds {
+				if (cloud.Cover == "BKN" || cloud.Cover == "OVC" || cloud.Cover == "OVX") && cloud.Base != nil {
+					if ceiling < 0 || *cloud.Base < ceiling {
+						ceiling = *cloud.Base
+					}
+				}
+			}
+			station.Category = Categorize(ceiling, parseVisibility(r.Visib))
+		}
+
+		stations = append(stations, station)
+	}
+
+	return stations, nil
+}
+
+// Categorize derives the flight category from a ceiling in feet AGL
+// (negative for none) and visibility in statute miles (negative if unknown)
+func Categorize(ceiling int, visibility float64) FlightCategory {
+	if visibility < 0 && ceiling < 0 {
+		return CategoryUnknown
+	}
+
+	switch {
+	case (ceiling >= 0 && ceiling < 500) || (visibility >= 0 && visibility < 1):
+		return CategoryLIFR
+	case (ceiling >= 0 && ceiling < 1000) || (visibility >= 0 && visibility < 3):
+		return CategoryIFR
+	case (ceiling >= 0 && ceiling <= 3000) || (visibility >= 0 && visibility <= 5):
+		return CategoryMVFR
+	default:
+		return CategoryVFR
+	}
+}
+
+// parseCategory maps the API's fltCat string to a FlightCategory
+func parseCategory(s string) FlightCategory {
+	switch strings.ToUpper(s) {
+	case "VFR":
+		return CategoryVFR
+	case "MVFR":
+		return CategoryMVFR
+	case "IFR":
+		return CategoryIFR
+	case "LIFR":
+		return CategoryLIFR
+	default:
+		return CategoryUnknown
+	}
+}
+
+// parseVisibility converts the API's visibility field to statute miles
+func parseVisibility(v interface{}) float64 {
+	switch vis := v.(type) {
+	case float64:
+		return vis
+	case string:
+		if f, err := strconv.ParseFloat(strings.TrimSuffix(vis, "+"), 64); err == nil {
+			return f
+		}
+	}
+	return -1
+}
@@ -2,25 +2,95 @@ package main
 
 import (
 	"ascii1090/internal/adsb"
+	"ascii1090/internal/alert"
 	"ascii1090/internal/cache"
+	"ascii1090/internal/config"
 	"ascii1090/internal/debug"
 	"ascii1090/internal/geo"
+	"ascii1090/internal/render"
+	"ascii1090/internal/stats"
 	"ascii1090/internal/ui"
 	"flag"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
+	_ "net/http/pprof"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 )
 
+// log is the debug scope for startup
+var log = debug.NewScope("main")
+
 func main() {
 	// Parse command line flags
 	help := flag.Bool("h", false, "Show help message")
-	networkAddr := flag.String("network", "", "Connect to remote dump1090 (e.g., 192.168.1.100:30003)")
+	networkAddr := flag.String("network", "", "Connect to remote dump1090 feeds, comma-separated, each host:port or kind=host:port with kind remote, mlat, uat or raw (e.g., 192.168.1.100:30003,mlat=192.168.1.100:30106)")
+	withLocal := flag.Bool("local", false, "Also start the local dump1090 when -network is given")
+	sourcePriority := flag.String("source-priority", "local,uat,remote,mlat", "Sources to trust first when feeds disagree, comma-separated; feeds of the same kind rank equally")
+	feedTimeout := flag.Int("feed-timeout", 0, "Reconnect a feed, or restart the local dump1090, after this many seconds without data, 0 for never (default: 0)")
+	mergePolicy := flag.String("merge", "priority", "How conflicting values from different feeds are reconciled: priority, latest, adsb, latency or average (default: priority)")
+	dump1090Binary := flag.String("dump1090", "dump1090", "dump1090 executable to start in local mode (name in PATH or full path)")
+	dump1090Args := flag.String("dump1090-args", "", "Extra arguments for the local dump1090, e.g. \"--gain 40 --device-index 1 --fix\"")
+	sbsPort := flag.Int("sbs-port", adsb.DefaultSBSPort, "SBS output port of the local dump1090 to connect to (default: 30003)")
 	cacheDir := flag.String("cache", "", "Cache directory for map data (default: ~/.ascii1090/data)")
+	proxyURL := flag.String("proxy", "", "HTTP(S) proxy URL for data downloads (default: from HTTP_PROXY/HTTPS_PROXY)")
+	mirrors := flag.String("mirror", "", "Comma-separated base URLs to try before the built-in download sources (files are fetched as <url>/<file name>)")
+	refresh := flag.Bool("refresh", false, "Re-download all cached map and airport data")
+	airportsMaxAge := flag.Int("airports-max-age", 30, "Re-download the airport, runway and navaid CSVs after this many days, 0 for never (default: 30)")
+	mapMaxAge := flag.Int("map-max-age", 0, "Re-download Natural Earth and airspace map data after this many days, 0 for never (default: 0)")
+	cacheLimit := flag.Int("cache-limit", 0, "Cache size budget in MB; optional datasets not used by this run are deleted, least recently used first, to stay under it (default: 0, no limit)")
+	featureMemory := flag.Int("feature-memory", 0, "Memory budget in MB for loaded map tiles; tiles scrolled off the map are unloaded, least recently seen first, to stay under it (default: 0, no limit)")
+	caBundle := flag.String("ca-bundle", "", "PEM file of extra CA certificates to trust for data downloads")
 	debugLog := flag.String("d", "", "Debug log file (e.g., debug.log)")
-	radiusMiles := flag.Float64("r", 150.0, "Map radius in miles (default: 150)")
+	debugScope := flag.String("dscope", "", "Comma-separated subsystems to debug log: "+strings.Join(debug.Scopes(), ", ")+" (default: all)")
+	logJSON := flag.Bool("log-json", false, "Write the debug log as JSON lines (time, level, component, event, icao, fields) for querying with jq")
+	logLevel := flag.String("log-level", "info", "Debug log level: error, warn, info or trace (default: info)")
+	logMaxSize := flag.Int("log-max-size", 10, "Rotate the debug log when it reaches this many MB, 0 for never (default: 10)")
+	pprofAddr := flag.String("pprof", "", "Serve net/http/pprof profiles on this address (e.g., :6060 or localhost:6060)")
+	logKeep := flag.Int("log-keep", 3, "Number of rotated debug logs to keep (default: 3)")
+	radius := flag.String("r", "150", "Map radius in the -units distance unit, or with an nm, km or mi suffix (default: 150)")
+	zoomStep := flag.Float64("zoom-step", ui.DefaultZoomStep, "Factor + and - divide and multiply the map radius by, above 1 (default: 1.2)")
 	aspectRatio := flag.Float64("a", 2.0, "Character aspect ratio - adjust for font width (1.0-4.0, default: 2.0)")
-	highwayDetail := flag.Int("H", 4, "Highway detail level - lower shows fewer roads (1-10, default: 4)")
+	highwayDetail := flag.Int("H", 4, "Highway detail level - lower shows fewer roads (1-10, default: 4); ( and ) change it while running")
+	overlayPaths := flag.String("overlay", "", "Comma-separated GeoJSON overlay files or directories (default: ~/.ascii1090/overlays)")
+	globalRoads := flag.Bool("global-roads", false, "Download worldwide road data for use outside North America")
+	detailName := flag.String("detail", "medium", "Coastline, river and border detail: medium (1:50m) or high (1:10m, for zooming in below 50 miles) (default: medium)")
+	renderModeName := flag.String("mode", "text", "Map line rendering: text, halfblock (▀▄ 1x2), quadrant (▚ 2x2), sixel or kitty (default: text)")
+	colorDepthName := flag.String("colors", "auto", "Color depth: auto, 16, 256 or truecolor (default: auto)")
+	asciiOnly := flag.Bool("ascii", false, "Draw with 7-bit ASCII only (for serial consoles and broken locales)")
+	monochrome := flag.Bool("mono", false, "Monochrome: no color, features told apart by glyph and bold/dim/reverse")
+	symbolSet := flag.String("symbols", "", "Aircraft symbols: arrows, plane (✈) or category (H helicopter, J jet, ...) (default: arrows, or the theme's)")
+	watchlistPath := flag.String("watchlist", "", "Watchlist file of ICAO hex codes, callsigns or registrations to highlight, one per line (default: ~/.ascii1090/watchlist.txt if present)")
+	receiverLat := flag.Float64("lat", 0, "Receiver latitude in decimal degrees (with -lon; centers range rings)")
+	receiverLon := flag.Float64("lon", 0, "Receiver longitude in decimal degrees (with -lat)")
+	showLayers := flag.String("show", "", "Comma-separated map layers to turn on at startup (e.g. timezones,rings)")
+	hideLayers := flag.String("hide", "", "Comma-separated map layers to turn off at startup (e.g. highways,rivers)")
+	themeName := flag.String("theme", "", "Theme name (default, amber, solarized, high-contrast, night) or path to a TOML theme file")
+	unitsName := flag.String("units", "imperial", "Units: aviation (nm, kts, ft), imperial (mi, mph, ft) or metric (km, km/h, m) (default: imperial)")
+	coordFormat := flag.String("coords", "decimal", "Coordinate format: decimal, ddm (deg/decimal minutes) or dms (default: decimal)")
+	airportLabels := flag.String("labels", "iata", "Airport labels: iata, icao or name (default: iata)")
+	metar := flag.Bool("metar", false, "Show METAR flight categories for airports in view (fetched from aviationweather.gov)")
+	routesFile := flag.String("routes", "", "Routes CSV file mapping callsigns to origin/destination (default: routes.csv in the cache directory)")
+	aircraftDBFile := flag.String("aircraft-db", "", "Aircraft database CSV with registration, type and operator by ICAO hex (default: aircraft.csv in the cache directory)")
+	downloadAircraftDB := flag.Bool("download-aircraft-db", false, "Download the OpenSky aircraft database (about 80 MB) into the cache directory and keep it up to date")
+	planeAlertFile := flag.String("plane-alert", "", "plane-alert-db CSV of interesting aircraft (government, military, notable owners) to tag and alert on (default: plane-alert-db.csv in the cache directory)")
+	downloadPlaneAlert := flag.Bool("download-plane-alert", false, "Download the community plane-alert database into the cache directory and keep it up to date")
+	waypointsFile := flag.String("waypoints", "", "Waypoints CSV file with name,lat,lon[,symbol] lines (default: ~/.ascii1090/waypoints.csv)")
+	positionsOnly := flag.Bool("positions-only", false, "Start with aircraft lacking a position hidden from the list")
+	proximityRadius := flag.Float64("proximity", 0, "Alert on aircraft within this distance of the receiver (-lat/-lon), in the -units distance unit, 0 for off (default: 0)")
+	proximityCeiling := flag.Int("proximity-alt", 0, "Altitude above sea level, in the -units altitude unit, at or below which -proximity alerts (default: 3000 ft, 914 m)")
+	beepKinds := flag.String("beep", "", "Comma-separated alert kinds that sound: emergency, watch, proximity, interesting or all (default: none)")
+	soundCommand := flag.String("sound-cmd", "", "Shell command run for sounding alerts instead of the terminal bell (gets ALERT_KIND, ALERT_ICAO, ALERT_MESSAGE)")
+	statsFile := flag.String("stats", "", "File the daily statistics (aircraft, messages, max range, busiest hour) are kept in, or off (default: ~/.ascii1090/stats.json)")
+	coverageFile := flag.String("coverage", "", "On exit, export where positions were received as a GeoJSON grid, or CSV for a .csv file")
+	summaryJSON := flag.String("summary-json", "", "Also write the session summary printed on exit to this JSON file")
+	confirmQuit := flag.Bool("confirm-quit", false, "Ask for q or Esc to be pressed twice before quitting")
+	localTime := flag.Bool("local-time", false, "Show local time next to the UTC clock in the status bar")
+	configFile := flag.String("config", "", "Config file whose settings apply unless overridden by flags (default: ~/.ascii1090/config.toml if present)")
 	flag.Parse()
 
 	// Show help if requested
@@ -32,31 +102,268 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Fill in flags not given on the command line from the config file; the
+	// default file is optional, an explicit one is not
+	configPath := *configFile
+	if configPath == "" {
+		if path, err := config.DefaultPath(); err == nil && fileExists(path) {
+			configPath = path
+		}
+	}
+	var keyMap map[rune]rune
+	var bookmarks []config.Bookmark
+	if configPath != "" {
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		warnings, err := cfg.ApplyFlags(flag.CommandLine)
+		for _, warning := range warnings {
+			fmt.Printf("Warning: %s\n", warning)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		keyMap, err = ui.ParseKeyBindings(cfg.KeyBindings())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", configPath, err)
+			os.Exit(1)
+		}
+		bookmarks = cfg.Bookmarks()
+	}
+
+	// New bookmarks go to the config file in use, or start the default one
+	bookmarkPath := configPath
+	if bookmarkPath == "" {
+		bookmarkPath, _ = config.DefaultPath()
+	}
+
 	// Validate aspect ratio
 	if *aspectRatio < 1.0 || *aspectRatio > 4.0 {
 		fmt.Fprintf(os.Stderr, "Error: Aspect ratio must be between 1.0 and 4.0\n")
 		os.Exit(1)
 	}
 
+	if *sbsPort < 1 || *sbsPort > 65535 {
+		fmt.Fprintf(os.Stderr, "Error: SBS port must be between 1 and 65535\n")
+		os.Exit(1)
+	}
+
+	feedSpecs, err := adsb.ParseFeedSpecs(*networkAddr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	precedence, err := adsb.ParseSources(*sourcePriority)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	policy, err := adsb.ParseMergePolicy(*mergePolicy)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	level, err := debug.ParseLevel(*logLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *debugScope != "" {
+		if err := debug.SetScopes(strings.Split(*debugScope, ",")); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *logMaxSize < 0 || *logKeep < 0 {
+		fmt.Fprintf(os.Stderr, "Error: Log size and count must be 0 or more\n")
+		os.Exit(1)
+	}
+
+	if *cacheLimit < 0 {
+		fmt.Fprintf(os.Stderr, "Error: Cache limit must be 0 or more MB\n")
+		os.Exit(1)
+	}
+
+	if *featureMemory < 0 {
+		fmt.Fprintf(os.Stderr, "Error: Feature memory must be 0 or more MB\n")
+		os.Exit(1)
+	}
+
+	if *airportsMaxAge < 0 || *mapMaxAge < 0 {
+		fmt.Fprintf(os.Stderr, "Error: Refresh ages must be 0 or more days\n")
+		os.Exit(1)
+	}
+
 	// Validate highway detail level
 	if *highwayDetail < 1 || *highwayDetail > 10 {
 		fmt.Fprintf(os.Stderr, "Error: Highway detail level must be between 1 and 10\n")
 		os.Exit(1)
 	}
 
+	detail, err := geo.ParseDetailLevel(*detailName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	labelMode, err := render.ParseAirportLabelMode(*airportLabels)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	units, err := geo.ParseUnits(*unitsName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	radiusMiles, err := units.ParseDistance(*radius)
+	if err == nil {
+		err = ui.CheckRadius(radiusMiles, units)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *zoomStep <= 1 || *zoomStep > 4 {
+		fmt.Fprintf(os.Stderr, "Error: zoom step must be above 1 and at most 4\n")
+		os.Exit(1)
+	}
+
+	coords, err := geo.ParseCoordFormat(*coordFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	renderMode, err := render.ParseRenderMode(*renderModeName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *asciiOnly && (renderMode == render.RenderModeHalfBlock || renderMode == render.RenderModeQuadrant) {
+		fmt.Printf("Warning: -mode %s needs Unicode block characters, using text with -ascii\n", renderMode)
+		renderMode = render.RenderModeText
+	}
+
+	colorDepth, err := render.ParseColorDepth(*colorDepthName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	shown, err := render.ParseLayers(*showLayers)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: -show: %v\n", err)
+		os.Exit(1)
+	}
+	hidden, err := render.ParseLayers(*hideLayers)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: -hide: %v\n", err)
+		os.Exit(1)
+	}
+
+	// The receiver location is only known if both coordinates were given
+	var receiver *geo.LatLon
+	latSet, lonSet := false, false
+	flag.Visit(func(f *flag.Flag) {
+		latSet = latSet || f.Name == "lat"
+		lonSet = lonSet || f.Name == "lon"
+	})
+	if latSet != lonSet {
+		fmt.Fprintln(os.Stderr, "Error: -lat and -lon must be given together")
+		os.Exit(1)
+	}
+	if latSet {
+		if *receiverLat < -90 || *receiverLat > 90 || *receiverLon < -180 || *receiverLon > 180 {
+			fmt.Fprintln(os.Stderr, "Error: receiver location out of range")
+			os.Exit(1)
+		}
+		receiver = &geo.LatLon{Lat: *receiverLat, Lon: *receiverLon}
+	}
+
+	// Proximity alerts measure from the receiver, so need its location
+	var proximity *alert.Proximity
+	if *proximityRadius > 0 {
+		if receiver == nil {
+			fmt.Fprintln(os.Stderr, "Error: -proximity needs the receiver location from -lat and -lon")
+			os.Exit(1)
+		}
+		// The default is a fixed height, not 3000 of whatever -units says
+		ceiling := alert.DefaultProximityCeiling
+		if *proximityCeiling > 0 {
+			ceiling = units.ToFeet(*proximityCeiling)
+		}
+		proximity = &alert.Proximity{
+			Center:  *receiver,
+			Radius:  units.ToMiles(*proximityRadius),
+			Ceiling: ceiling,
+			Units:   units,
+		}
+	}
+
+	soundKinds, err := alert.ParseKinds(*beepKinds)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var symbols *render.SymbolSet
+	if *symbolSet != "" {
+		set, err := render.ParseSymbolSet(*symbolSet)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		symbols = &set
+	}
+
+	themesDir := ""
+	if dir, err := cache.BaseDir(); err == nil {
+		themesDir = filepath.Join(dir, "themes")
+	}
+
+	var theme *render.Theme
+	if *themeName != "" {
+		theme, err = render.LoadTheme(*themeName, themesDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	// Set up debug logging if requested
 	if *debugLog != "" {
-		logFile, err := os.Create(*debugLog)
+		logFile, err := debug.OpenRotatingFile(*debugLog, int64(*logMaxSize)<<20, *logKeep)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to create debug log: %v\n", err)
 		} else {
 			defer logFile.Close()
+			debug.SetLevel(level)
+			debug.SetJSON(*logJSON)
 			debug.SetOutput(logFile)
-			debug.Log("ascii1090 debug log started")
+			log.Infof("ascii1090 debug log started")
 			fmt.Printf("Debug logging enabled: %s\n", *debugLog)
 		}
 	}
 
+	// Serve profiles for diagnosing rendering and parsing hot spots
+	if *pprofAddr != "" {
+		listener, err := net.Listen("tcp", *pprofAddr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to start profiling server: %v\n", err)
+		} else {
+			go http.Serve(listener, nil)
+			fmt.Printf("Profiling enabled: http://%s/debug/pprof/\n", listener.Addr())
+			log.Infof("pprof listening on %s", listener.Addr())
+		}
+	}
+
 	// Initialize cache manager
 	fmt.Println("Initializing map data cache...")
 	cacheManager, err := cache.NewManager(*cacheDir)
@@ -64,51 +371,298 @@ func main() {
 		fmt.Fprintf(os.Stderr, "Error: failed to initialize cache: %v\n", err)
 		os.Exit(1)
 	}
-
-	// Ensure Natural Earth data is available
-	fmt.Println("Checking Natural Earth data...")
-	if err := cacheManager.EnsureData(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to download map data: %v\n", err)
+	httpClient, err := cache.NewHTTPClient(*proxyURL, *caBundle)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	cacheManager.SetHTTPClient(httpClient)
+	cacheManager.SetRefreshPolicy(days(*airportsMaxAge), days(*mapMaxAge), *refresh)
+	cacheManager.SetSizeLimit(int64(*cacheLimit) << 20)
+	if *mirrors != "" {
+		cacheManager.SetMirrors(strings.Split(*mirrors, ","))
+	}
 
-	// Load shapefiles
-	fmt.Println("Loading geographic features...")
-	loader := geo.NewShapefileLoader(cacheManager.GetCacheDir())
-	features, err := loader.LoadAll(*highwayDetail)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to load shapefiles: %v\n", err)
-		os.Exit(1)
+	// Load user GeoJSON overlays from the default directory plus any given paths
+	baseDir, _ := cache.BaseDir()
+	var overlays []string
+	if baseDir != "" {
+		defaultOverlays := filepath.Join(baseDir, "overlays")
+		if _, err := os.Stat(defaultOverlays); err == nil {
+			overlays = append(overlays, defaultOverlays)
+		}
+	}
+	for _, path := range strings.Split(*overlayPaths, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			overlays = append(overlays, path)
+		}
+	}
+	features := make(map[geo.FeatureType][]*geo.Feature)
+	if len(overlays) > 0 {
+		features[geo.FeatureOverlay] = geo.LoadOverlays(overlays)
+		fmt.Printf("Loaded %d overlay features\n", len(features[geo.FeatureOverlay]))
+	}
+
+	// Load user waypoints; the default file is optional, an explicit one is not
+	waypointsPath := *waypointsFile
+	if waypointsPath == "" && baseDir != "" {
+		waypointsPath = filepath.Join(baseDir, "waypoints.csv")
+		if _, err := os.Stat(waypointsPath); err != nil {
+			waypointsPath = ""
+		}
+	}
+	if waypointsPath != "" {
+		waypoints, err := geo.NewWaypointLoader(waypointsPath).LoadWaypoints()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to load waypoints: %v\n", err)
+			os.Exit(1)
+		}
+		features[geo.FeatureWaypoint] = waypoints
+		fmt.Printf("Loaded %d waypoints\n", len(waypoints))
 	}
-	fmt.Printf("Loaded %d feature types\n", len(features))
 
-	// Initialize dump1090 client
-	var dump1090Client *adsb.Dump1090Client
-	if *networkAddr != "" {
-		fmt.Printf("Connecting to dump1090 at %s...\n", *networkAddr)
-		dump1090Client, err = adsb.NewNetworkClient(*networkAddr)
+	// Load flight routes if available
+	routesPath := *routesFile
+	if routesPath == "" {
+		routesPath = filepath.Join(cacheManager.GetCacheDir(), "routes.csv")
+		if _, err := os.Stat(routesPath); err != nil {
+			routesPath = ""
+		}
+	}
+	var routes *geo.RouteTable
+	if routesPath != "" {
+		routes, err = geo.LoadRoutes(routesPath)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: failed to connect to dump1090: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Error: failed to load routes: %v\n", err)
 			os.Exit(1)
 		}
-	} else {
+		fmt.Printf("Loaded %d routes\n", routes.Len())
+	}
+
+	// Open the daily statistics unless turned off
+	statsPath := *statsFile
+	if statsPath == "" {
+		statsPath, _ = stats.DefaultPath()
+	}
+	var statsStore *stats.Store
+	if statsPath != "" && statsPath != "off" {
+		statsStore, err = stats.Open(statsPath)
+		if err != nil {
+			fmt.Printf("Warning: daily statistics disabled: %v\n", err)
+		}
+	}
+
+	// Load the alert watchlist if one was given or exists in the default spot
+	watchlistFile := *watchlistPath
+	if watchlistFile == "" && baseDir != "" {
+		if path := filepath.Join(baseDir, "watchlist.txt"); fileExists(path) {
+			watchlistFile = path
+		}
+	}
+	var watchlist []string
+	if watchlistFile != "" {
+		watchlist, err = alert.LoadWatchlist(watchlistFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Loaded %d watchlist entries\n", len(watchlist))
+	}
+
+	// Use a full WMM coefficient file if one was dropped into the cache
+	magneticModel := geo.DefaultMagneticModel()
+	if cofPath := filepath.Join(cacheManager.GetCacheDir(), "WMM.COF"); fileExists(cofPath) {
+		if model, err := geo.LoadMagneticModel(cofPath); err != nil {
+			fmt.Printf("Warning: failed to load %s, using built-in model: %v\n", cofPath, err)
+		} else {
+			magneticModel = model
+		}
+	}
+
+	// Download and load the map data and databases in the background, so
+	// the display starts at once and shows aircraft while they load
+	loadData := func(out io.Writer) (ui.StartupData, error) {
+		var data ui.StartupData
+		cacheManager.SetOutput(out)
+		defer cacheManager.SetOutput(io.Discard) // Not stdout, which tcell owns
+
+		// Ensure Natural Earth data is available
+		fmt.Fprintln(out, "Checking Natural Earth data...")
+		if err := cacheManager.EnsureData(); err != nil {
+			return data, fmt.Errorf("failed to download map data: %w", err)
+		}
+
+		if *globalRoads {
+			if err := cacheManager.EnsureGlobalRoads(); err != nil {
+				fmt.Fprintf(out, "Warning: Skipping %s (optional): %v\n", cache.GlobalRoadsFile.Name, err)
+			}
+		}
+
+		if detail == geo.DetailHigh {
+			cacheManager.EnsureHighDetail()
+		}
+
+		if *downloadAircraftDB {
+			if err := cacheManager.EnsureAircraftDB(); err != nil {
+				fmt.Fprintf(out, "Warning: Skipping aircraft database (optional): %v\n", err)
+			}
+		}
+
+		if *downloadPlaneAlert {
+			if err := cacheManager.EnsurePlaneAlert(); err != nil {
+				fmt.Fprintf(out, "Warning: Skipping plane-alert database (optional): %v\n", err)
+			}
+		}
+
+		if err := cacheManager.EnforceLimit(); err != nil {
+			fmt.Fprintf(out, "Warning: %v\n", err)
+		}
+
+		// Load shapefiles
+		fmt.Fprintln(out, "Loading geographic features...")
+		loader := geo.NewShapefileLoader(cacheManager.GetCacheDir())
+		loader.SetDetail(detail)
+		loader.SetOutput(out)
+		features, tiles, err := loader.LoadCached()
+		if err != nil {
+			return data, fmt.Errorf("failed to load shapefiles: %w", err)
+		}
+		data.Features = features
+		if tiles != nil {
+			tiles.SetMemoryLimit(int64(*featureMemory) << 20)
+			data.Tiles = tiles
+		}
+		fmt.Fprintf(out, "Loaded %d feature types\n", len(features))
+
+		// Load the aircraft database if available
+		aircraftDBPath := *aircraftDBFile
+		if aircraftDBPath == "" {
+			aircraftDBPath = cacheManager.GetAircraftDBPath()
+			if _, err := os.Stat(aircraftDBPath); err != nil {
+				aircraftDBPath = ""
+			}
+		}
+		if aircraftDBPath != "" {
+			if db, err := adsb.LoadAircraftDB(aircraftDBPath); err != nil {
+				fmt.Fprintf(out, "Warning: failed to load aircraft database: %v\n", err)
+			} else {
+				data.AircraftDB = db
+				fmt.Fprintf(out, "Loaded %d aircraft\n", db.Len())
+			}
+		}
+
+		// Load the plane-alert database if available
+		planeAlertPath := *planeAlertFile
+		if planeAlertPath == "" {
+			planeAlertPath = cacheManager.GetPlaneAlertPath()
+			if _, err := os.Stat(planeAlertPath); err != nil {
+				planeAlertPath = ""
+			}
+		}
+		if planeAlertPath != "" {
+			if db, err := adsb.LoadPlaneAlertDB(planeAlertPath); err != nil {
+				fmt.Fprintf(out, "Warning: %v\n", err)
+			} else {
+				data.PlaneAlert = db
+				fmt.Fprintf(out, "Loaded %d interesting aircraft\n", db.Len())
+			}
+		}
+		return data, nil
+	}
+
+	// Connect the feeds: the local dump1090 unless only network feeds were
+	// given, then each network feed
+	var clients []*adsb.Dump1090Client
+	if len(feedSpecs) == 0 || *withLocal {
 		fmt.Println("Starting local dump1090...")
-		dump1090Client, err = adsb.NewLocalClient()
+		client, err := adsb.NewLocalClient(adsb.LocalOptions{
+			Binary: *dump1090Binary,
+			Args:   strings.Fields(*dump1090Args),
+			Port:   *sbsPort,
+		})
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: failed to start dump1090: %v\n", err)
-			fmt.Fprintf(os.Stderr, "Hint: Make sure dump1090 is installed and in your PATH\n")
+			fmt.Fprintf(os.Stderr, "Hint: Make sure dump1090 is installed and in your PATH, or give its path with -dump1090\n")
 			fmt.Fprintf(os.Stderr, "Or use -network flag to connect to a remote instance\n")
 			os.Exit(1)
 		}
+		clients = append(clients, client)
+	}
+	for _, spec := range feedSpecs {
+		fmt.Printf("Connecting to %s feed at %s...\n", spec.Source, spec.Addr)
+		connect := adsb.NewNetworkClient
+		if spec.Raw {
+			connect = adsb.NewRawNetworkClient
+		}
+		client, err := connect(spec.Addr, spec.Source)
+		if err != nil {
+			if len(feedSpecs) > 1 || len(clients) > 0 {
+				fmt.Printf("Warning: %v\n", err)
+				continue
+			}
+			fmt.Fprintf(os.Stderr, "Error: failed to connect to dump1090: %v\n", err)
+			os.Exit(1)
+		}
+		clients = append(clients, client)
+	}
+	if len(clients) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: failed to connect to any feed\n")
+		os.Exit(1)
 	}
-	defer dump1090Client.Close()
+	feeds := adsb.NewFeeds(clients...)
+	feeds.SetSilenceTimeout(time.Duration(*feedTimeout) * time.Second)
+	defer feeds.Close()
 
 	// Initialize aircraft tracker
 	tracker := adsb.NewTracker(60 * time.Second)
+	tracker.SetPrecedence(precedence)
+	tracker.SetMergePolicy(policy)
+
+	// Screenshots go to the cache directory, or the working directory if
+	// there is no home directory
+	screenshotDir := "."
+	if baseDir != "" {
+		screenshotDir = filepath.Join(baseDir, "screenshots")
+	}
 
 	// Create and run application
-	fmt.Printf("Starting ascii1090 (radius: %.0f miles, aspect: %.1f)...\n", *radiusMiles, *aspectRatio)
-	app, err := ui.NewApp(tracker, dump1090Client, features, *radiusMiles, *aspectRatio)
+	fmt.Printf("Starting ascii1090 (radius: %s, aspect: %.1f)...\n", units.FormatDistance(radiusMiles), *aspectRatio)
+	app, err := ui.NewApp(tracker, feeds, features, ui.Options{
+		RadiusMiles:   radiusMiles,
+		ZoomStep:      *zoomStep,
+		AspectRatio:   *aspectRatio,
+		AirportLabels: labelMode,
+		METAR:         *metar,
+		Routes:        routes,
+		Stats:         statsStore,
+		Magnetic:      magneticModel,
+		CoordFormat:   coords,
+		Units:         units,
+		RenderMode:    renderMode,
+		ColorDepth:    colorDepth,
+		Theme:         theme,
+		ASCII:         *asciiOnly,
+		Monochrome:    *monochrome || os.Getenv("NO_COLOR") != "",
+		Symbols:       symbols,
+		Watchlist:     watchlist,
+		Proximity:     proximity,
+		Receiver:      receiver,
+		ShowLayers:    shown,
+		HideLayers:    hidden,
+		ScreenshotDir: screenshotDir,
+		PositionsOnly: *positionsOnly,
+		KeyMap:        keyMap,
+		Bookmarks:     bookmarks,
+		ConfigPath:    bookmarkPath,
+		LocalTime:     *localTime,
+		SoundKinds:    soundKinds,
+		SoundCommand:  *soundCommand,
+		ConfirmQuit:   *confirmQuit,
+		ThemesDir:     themesDir,
+		HighwayDetail: *highwayDetail,
+		Cache:         cacheManager,
+		Startup:       loadData,
+	})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: failed to create application: %v\n", err)
 		os.Exit(1)
@@ -127,5 +681,32 @@ func main() {
 		}
 	}()
 
-	fmt.Println("\nGoodbye!")
+	fmt.Println()
+	summary := app.Summary()
+	summary.Print(os.Stdout)
+	if *coverageFile != "" {
+		if err := app.ExportCoverage(*coverageFile); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		} else {
+			fmt.Printf("Saved coverage to %s\n", *coverageFile)
+		}
+	}
+	if *summaryJSON != "" {
+		if err := summary.WriteJSON(*summaryJSON); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		} else {
+			fmt.Printf("Saved session summary to %s\n", *summaryJSON)
+		}
+	}
+}
+
+// fileExists returns true if path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// days converts a day count from the command line to a duration
+func days(n int) time.Duration {
+	return time.Duration(n) * 24 * time.Hour
 }